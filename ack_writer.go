@@ -0,0 +1,129 @@
+package batching
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AckWriter is used to submit the completed batch when the batch is only
+// delivered once the downstream acknowledges it. Write must eventually call
+// ack exactly once, with nil once the batch has been delivered or with an
+// error if it has not, in which case the batch is written again. ack may be
+// called from any goroutine, including from within Write. The batch must not
+// be modified until ack is called.
+type AckWriter interface {
+	// Write submits the batch.
+	Write(batch []interface{}, ack func(err error))
+}
+
+// AckWriterFunc is an adapter to allow ordinary functions to be an
+// AckWriter.
+type AckWriterFunc func(batch []interface{}, ack func(err error))
+
+// Write implements AckWriter.
+func (f AckWriterFunc) Write(batch []interface{}, ack func(err error)) {
+	f(batch, ack)
+}
+
+// NewAckBatcher creates a new Batcher that submits batches to an AckWriter.
+// Batches that were submitted but not yet acknowledged are reserved by the
+// Batcher. A batch acknowledged with an error is put back at the front of the
+// pending batch and written again, without applying the RetryPolicy. Since
+// acknowledgements may arrive from other goroutines, the Batcher is created
+// WithLocking. Batches acknowledged with an error after the Batcher has been
+// closed are reported to the drop callback.
+func NewAckBatcher(size int, interval time.Duration, writer AckWriter, opts ...Option) *Batcher {
+	w := &ackContextWriter{w: writer}
+	b := newBatcher(size, interval, w, append(opts, WithLocking()))
+	b.reuseBatches = false
+	w.b = b
+
+	return b
+}
+
+// Unacked returns the number of elements that have been submitted to an
+// AckWriter but not yet acknowledged.
+func (b *Batcher) Unacked() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := 0
+	for _, batch := range b.reserved {
+		n += len(batch)
+	}
+	return n
+}
+
+// ackContextWriter adapts an AckWriter to a ContextWriter.
+type ackContextWriter struct {
+	w AckWriter
+	b *Batcher
+}
+
+const (
+	ackPending int32 = iota
+	ackInline
+	ackReturned
+)
+
+func (w *ackContextWriter) Write(_ context.Context, batch []interface{}) error {
+	id := w.b.reserve(batch)
+
+	var (
+		state atomic.Int32
+		once  sync.Once
+		err   error
+	)
+	ack := func(ackErr error) {
+		once.Do(func() {
+			err = ackErr
+			// An ack that arrives before Write returned is handled below,
+			// since the Batcher is still locked.
+			if state.CompareAndSwap(ackPending, ackInline) {
+				return
+			}
+
+			w.b.mu.Lock()
+			defer w.b.mu.Unlock()
+			w.b.acked(id, err)
+		})
+	}
+
+	w.w.Write(batch, ack)
+	if state.CompareAndSwap(ackPending, ackReturned) {
+		return nil
+	}
+
+	// The batch is still at the front of the pending batch, so it only
+	// needs to be kept there if it was not delivered.
+	delete(w.b.reserved, id)
+	if err != nil {
+		return notSubmittedError{err: err}
+	}
+	return nil
+}
+
+func (b *Batcher) reserve(batch []interface{}) uint64 {
+	if b.reserved == nil {
+		b.reserved = make(map[uint64][]interface{})
+	}
+	b.nextReservation++
+	b.reserved[b.nextReservation] = batch
+	return b.nextReservation
+}
+
+func (b *Batcher) acked(id uint64, err error) {
+	batch := b.reserved[id]
+	delete(b.reserved, id)
+	if err == nil {
+		return
+	}
+
+	if b.closed {
+		b.dropped(batch, DropClosed)
+		return
+	}
+	b.requeue(batch)
+}
@@ -0,0 +1,111 @@
+package batching_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("AckWriter", func() {
+	var (
+		batches [][]interface{}
+		acks    []func(error)
+		writer  batching.AckWriter
+	)
+
+	BeforeEach(func() {
+		batches = nil
+		acks = nil
+		writer = batching.AckWriterFunc(func(batch []interface{}, ack func(error)) {
+			batches = append(batches, batch)
+			acks = append(acks, ack)
+		})
+	})
+
+	It("reserves batches until they are acknowledged", func() {
+		b := batching.NewAckBatcher(2, time.Minute, writer)
+		b.WriteAll("a", "b")
+		Expect(b.Unacked()).To(Equal(2))
+		Expect(b.Len()).To(Equal(0))
+
+		acks[0](nil)
+
+		Expect(b.Unacked()).To(Equal(0))
+		Expect(b.Len()).To(Equal(0))
+	})
+
+	It("writes batches acknowledged with an error again", func() {
+		b := batching.NewAckBatcher(2, time.Minute, writer)
+		b.WriteAll("a", "b")
+		b.Write("c")
+
+		acks[0](errors.New("failed"))
+		Expect(b.Peek()).To(Equal([]interface{}{"a", "b", "c"}))
+		Expect(b.Unacked()).To(Equal(0))
+
+		b.ForcedFlush()
+		Expect(batches[1:]).To(Equal([][]interface{}{{"a", "b"}, {"c"}}))
+	})
+
+	It("handles acknowledgements from within the writer", func() {
+		fail := true
+		writer := batching.AckWriterFunc(func(batch []interface{}, ack func(error)) {
+			batches = append(batches, batch)
+			if fail {
+				fail = false
+				ack(errors.New("failed"))
+				return
+			}
+			ack(nil)
+		})
+		b := batching.NewAckBatcher(1, time.Minute, writer)
+
+		b.Write("a")
+		Expect(b.Peek()).To(Equal([]interface{}{"a"}))
+
+		b.ForcedFlush()
+		Expect(batches).To(Equal([][]interface{}{{"a"}, {"a"}}))
+		Expect(b.Len()).To(Equal(0))
+		Expect(b.Unacked()).To(Equal(0))
+	})
+
+	It("handles acknowledgements from other goroutines", func() {
+		writer := batching.AckWriterFunc(func(batch []interface{}, ack func(error)) {
+			go ack(nil)
+		})
+		b := batching.NewAckBatcher(1, time.Minute, writer)
+
+		b.WriteAll("a", "b", "c")
+
+		Eventually(b.Unacked).Should(Equal(0))
+	})
+
+	It("only honors the first acknowledgement", func() {
+		b := batching.NewAckBatcher(1, time.Minute, writer)
+		b.Write("a")
+
+		acks[0](nil)
+		acks[0](errors.New("failed"))
+
+		Expect(b.Len()).To(Equal(0))
+	})
+
+	It("drops batches acknowledged with an error once closed", func() {
+		var dropped []interface{}
+		b := batching.NewAckBatcher(1, time.Minute, writer,
+			batching.WithOnDrop(func(d []interface{}) {
+				dropped = append(dropped, d...)
+			}),
+		)
+		b.Write("a")
+		Expect(b.Close()).To(Succeed())
+
+		acks[0](errors.New("failed"))
+
+		Expect(dropped).To(Equal([]interface{}{"a"}))
+	})
+})
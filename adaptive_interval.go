@@ -0,0 +1,52 @@
+package batching
+
+import "time"
+
+// WithAdaptiveInterval adjusts the flush interval to the rate at which data
+// arrives. After every flush the next interval is set to how long the batch
+// would take to fill at the rate observed during the previous interval,
+// bounded by min and max. An interval that lapses without any data is
+// restarted by Flush. High ingest rates therefore shorten the interval
+// towards min, while idle periods lengthen it towards max so that low
+// traffic does not produce a stream of tiny batches. The interval passed to
+// the constructor is used, within the bounds, until a rate has been observed.
+func WithAdaptiveInterval(min, max time.Duration) Option {
+	return func(b *Batcher) {
+		b.minInterval = min
+		b.maxInterval = max
+	}
+}
+
+// adaptInterval returns the interval to use for the next batch, based on
+// the number of elements that arrived since the last interval started.
+func (b *Batcher) adaptInterval() time.Duration {
+	if b.maxInterval <= 0 {
+		return b.interval
+	}
+
+	arrivals := b.arrivals
+	b.arrivals = 0
+	if b.lastSent.IsZero() {
+		return b.boundInterval(b.interval)
+	}
+	if arrivals == 0 {
+		return b.maxInterval
+	}
+
+	elapsed := b.clock.Since(b.lastSent)
+	fill := float64(elapsed) * float64(b.size) / float64(arrivals)
+	if fill >= float64(b.maxInterval) {
+		return b.maxInterval
+	}
+	return b.boundInterval(time.Duration(fill))
+}
+
+// idleInterval reports whether the interval lapsed without any data while
+// the interval is adaptive, in which case a new, longer interval starts.
+func (b *Batcher) idleInterval() bool {
+	return b.maxInterval > 0 && len(b.batch) == 0 && !b.partialInterval()
+}
+
+func (b *Batcher) boundInterval(d time.Duration) time.Duration {
+	return min(max(d, b.minInterval), b.maxInterval)
+}
@@ -0,0 +1,88 @@
+package batching_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Adaptive interval", func() {
+	var (
+		clock  *fakeClock
+		writer *spyWriter
+		b      *batching.Batcher
+	)
+
+	BeforeEach(func() {
+		clock = &fakeClock{now: time.Unix(0, 0)}
+		writer = &spyWriter{}
+		b = batching.NewBatcher(10, time.Second, writer,
+			batching.WithClock(clock),
+			batching.WithAdaptiveInterval(100*time.Millisecond, time.Minute),
+		)
+	})
+
+	It("uses the interval until a rate has been observed", func() {
+		b.Write("item")
+
+		clock.Advance(999 * time.Millisecond)
+		Expect(b.Flush().Written).To(BeFalse())
+
+		clock.Advance(time.Millisecond)
+		Expect(b.Flush().Written).To(BeTrue())
+	})
+
+	It("lengthens the interval when data arrives slowly", func() {
+		b.Write("item")
+		clock.Advance(time.Second)
+		b.Flush()
+
+		// One element per second fills the batch in ten seconds.
+		b.Write("item")
+		clock.Advance(9 * time.Second)
+		Expect(b.Flush().Written).To(BeFalse())
+
+		clock.Advance(time.Second)
+		Expect(b.Flush().Written).To(BeTrue())
+	})
+
+	It("shortens the interval when data arrives quickly", func() {
+		for i := 0; i < 5; i++ {
+			b.Write("item")
+		}
+		clock.Advance(time.Second)
+		b.Flush()
+
+		// Five elements per second fill the batch in two seconds.
+		b.Write("item")
+		clock.Advance(2 * time.Second)
+		Expect(b.Flush().Written).To(BeTrue())
+	})
+
+	It("does not shorten the interval below the minimum", func() {
+		for i := 0; i < 10; i++ {
+			b.Write("item")
+		}
+		b.Write("item")
+		clock.Advance(99 * time.Millisecond)
+		Expect(b.Flush().Written).To(BeFalse())
+
+		clock.Advance(time.Millisecond)
+		Expect(b.Flush().Written).To(BeTrue())
+	})
+
+	It("uses the maximum interval after an idle interval", func() {
+		clock.Advance(time.Second)
+		Expect(b.Flush().Written).To(BeFalse())
+
+		b.Write("item")
+		clock.Advance(59 * time.Second)
+		Expect(b.Flush().Written).To(BeFalse())
+
+		clock.Advance(time.Second)
+		Expect(b.Flush().Written).To(BeTrue())
+	})
+})
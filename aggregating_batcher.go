@@ -0,0 +1,143 @@
+package batching
+
+import (
+	"sync"
+	"time"
+)
+
+// AggregatingBatcher reduces all elements written with the same key into a
+// single accumulated value, such as the sum of a counter or the latest value
+// of a gauge, and writes the accumulated values on the usual batch size and
+// interval triggers. The batch size is the number of distinct keys. The key
+// of each element is returned by the key function and must be comparable.
+// The methods of an AggregatingBatcher are safe to call from multiple
+// goroutines. AggregatingBatcher should be created with
+// NewAggregatingBatcher().
+type AggregatingBatcher struct {
+	mu       sync.Mutex
+	size     int
+	interval time.Duration
+	clock    Clock
+	lastSent time.Time
+	keyFn    func(data interface{}) interface{}
+	reduce   func(acc, data interface{}) interface{}
+	w        Writer
+	keys     []interface{}
+	values   map[interface{}]interface{}
+	closed   bool
+}
+
+// NewAggregatingBatcher creates a new AggregatingBatcher. The first element
+// written with a key is its initial value, every following element is
+// combined with the accumulated value by reduce. The writer is passed the
+// accumulated values in the order their keys were first written.
+func NewAggregatingBatcher(
+	size int,
+	interval time.Duration,
+	keyFn func(data interface{}) interface{},
+	reduce func(acc, data interface{}) interface{},
+	writer Writer,
+) *AggregatingBatcher {
+	return &AggregatingBatcher{
+		size:     size,
+		interval: interval,
+		clock:    systemClock{},
+		lastSent: time.Now(),
+		keyFn:    keyFn,
+		reduce:   reduce,
+		w:        writer,
+		values:   make(map[interface{}]interface{}),
+	}
+}
+
+// SetClock sets the Clock used to decide when the interval has lapsed, as
+// WithClock does for a Batcher. It defaults to the system clock. The interval
+// starts over at the time of c.
+func (a *AggregatingBatcher) SetClock(c Clock) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.clock = c
+	a.lastSent = c.Now()
+}
+
+// Write combines data with the accumulated value of its key. If this adds a
+// key and the number of keys reaches the batch size, the accumulated values
+// are written. Data written after Close is dropped.
+func (a *AggregatingBatcher) Write(data interface{}) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return
+	}
+
+	key := a.keyFn(data)
+	if acc, ok := a.values[key]; ok {
+		a.values[key] = a.reduce(acc, data)
+		return
+	}
+	a.keys = append(a.keys, key)
+	a.values[key] = data
+
+	if len(a.keys) >= a.size {
+		a.flush()
+	}
+}
+
+// Flush writes the accumulated values if the interval has lapsed.
+func (a *AggregatingBatcher) Flush() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.clock.Since(a.lastSent) >= a.interval {
+		a.flush()
+	}
+}
+
+// ForcedFlush writes the accumulated values, regardless of the batch size or
+// interval.
+func (a *AggregatingBatcher) ForcedFlush() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.flush()
+}
+
+// Len returns the number of keys with an accumulated value.
+func (a *AggregatingBatcher) Len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return len(a.keys)
+}
+
+// Close writes the accumulated values and closes the AggregatingBatcher.
+// Calling Close more than once returns ErrClosed.
+func (a *AggregatingBatcher) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return ErrClosed
+	}
+	a.flush()
+	a.closed = true
+	return nil
+}
+
+func (a *AggregatingBatcher) flush() {
+	a.lastSent = a.clock.Now()
+	if len(a.keys) == 0 {
+		return
+	}
+
+	batch := make([]interface{}, len(a.keys))
+	for i, key := range a.keys {
+		batch[i] = a.values[key]
+	}
+	a.keys = nil
+	clear(a.values)
+
+	a.w.Write(batch)
+}
@@ -0,0 +1,97 @@
+package batching_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("AggregatingBatcher", func() {
+	type counter struct {
+		name  string
+		delta int
+	}
+
+	var (
+		writer *recordingWriter
+		b      *batching.AggregatingBatcher
+	)
+
+	BeforeEach(func() {
+		writer = &recordingWriter{}
+		b = batching.NewAggregatingBatcher(2, time.Minute,
+			func(data interface{}) interface{} {
+				return data.(counter).name
+			},
+			func(acc, data interface{}) interface{} {
+				c := acc.(counter)
+				c.delta += data.(counter).delta
+				return c
+			},
+			writer,
+		)
+	})
+
+	It("sums the elements written with the same key", func() {
+		b.Write(counter{name: "requests", delta: 1})
+		b.Write(counter{name: "requests", delta: 2})
+		b.Write(counter{name: "errors", delta: 1})
+
+		Expect(writer.batches).To(Equal([][]interface{}{{
+			counter{name: "requests", delta: 3},
+			counter{name: "errors", delta: 1},
+		}}))
+		Expect(b.Len()).To(Equal(0))
+	})
+
+	It("counts distinct keys towards the batch size", func() {
+		for i := 0; i < 10; i++ {
+			b.Write(counter{name: "requests", delta: 1})
+		}
+
+		Expect(writer.batches).To(BeEmpty())
+		Expect(b.Len()).To(Equal(1))
+	})
+
+	It("writes the accumulated values once the interval lapsed", func() {
+		b = batching.NewAggregatingBatcher(2, time.Millisecond,
+			func(data interface{}) interface{} { return data },
+			func(acc, _ interface{}) interface{} { return acc },
+			writer,
+		)
+		b.Write("a")
+		time.Sleep(time.Millisecond)
+
+		b.Flush()
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"a"}}))
+	})
+
+	It("measures the interval with its clock", func() {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		b.SetClock(clock)
+		b.Write(counter{name: "requests", delta: 1})
+
+		clock.Advance(30 * time.Second)
+		b.Flush()
+		Expect(writer.batches).To(BeEmpty())
+
+		clock.Advance(30 * time.Second)
+		b.Flush()
+		Expect(writer.batches).To(HaveLen(1))
+	})
+
+	It("writes the accumulated values on Close", func() {
+		b.Write(counter{name: "requests", delta: 1})
+
+		Expect(b.Close()).To(Succeed())
+		Expect(writer.batches).To(HaveLen(1))
+
+		b.Write(counter{name: "requests", delta: 1})
+		Expect(b.Len()).To(Equal(0))
+		Expect(b.Close()).To(MatchError(batching.ErrClosed))
+	})
+})
@@ -0,0 +1,21 @@
+package batching
+
+import "time"
+
+// WithAlignedInterval aligns the interval to the wall clock, so that partial
+// batches are written at multiples of the interval, such as every minute at
+// :00, instead of an interval after the last write. This makes batch
+// boundaries comparable across instances. Intervals that divide a day
+// evenly are aligned to midnight UTC. Jitter is not applied to aligned
+// intervals.
+func WithAlignedInterval() Option {
+	return func(b *Batcher) {
+		b.alignInterval = true
+	}
+}
+
+// alignedInterval returns how long it is from the current interval start to
+// the next multiple of interval.
+func (b *Batcher) alignedInterval(interval time.Duration) time.Duration {
+	return b.lastSent.Truncate(interval).Add(interval).Sub(b.lastSent)
+}
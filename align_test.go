@@ -0,0 +1,56 @@
+package batching_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Aligned interval", func() {
+	It("writes partial batches at multiples of the interval", func() {
+		clock := &fakeClock{now: time.Date(2026, 1, 2, 3, 4, 25, 0, time.UTC)}
+		writer := &spyWriter{}
+		b := batching.NewBatcher(10, time.Minute, writer,
+			batching.WithClock(clock),
+			batching.WithAlignedInterval(),
+		)
+
+		b.Write("item")
+		clock.Advance(34 * time.Second)
+		Expect(b.Flush().Written).To(BeFalse())
+
+		clock.Advance(time.Second)
+		Expect(b.Flush().Written).To(BeTrue())
+		Expect(clock.now).To(Equal(time.Date(2026, 1, 2, 3, 5, 0, 0, time.UTC)))
+
+		b.Write("item")
+		clock.Advance(20 * time.Second)
+		b.ForcedFlush()
+		b.Write("item")
+		clock.Advance(39 * time.Second)
+		Expect(b.Flush().Written).To(BeFalse())
+
+		clock.Advance(time.Second)
+		Expect(b.Flush().Written).To(BeTrue())
+	})
+
+	It("ignores jitter", func() {
+		clock := &fakeClock{now: time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)}
+		writer := &spyWriter{}
+		b := batching.NewBatcher(10, time.Minute, writer,
+			batching.WithClock(clock),
+			batching.WithJitter(0.5),
+			batching.WithAlignedInterval(),
+		)
+
+		b.Write("item")
+		clock.Advance(59 * time.Second)
+		Expect(b.Flush().Written).To(BeFalse())
+
+		clock.Advance(time.Second)
+		Expect(b.Flush().Written).To(BeTrue())
+	})
+})
@@ -0,0 +1,164 @@
+package batching
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// AsyncBatcher decouples writing data from submitting batches. Data written
+// to an AsyncBatcher is put on a bounded queue and batched and submitted to
+// the writer by a dedicated goroutine, so writes only block when the queue is
+// full. The methods of an AsyncBatcher are safe to call from multiple
+// goroutines. AsyncBatcher should be created with NewAsyncBatcher().
+type AsyncBatcher struct {
+	b      *Batcher
+	queue  chan interface{}
+	done   chan struct{}
+	cancel context.CancelFunc
+
+	mu     sync.RWMutex
+	closed bool
+
+	highWater atomic.Int64
+}
+
+// NewAsyncBatcher creates a new AsyncBatcher that queues up to queueSize
+// elements and batches them with b. The goroutine that drives b is started
+// immediately. b must not be used directly once it is handed to the
+// AsyncBatcher.
+func NewAsyncBatcher(b *Batcher, queueSize int) *AsyncBatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &AsyncBatcher{
+		b:      b,
+		queue:  make(chan interface{}, queueSize),
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+
+	go func() {
+		defer close(a.done)
+		_ = b.Run(ctx, a.queue)
+	}()
+
+	return a
+}
+
+// Write queues data to be batched. It blocks while the queue is full. Data
+// written after the AsyncBatcher is closed is dropped.
+func (a *AsyncBatcher) Write(data interface{}) {
+	_ = a.WriteContext(context.Background(), data)
+}
+
+// WriteContext is like Write but returns the error of ctx if it is done
+// before data could be queued, or ErrClosed if the AsyncBatcher is closed.
+func (a *AsyncBatcher) WriteContext(ctx context.Context, data interface{}) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.closed {
+		return ErrClosed
+	}
+
+	select {
+	case a.queue <- data:
+		a.queued()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TryWrite queues data without blocking. It reports whether data was queued,
+// which is not the case if the queue is full or the AsyncBatcher is closed.
+func (a *AsyncBatcher) TryWrite(data interface{}) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.closed {
+		return false
+	}
+
+	select {
+	case a.queue <- data:
+		a.queued()
+		return true
+	default:
+		return false
+	}
+}
+
+// QueueLen returns the number of elements in the queue that have not been
+// batched yet. A queue that stays close to its capacity means writes are
+// about to block.
+func (a *AsyncBatcher) QueueLen() int {
+	return len(a.queue)
+}
+
+// QueueCap returns the capacity of the queue.
+func (a *AsyncBatcher) QueueCap() int {
+	return cap(a.queue)
+}
+
+// QueueHighWater returns the largest number of elements that were in the
+// queue at once.
+func (a *AsyncBatcher) QueueHighWater() int {
+	return int(a.highWater.Load())
+}
+
+// queued updates the high-water mark after an element was queued.
+func (a *AsyncBatcher) queued() {
+	n := int64(len(a.queue))
+	for {
+		hw := a.highWater.Load()
+		if n <= hw || a.highWater.CompareAndSwap(hw, n) {
+			return
+		}
+	}
+}
+
+// Close stops accepting data, waits for the queued data to be batched and
+// written and closes the underlying Batcher. Calling Close more than once
+// returns ErrClosed.
+func (a *AsyncBatcher) Close() error {
+	_, err := a.Drain(context.Background())
+	return err
+}
+
+// Drain is like Close but gives up once ctx expires. It reports how many of
+// the queued and pending elements could not be delivered, see
+// Batcher.Drain. If ctx expires first, Drain returns straight away with the
+// number of elements left in the queue, without waiting for a write that is
+// in progress. The context of that write is canceled, and the underlying
+// Batcher is closed once it returns.
+func (a *AsyncBatcher) Drain(ctx context.Context) (int, error) {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return 0, ErrClosed
+	}
+	a.closed = true
+	close(a.queue)
+	a.mu.Unlock()
+
+	type result struct {
+		undelivered int
+		err         error
+	}
+	drained := make(chan result, 1)
+	go func() {
+		<-a.done
+		a.cancel()
+		queued := len(a.queue)
+		undelivered, err := a.b.Drain(ctx)
+		drained <- result{undelivered: queued + undelivered, err: err}
+	}()
+
+	select {
+	case r := <-drained:
+		return r.undelivered, r.err
+	case <-ctx.Done():
+		a.cancel()
+		return len(a.queue), ctx.Err()
+	}
+}
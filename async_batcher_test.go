@@ -0,0 +1,107 @@
+package batching_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("AsyncBatcher", func() {
+	It("batches and writes queued data", func() {
+		writer := &syncRecordingWriter{}
+		a := batching.NewAsyncBatcher(batching.NewBatcher(2, time.Hour, writer), 10)
+
+		a.Write("a")
+		a.Write("b")
+		a.Write("c")
+
+		Eventually(writer.all).Should(Equal([][]interface{}{{"a", "b"}}))
+
+		Expect(a.Close()).To(Succeed())
+		Expect(writer.all()).To(Equal([][]interface{}{{"a", "b"}, {"c"}}))
+	})
+
+	It("does not block writes on the writer", func() {
+		started := make(chan struct{}, 3)
+		release := make(chan struct{})
+		writer := batching.WriterFunc(func([]interface{}) {
+			started <- struct{}{}
+			<-release
+		})
+		a := batching.NewAsyncBatcher(batching.NewBatcher(1, time.Hour, writer), 2)
+
+		a.Write("a")
+		Eventually(started).Should(Receive())
+		Expect(a.TryWrite("b")).To(BeTrue())
+		Expect(a.TryWrite("c")).To(BeTrue())
+		Expect(a.TryWrite("d")).To(BeFalse())
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		Expect(a.WriteContext(ctx, "d")).To(MatchError(context.DeadlineExceeded))
+
+		close(release)
+		Expect(a.Close()).To(Succeed())
+	})
+
+	It("can be written to from multiple goroutines", func() {
+		writer := &countingWriter{}
+		a := batching.NewAsyncBatcher(batching.NewBatcher(10, time.Hour, writer), 10)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					a.Write(j)
+				}
+			}()
+		}
+		wg.Wait()
+		Expect(a.Close()).To(Succeed())
+
+		Expect(writer.items()).To(Equal(1000))
+	})
+
+	It("rejects data once closed", func() {
+		a := batching.NewAsyncBatcher(batching.NewBatcher(10, time.Hour, &spyWriter{}), 10)
+		Expect(a.Close()).To(Succeed())
+
+		Expect(a.TryWrite("a")).To(BeFalse())
+		Expect(a.WriteContext(context.Background(), "a")).To(MatchError(batching.ErrClosed))
+		Expect(a.Close()).To(MatchError(batching.ErrClosed))
+	})
+
+	It("reports the queue depth and its high-water mark", func() {
+		started := make(chan struct{}, 1)
+		release := make(chan struct{})
+		writer := batching.WriterFunc(func([]interface{}) {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-release
+		})
+		a := batching.NewAsyncBatcher(batching.NewBatcher(1, time.Hour, writer), 3)
+
+		a.Write("a")
+		Eventually(started).Should(Receive())
+		a.Write("b")
+		a.Write("c")
+
+		Expect(a.QueueLen()).To(Equal(2))
+		Expect(a.QueueCap()).To(Equal(3))
+		Expect(a.QueueHighWater()).To(BeNumerically(">=", 2))
+
+		close(release)
+		Eventually(a.QueueLen).Should(BeZero())
+		Expect(a.QueueHighWater()).To(BeNumerically(">=", 2))
+		Expect(a.Close()).To(Succeed())
+	})
+})
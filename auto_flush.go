@@ -0,0 +1,44 @@
+package batching
+
+import "context"
+
+// StartAutoFlush starts a goroutine that flushes the Batcher whenever the
+// batch is due to be written because of the interval, until ctx is done or
+// the Batcher is closed. Callers no longer need to call Flush themselves.
+//
+// Since the writer is then invoked from another goroutine, the Batcher is made
+// safe for concurrent use as if created WithLocking. StartAutoFlush must
+// therefore be called before the Batcher is shared between goroutines.
+func (b *Batcher) StartAutoFlush(ctx context.Context) {
+	b.ensureLocking()
+
+	ctx, cancel := context.WithCancel(ctx)
+	b.mu.Lock()
+	b.stopAutoFlush = append(b.stopAutoFlush, cancel)
+	b.mu.Unlock()
+
+	go func() {
+		defer cancel()
+
+		fire := make(chan struct{}, 1)
+		for {
+			reconfigured := b.reconfiguredChan()
+			timer := b.afterFunc(b.untilDue(), func() {
+				select {
+				case fire <- struct{}{}:
+				default:
+				}
+			})
+
+			select {
+			case <-fire:
+				_, _ = b.FlushContext(ctx)
+			case <-reconfigured:
+				timer.Stop()
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,50 @@
+package batching_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("StartAutoFlush", func() {
+	It("flushes the batch when the interval lapses", func() {
+		writer := &syncRecordingWriter{}
+		b := batching.NewBatcher(10, 10*time.Millisecond, writer)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		b.StartAutoFlush(ctx)
+		b.Write("a")
+
+		Eventually(writer.all).Should(Equal([][]interface{}{{"a"}}))
+
+		b.Write("b")
+		Eventually(writer.all).Should(Equal([][]interface{}{{"a"}, {"b"}}))
+	})
+
+	It("stops flushing once the context is done", func() {
+		writer := &syncRecordingWriter{}
+		b := batching.NewBatcher(10, 20*time.Millisecond, writer)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		b.StartAutoFlush(ctx)
+		cancel()
+		b.Write("a")
+
+		Consistently(writer.all, 100*time.Millisecond).Should(BeEmpty())
+	})
+
+	It("stops flushing once the batcher is closed", func() {
+		writer := &syncRecordingWriter{}
+		b := batching.NewBatcher(10, 10*time.Millisecond, writer)
+
+		b.StartAutoFlush(context.Background())
+		Expect(b.Close()).To(Succeed())
+
+		Consistently(writer.all, 50*time.Millisecond).Should(BeEmpty())
+	})
+})
@@ -0,0 +1,117 @@
+package batching
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Backoff configures how a batch the writer failed to write is retried with
+// exponentially growing delays before the Batcher gives up on it. Once it
+// gives up, the RetryPolicy decides what happens to the batch.
+type Backoff struct {
+	// InitialInterval is the delay before the first retry. It defaults to
+	// 100ms, since retrying without a delay would never let the writer
+	// recover.
+	InitialInterval time.Duration
+
+	// MaxInterval caps the delay between retries. Zero means no cap.
+	MaxInterval time.Duration
+
+	// Multiplier is the factor the delay grows by after every retry. It
+	// defaults to 2.
+	Multiplier float64
+
+	// MaxAttempts is the maximum number of writes of the batch, including
+	// the first one. Zero means no limit.
+	MaxAttempts int
+
+	// MaxElapsedTime is the maximum time spent writing and waiting to retry
+	// the batch. Zero means no limit.
+	MaxElapsedTime time.Duration
+}
+
+// WithBackoff retries writing a batch that the writer failed to write
+// according to backoff. The retries happen within the write, so the Batcher
+// stays locked while it waits, unless it was created WithWriterConcurrency.
+// Waiting stops early once the context of the write is done. With neither
+// MaxAttempts nor MaxElapsedTime set the batch is retried until it is
+// written or the context is done.
+func WithBackoff(backoff Backoff) Option {
+	return func(b *Batcher) {
+		b.backoff = &backoff
+	}
+}
+
+const defaultInitialInterval = 100 * time.Millisecond
+
+// backoffWriter is a ContextWriter that retries failed writes to the
+// underlying ContextWriter. It is installed on every Batcher, so that
+// ApplyConfig can enable retries, and writes once while the Backoff is nil.
+type backoffWriter struct {
+	w       ContextWriter
+	backoff *atomic.Pointer[Backoff]
+	clock   Clock
+}
+
+func (b *Batcher) startBackoff() {
+	b.retry.Store(b.backoff)
+	b.w = backoffWriter{w: b.w, backoff: &b.retry, clock: b.clock}
+}
+
+func (w backoffWriter) Write(ctx context.Context, batch []interface{}) error {
+	backoff := w.backoff.Load()
+	if backoff == nil {
+		return w.w.Write(ctx, batch)
+	}
+
+	multiplier := backoff.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	delay := backoff.InitialInterval
+	if delay <= 0 {
+		delay = defaultInitialInterval
+	}
+
+	start := w.clock.Now()
+	for attempt := 1; ; attempt++ {
+		err := w.w.Write(ctx, batch)
+		if err == nil {
+			return nil
+		}
+
+		if backoff.MaxAttempts > 0 && attempt >= backoff.MaxAttempts {
+			return err
+		}
+		if backoff.MaxElapsedTime > 0 && w.clock.Since(start)+delay > backoff.MaxElapsedTime {
+			return err
+		}
+
+		if !sleep(ctx, w.clock, delay) {
+			return err
+		}
+
+		delay = time.Duration(float64(delay) * multiplier)
+		if backoff.MaxInterval > 0 {
+			delay = min(delay, backoff.MaxInterval)
+		}
+	}
+}
+
+// sleep waits for d to elapse on clock. It returns false if ctx is done
+// first.
+func sleep(ctx context.Context, clock Clock, d time.Duration) bool {
+	elapsed := make(chan struct{})
+	timer := afterFunc(clock, d, func() {
+		close(elapsed)
+	})
+	select {
+	case <-elapsed:
+		return true
+	case <-ctx.Done():
+		timer.Stop()
+		return false
+	}
+}
@@ -0,0 +1,134 @@
+package batching_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+	"code.cloudfoundry.org/go-batching/batchingtest"
+)
+
+var _ = Describe("Backoff", func() {
+	var writeErr = errors.New("failed")
+
+	It("retries a batch until it is written", func() {
+		writer := &recordingFallibleWriter{errs: []error{writeErr, writeErr}}
+		b := batching.NewFallibleBatcher(1, time.Minute, writer,
+			batching.WithBackoff(batching.Backoff{InitialInterval: time.Millisecond}),
+		)
+
+		b.Write("a")
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"a"}, {"a"}, {"a"}}))
+		Expect(b.Stats().WriteErrors).To(BeZero())
+	})
+
+	It("grows the delay between retries", func() {
+		var attempts []time.Time
+		writer := batching.FallibleWriterFunc(func([]interface{}) error {
+			attempts = append(attempts, time.Now())
+			return writeErr
+		})
+		b := batching.NewFallibleBatcher(1, time.Minute, writer,
+			batching.WithBackoff(batching.Backoff{
+				InitialInterval: 10 * time.Millisecond,
+				Multiplier:      3,
+				MaxAttempts:     3,
+			}),
+		)
+
+		b.Write("a")
+
+		Expect(attempts).To(HaveLen(3))
+		Expect(attempts[1].Sub(attempts[0])).To(BeNumerically(">=", 10*time.Millisecond))
+		Expect(attempts[2].Sub(attempts[1])).To(BeNumerically(">=", 30*time.Millisecond))
+	})
+
+	It("gives up after the maximum attempts and applies the RetryPolicy", func() {
+		writer := &recordingFallibleWriter{errs: []error{writeErr, writeErr, writeErr}}
+		b := batching.NewFallibleBatcher(1, time.Minute, writer,
+			batching.WithBackoff(batching.Backoff{InitialInterval: time.Millisecond, MaxAttempts: 2}),
+			batching.WithRetryPolicy(batching.RetainOnError(0)),
+		)
+
+		b.Write("a")
+
+		Expect(writer.batches).To(HaveLen(2))
+		Expect(b.Peek()).To(Equal([]interface{}{"a"}))
+	})
+
+	It("gives up once the maximum elapsed time would be exceeded", func() {
+		writer := &recordingFallibleWriter{errs: []error{writeErr, writeErr, writeErr}}
+		b := batching.NewFallibleBatcher(1, time.Minute, writer,
+			batching.WithBackoff(batching.Backoff{
+				InitialInterval: 10 * time.Millisecond,
+				MaxElapsedTime:  15 * time.Millisecond,
+			}),
+		)
+
+		b.Write("a")
+
+		Expect(writer.batches).To(HaveLen(2))
+	})
+
+	It("stops waiting once the context is done", func() {
+		writer := &recordingFallibleWriter{errs: []error{writeErr, writeErr}}
+		b := batching.NewFallibleBatcher(1, time.Minute, writer,
+			batching.WithBackoff(batching.Backoff{InitialInterval: time.Hour}),
+		)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		Expect(b.WriteContext(ctx, "a")).To(MatchError(writeErr))
+		Expect(writer.batches).To(HaveLen(1))
+	})
+
+	It("waits for the delay on the clock", func() {
+		clock := batchingtest.NewClock(time.Unix(0, 0))
+		spy := batchingtest.NewSpyWriter()
+		spy.FailNext(writeErr)
+		b := batching.NewContextBatcher(1, time.Minute, spy,
+			batching.WithClock(clock),
+			batching.WithBackoff(batching.Backoff{InitialInterval: time.Hour}),
+		)
+
+		done := make(chan error)
+		go func() {
+			done <- b.WriteContext(context.Background(), "a")
+		}()
+		Eventually(clock.Timers).Should(Equal(1))
+		Consistently(done).ShouldNot(Receive())
+
+		clock.Advance(time.Hour)
+
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(spy.Calls()).To(Equal(2))
+	})
+
+	It("waits for a default delay without an initial interval", func() {
+		clock := batchingtest.NewClock(time.Unix(0, 0))
+		spy := batchingtest.NewSpyWriter()
+		spy.FailNext(writeErr)
+		b := batching.NewContextBatcher(1, time.Minute, spy,
+			batching.WithClock(clock),
+			batching.WithBackoff(batching.Backoff{}),
+		)
+
+		done := make(chan error)
+		go func() {
+			done <- b.WriteContext(context.Background(), "a")
+		}()
+		Eventually(clock.Timers).Should(Equal(1))
+
+		clock.Advance(99 * time.Millisecond)
+		Consistently(done).ShouldNot(Receive())
+		clock.Advance(time.Millisecond)
+
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(spy.Calls()).To(Equal(2))
+	})
+})
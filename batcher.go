@@ -1,20 +1,146 @@
 package batching
 
-import "time"
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 // Batcher will accept messages and invoke the Writer when the batch
 // requirements have been fulfilled (either batch size or interval have been
 // exceeded). Batcher should be created with NewBatcher().
 type Batcher struct {
-	w        Writer
+	mu       sync.Locker
+	w        ContextWriter
 	size     int
 	interval time.Duration
 	batch    []interface{}
 	lastSent time.Time
+	clock    Clock
+
+	lastReason FlushReason
+
+	jitter          float64
+	currentInterval time.Duration
+	alignInterval   bool
+
+	schedule      func(after time.Time) time.Time
+	nextScheduled time.Time
+
+	minInterval time.Duration
+	maxInterval time.Duration
+	arrivals    int
+
+	quietPeriod time.Duration
+	maxWait     time.Duration
+	lastItemAt  time.Time
+
+	maxBytes     int
+	sizeFn       func(data interface{}) int
+	pendingBytes int
+
+	weightFn      func(data interface{}) int
+	pendingWeight int
+
+	retryPolicy    RetryPolicy
+	retains        bool
+	backoff        *Backoff
+	retry          atomic.Pointer[Backoff]
+	reconfigured   chan struct{}
+	circuitBreaker *CircuitBreaker
+	failures       int
+	deadLetter     Writer
+	spool          Spool
+	wal            *WAL
+
+	reserved        map[uint64][]interface{}
+	nextReservation uint64
+
+	batchPool    *sync.Pool
+	reuseBatches bool
+	preallocate  bool
+	capacity     int
+
+	firstItemAt time.Time
+	maxItemAge  time.Duration
+	ageDeadline time.Time
+	ageTimer    Timer
+
+	pendingLimit   int
+	overflowPolicy OverflowPolicy
+	onOverflow     func(dropped []interface{})
+
+	heartbeat bool
+
+	minSize    int
+	minMaxWait time.Duration
+
+	minFlushInterval time.Duration
+
+	stampBatches bool
+	itemContexts bool
+	sequence     uint64
+	batchIDs     bool
+	stamped      BatchInfo
+	retained     int
+	retainedInfo BatchInfo
+
+	memory       *MemoryLimiter
+	memoryMember *memoryMember
+
+	onDrop       func(dropped []interface{})
+	onDropReason func(dropped []interface{}, reason DropReason)
+	metrics      Metrics
+	itemTTL      time.Duration
+	timestampFn  func(data interface{}) time.Time
+	dedupKey     func(data interface{}) interface{}
+	filter       func(data interface{}) bool
+	transform    func(data interface{}) interface{}
+	less         func(a, b interface{}) bool
+
+	slowWrite        time.Duration
+	logger           *slog.Logger
+	lastErrorLog     time.Time
+	suppressedErrors int
+
+	beforeFlush []func(batch []interface{})
+	afterFlush  []func(batch []interface{}, err error, d time.Duration)
+
+	stats Stats
+
+	stopAutoFlush []context.CancelFunc
+
+	writerConcurrency int
+	maxInFlight       int
+	backpressure      BackpressurePolicy
+	pool              *writerPool
+
+	closed bool
+}
+
+// FlushResult describes the outcome of a flush.
+type FlushResult struct {
+	// Written reports whether the writer was invoked.
+	Written bool
+
+	// Items is the number of elements submitted to the writer.
+	Items int
+
+	// Reason is why the batch was written. It is only meaningful if
+	// Written is true.
+	Reason FlushReason
 }
 
 // Writer is used to submit the completed batch. The batch may be partial if
-// the interval lapsed instead of filling the batch.
+// the interval lapsed instead of filling the batch. The writer owns the batch
+// it is passed: the Batcher never reads, modifies or reuses it once it is
+// submitted, so the writer may retain it without copying it, unless the
+// Batcher was created WithBatchReuse.
 type Writer interface {
 	// Write submits the batch.
 	Write(batch []interface{})
@@ -28,66 +154,386 @@ func (f WriterFunc) Write(batch []interface{}) {
 	f(batch)
 }
 
-// NewBatcher creates a new Batcher. It is recommenended to use a wrapper type
-// such as NewByteBatcher or NewV2EnvelopeBatcher vs using this directly.
-func NewBatcher(size int, interval time.Duration, writer Writer) *Batcher {
-	return &Batcher{
-		size:     size,
-		interval: interval,
-		w:        writer,
-		lastSent: time.Now(),
+// NewBatcher creates a new Batcher. It is recommended to use a wrapper type
+// such as NewByteBatcher vs using this directly. Batchers of other types,
+// such as loggregator envelopes, can flush by their encoded size by combining
+// WithSizeFunc and WithMaxBytes.
+func NewBatcher(size int, interval time.Duration, writer Writer, opts ...Option) *Batcher {
+	return newBatcher(size, interval, infallibleWriter{w: writer}, opts)
+}
+
+func newBatcher(size int, interval time.Duration, w ContextWriter, opts []Option) *Batcher {
+	b := &Batcher{
+		mu:          nopLocker{},
+		size:        size,
+		interval:    interval,
+		w:           w,
+		clock:       systemClock{},
+		retryPolicy: DropOnError,
+	}
+	for _, o := range opts {
+		o(b)
 	}
+	b.recoverWAL()
+	b.restartInterval()
+	b.startSchedule()
+	b.startBackoff()
+	b.startCircuitBreaker()
+	b.startWriterPool()
+
+	return b
+}
+
+// NewBatcherWithByteLimit creates a new Batcher that submits the batch once
+// adding to it would exceed maxBytes, as measured by sizeFn, or the interval
+// has lapsed. This is useful when the downstream has a payload size limit. A
+// single element larger than maxBytes is written in a batch of its own.
+func NewBatcherWithByteLimit(
+	maxBytes int,
+	interval time.Duration,
+	sizeFn func(data interface{}) int,
+	writer Writer,
+	opts ...Option,
+) *Batcher {
+	opts = append([]Option{WithMaxBytes(maxBytes), WithSizeFunc(sizeFn)}, opts...)
+	return NewBatcher(math.MaxInt, interval, writer, opts...)
 }
 
 // Write stores data to the batch. It will not submit the batch to the writer
 // until either the batch has been filled, or the interval has lapsed. NOTE:
-// Write is *not* thread safe and should be called by the same goroutine that
-// calls Flush.
+// Write is *not* thread safe unless the Batcher was created WithLocking and
+// should otherwise be called by the same goroutine that calls Flush.
 func (b *Batcher) Write(data interface{}) {
-	b.batch = append(b.batch, data)
-	if b.partialBatch() && b.partialInterval() {
-		return
-	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
 
-	b.writeBatch()
+	_ = b.write(context.Background(), data)
 }
 
 // ForcedFlush bypasses the batch interval and batch size checks and writes
-// immediately.
-func (b *Batcher) ForcedFlush() {
-	b.writeBatch()
+// immediately. It reports whether the writer was invoked and with how many
+// elements.
+func (b *Batcher) ForcedFlush() FlushResult {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	res, _ := b.writeBatch(context.Background(), FlushForced)
+	return res
 }
 
 // Flush will write a partial batch if there is data and the interval has
 // lapsed. Otherwise it is a NOP. This method should be called freqently to
 // make sure batches do not stick around for long periods of time. As a result
 // it would be a bad idea to call Flush after an operation that might block
-// for an un-specified amount of time. NOTE: Flush is *not* thread safe and
-// should be called by the same goroutine that calls Write.
-func (b *Batcher) Flush() {
-	if b.partialInterval() {
-		return
+// for an un-specified amount of time. NOTE: Flush is *not* thread safe unless
+// the Batcher was created WithLocking and should otherwise be called by the
+// same goroutine that calls Write. Flush reports whether the writer was
+// invoked and with how many elements.
+func (b *Batcher) Flush() FlushResult {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	res, _ := b.flush(context.Background())
+	return res
+}
+
+func (b *Batcher) write(ctx context.Context, data interface{}) error {
+	if b.closed {
+		return ErrClosed
+	}
+	data, ok := b.accept(data)
+	if !ok {
+		return nil
 	}
 
-	b.writeBatch()
+	size := b.sizeOf(data)
+	if err := b.makeRoom(data); err != nil {
+		return err
+	}
+	if b.exceedsBytes(size) {
+		if _, err := b.writeBatch(ctx, FlushSize); err != nil {
+			_ = b.store(data, size)
+			return err
+		}
+	}
+
+	walErr := b.store(data, size)
+	reason, ok := b.trigger()
+	if !ok {
+		return walErr
+	}
+
+	if _, err := b.writeBatch(ctx, reason); err != nil {
+		return err
+	}
+	return walErr
 }
 
-// writeBatch writes the batch (if any) to the writer and resets the batch and
-// interval.
-func (b *Batcher) writeBatch() {
+func (b *Batcher) add(data interface{}, size int) {
+	if b.batch == nil {
+		b.batch = b.newBatch()
+	}
 	if len(b.batch) == 0 {
-		return
+		b.firstItemAt = b.clock.Now()
+		b.startAgeTimer()
+	}
+	if b.quietPeriod > 0 {
+		b.lastItemAt = b.clock.Now()
+	}
+	b.batch = append(b.batch, data)
+	b.stats.ItemsWritten++
+	b.arrivals++
+	b.pendingBytes += size
+	if b.weightFn != nil {
+		b.pendingWeight += b.weightFn(data)
+	}
+	b.observePending()
+}
+
+func (b *Batcher) flush(ctx context.Context) (FlushResult, error) {
+	if b.closed {
+		return FlushResult{}, ErrClosed
+	}
+	if b.heartbeat && len(b.batch) == 0 && !b.partialInterval() {
+		return b.writeBatch(ctx, FlushHeartbeat)
+	}
+	if b.idleInterval() {
+		b.restartInterval()
+		return FlushResult{}, nil
+	}
+	reason, ok := b.due()
+	if !ok {
+		return FlushResult{}, nil
+	}
+
+	return b.writeBatch(ctx, reason)
+}
+
+// writeBatch writes the batch (if any) to the writer and resets the batch and
+// interval. A batch larger than the batch size or the byte limit is written in
+// several chunks. If a write fails the remaining data is only reset if the
+// RetryPolicy does not retain it, otherwise the next chunk is written. The
+// batch is left untouched if ctx is already done.
+func (b *Batcher) writeBatch(ctx context.Context, reason FlushReason) (FlushResult, error) {
+	if b.closed {
+		return FlushResult{}, ErrClosed
+	}
+	if len(b.batch) == 0 && reason != FlushHeartbeat {
+		return FlushResult{}, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return FlushResult{}, err
+	}
+	if b.dropExpired() {
+		b.observePending()
+		return FlushResult{}, nil
+	}
+	if b.shed(reason) {
+		b.observePending()
+		return FlushResult{}, nil
+	}
+	b.dedup()
+	b.sort()
+
+	res := FlushResult{Written: true, Reason: reason}
+	b.restartInterval()
+	defer b.observePending()
+	buf := b.batch[:0]
+
+	var firstErr error
+	for {
+		c := b.nextChunk()
+		n := c.n
+		chunk := b.batch[:n:n]
+		if b.keepsFailed() && !b.reusesBatches() {
+			chunk = slices.Clone(chunk)
+		}
+		err := b.submit(ctx, reason, chunk)
+
+		if notSubmitted, ok := asNotSubmitted(err); ok {
+			b.retain(n)
+			res.Written = res.Items > 0
+			if firstErr == nil {
+				firstErr = notSubmitted.err
+			}
+			return res, firstErr
+		}
+
+		if partial, ok := asPartialWrite(err); ok {
+			res.Items += n
+			b.stats.flushed(n-len(partial.unwritten), reason, partial.err, b.lastSent)
+			if n == len(b.batch) {
+				b.reset()
+			} else {
+				b.consume(c)
+			}
+			b.requeue(partial.unwritten)
+			if firstErr == nil {
+				firstErr = partial.err
+			}
+			return res, firstErr
+		}
+
+		res.Items += n
+		b.stats.flushed(n, reason, err, b.lastSent)
+		if errors.Is(err, ErrCircuitOpen) {
+			if firstErr == nil {
+				firstErr = err
+			}
+			b.dropped(b.batch[:n:n], DropCircuitOpen)
+		} else if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			b.failures++
+			if b.retryPolicy.Retain(b.failures, err) {
+				if b.reusesBatches() {
+					b.batch = slices.Clone(b.batch)
+				}
+				b.retain(n)
+				b.logRetained(err)
+				b.startAgeTimer()
+				return res, firstErr
+			}
+			b.giveUp(b.batch[:n:n])
+		}
+
+		if n == len(b.batch) {
+			b.reset()
+			if firstErr == nil {
+				b.reuse(buf)
+			}
+			if err == nil {
+				b.truncateWAL()
+				res.Items += b.replaySpool(ctx, reason)
+			}
+			return res, firstErr
+		}
+		b.consume(c)
+
+		if err := ctx.Err(); err != nil && firstErr == nil {
+			return res, err
+		}
+	}
+}
+
+// keepsFailed reports whether the elements of a batch may still be needed
+// once the writer failed to write it, to retain, spool or report them. The
+// writer owns the batch it is passed even if it fails, so it is then passed
+// a copy, unless the Batcher reuses batches and therefore keeps ownership.
+func (b *Batcher) keepsFailed() bool {
+	return b.retains || b.spool != nil || b.deadLetter != nil ||
+		b.onDrop != nil || b.onDropReason != nil || b.circuitBreaker != nil
+}
+
+// submit runs the flush hooks around writing a single batch to the writer.
+func (b *Batcher) submit(ctx context.Context, reason FlushReason, batch []interface{}) error {
+	for _, f := range b.beforeFlush {
+		f(batch)
+	}
+
+	// The writer owns the batch once it is submitted and may release it, so
+	// the after flush hooks get a copy.
+	var written []interface{}
+	if len(b.afterFlush) > 0 {
+		written = slices.Clone(batch)
+	}
+
+	n := len(batch)
+	b.lastReason = reason
+	start := b.clock.Now()
+	err := b.w.Write(b.withItemContexts(b.stamp(ctx), batch), batch)
+	d := b.clock.Since(start)
+	b.handedOver(err)
+
+	for _, f := range b.afterFlush {
+		f(written, err, d)
 	}
+	b.observeLatency(n, d)
+	b.observeFlush(reason, n, err, d)
+	b.logFlush(reason, n, err, d)
 
-	b.w.Write(b.batch)
+	return err
+}
+
+// reset empties the pending batch.
+func (b *Batcher) reset() {
 	b.batch = nil
-	b.lastSent = time.Now()
+	b.pendingBytes = 0
+	b.pendingWeight = 0
+	b.failures = 0
+	b.retained = 0
+	b.stopAgeTimer()
 }
 
 func (b *Batcher) partialBatch() bool {
+	if b.weightFn != nil {
+		return b.pendingWeight < b.size
+	}
 	return len(b.batch) < b.size
 }
 
+func (b *Batcher) partialBytes() bool {
+	return b.maxBytes <= 0 || b.pendingBytes < b.maxBytes
+}
+
+// exceedsBytes reports whether adding an element of the given size to a
+// non-empty batch would exceed the byte limit.
+func (b *Batcher) exceedsBytes(size int) bool {
+	return b.maxBytes > 0 && len(b.batch) > 0 && b.pendingBytes+size > b.maxBytes
+}
+
+func (b *Batcher) sizeOf(data interface{}) int {
+	if b.sizeFn == nil {
+		return 0
+	}
+	return b.sizeFn(data)
+}
+
+// trigger reports whether and why the pending batch should be written after
+// data has been stored to it.
+func (b *Batcher) trigger() (FlushReason, bool) {
+	if b.pressured() {
+		return FlushMemory, true
+	}
+	if b.full() && !b.rateLimited() {
+		return FlushSize, true
+	}
+	return b.due()
+}
+
+// due reports whether and why a partial batch should be written because the
+// interval has lapsed or the batch has been pending for too long.
+func (b *Batcher) due() (FlushReason, bool) {
+	if b.pressured() {
+		return FlushMemory, true
+	}
+	if b.scheduled() {
+		return FlushScheduled, true
+	}
+	if b.minFlushInterval > 0 && b.full() && !b.rateLimited() {
+		return FlushSize, true
+	}
+	if b.quietPeriod > 0 {
+		if reason, ok := b.debounced(); ok {
+			return reason, true
+		}
+	} else if !b.partialInterval() && b.enoughForInterval() {
+		return FlushInterval, true
+	}
+	if b.expired() {
+		return FlushAge, true
+	}
+	return 0, false
+}
+
 func (b *Batcher) partialInterval() bool {
-	return time.Since(b.lastSent) < b.interval
+	return b.clock.Since(b.lastSent) < b.currentInterval
 }
+
+// nopLocker is the sync.Locker used by batchers that are only accessed from
+// a single goroutine.
+type nopLocker struct{}
+
+func (nopLocker) Lock()   {}
+func (nopLocker) Unlock() {}
@@ -116,8 +116,74 @@ var _ = Describe("Batcher", func() {
 
 		Expect(writer.called).To(Equal(0))
 	})
+
+	It("reports the result of a flush", func() {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		writer := &spyWriter{}
+		b := batching.NewBatcher(3, time.Minute, writer, batching.WithClock(clock))
+
+		b.Write("item")
+		b.Write("other-item")
+		Expect(b.Flush()).To(Equal(batching.FlushResult{}))
+
+		clock.Advance(time.Minute)
+
+		Expect(b.Flush()).To(Equal(batching.FlushResult{Written: true, Items: 2, Reason: batching.FlushInterval}))
+	})
+
+	It("reports the result of a forced flush", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(3, time.Minute, writer)
+
+		Expect(b.ForcedFlush()).To(Equal(batching.FlushResult{}))
+
+		b.Write("item")
+
+		Expect(b.ForcedFlush()).To(Equal(batching.FlushResult{Written: true, Items: 1, Reason: batching.FlushForced}))
+	})
+
+	Context("with a byte limit", func() {
+		It("writes the batch before it would exceed the limit", func() {
+			writer := &spyWriter{}
+			b := batching.NewBatcherWithByteLimit(10, time.Minute, strLen, writer)
+
+			b.Write("12345")
+			b.Write("1234")
+			Expect(writer.called).To(Equal(0))
+
+			b.Write("12")
+			Expect(writer.called).To(Equal(1))
+			Expect(writer.batch).To(Equal([]interface{}{"12345", "1234"}))
+		})
+
+		It("writes the batch once the limit is reached", func() {
+			writer := &spyWriter{}
+			b := batching.NewBatcherWithByteLimit(10, time.Minute, strLen, writer)
+
+			b.Write("12345")
+			b.Write("12345")
+
+			Expect(writer.called).To(Equal(1))
+			Expect(writer.batch).To(HaveLen(2))
+		})
+
+		It("writes an element larger than the limit on its own", func() {
+			writer := &spyWriter{}
+			b := batching.NewBatcherWithByteLimit(10, time.Minute, strLen, writer)
+
+			b.Write("1")
+			b.Write("12345678901")
+
+			Expect(writer.called).To(Equal(2))
+			Expect(writer.batch).To(Equal([]interface{}{"12345678901"}))
+		})
+	})
 })
 
+func strLen(data interface{}) int {
+	return len(data.(string))
+}
+
 type spyWriter struct {
 	batch  []interface{}
 	called int
@@ -0,0 +1,13 @@
+package batchingtest_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestBatchingtest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Batchingtest Suite")
+}
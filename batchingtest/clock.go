@@ -0,0 +1,125 @@
+// Package batchingtest provides helpers for testing code that uses batchers
+// without waiting for real time to pass.
+package batchingtest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+// Clock is a batching.TimerClock whose time only passes when it is advanced.
+// It is safe for concurrent use, so it can also drive batchers that write
+// from timers or other goroutines. Clock should be created with NewClock().
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*timer
+}
+
+// NewClock returns a Clock set to now.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now implements batching.Clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Since implements batching.Clock.
+func (c *Clock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// AfterFunc implements batching.TimerClock. Unlike time.AfterFunc, f is
+// called from the goroutine advancing the Clock once d has elapsed, so that
+// its effects are visible as soon as Advance returns.
+func (c *Clock) AfterFunc(d time.Duration, f func()) batching.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &timer{clock: c, deadline: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the Clock forward by d, calling the functions of any timers
+// that expire in the order of their deadlines, with the Clock set to each
+// deadline in turn.
+func (c *Clock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the Clock to t like Advance. The Clock never moves backwards, so
+// a t before the current time only fires the timers that are already due.
+func (c *Clock) Set(t time.Time) {
+	for {
+		c.mu.Lock()
+		next := c.nextTimer(t)
+		if next == nil {
+			if t.After(c.now) {
+				c.now = t
+			}
+			c.mu.Unlock()
+			return
+		}
+		if next.deadline.After(c.now) {
+			c.now = next.deadline
+		}
+		c.remove(next)
+		c.mu.Unlock()
+
+		next.f()
+	}
+}
+
+// Timers returns the number of timers that have not expired or been stopped.
+// It can be used to wait until a goroutine under test has scheduled a timer
+// before advancing the Clock.
+func (c *Clock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.timers)
+}
+
+// nextTimer returns the timer with the earliest deadline at or before t.
+func (c *Clock) nextTimer(t time.Time) *timer {
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].deadline.Before(c.timers[j].deadline)
+	})
+	if len(c.timers) == 0 || c.timers[0].deadline.After(t) {
+		return nil
+	}
+	return c.timers[0]
+}
+
+func (c *Clock) remove(t *timer) bool {
+	for i, other := range c.timers {
+		if other == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type timer struct {
+	clock    *Clock
+	deadline time.Time
+	f        func()
+}
+
+// Stop implements batching.Timer.
+func (t *timer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	return t.clock.remove(t)
+}
@@ -0,0 +1,97 @@
+package batchingtest_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+	"code.cloudfoundry.org/go-batching/batchingtest"
+)
+
+var _ = Describe("Clock", func() {
+	var (
+		start time.Time
+		clock *batchingtest.Clock
+	)
+
+	BeforeEach(func() {
+		start = time.Unix(1000, 0)
+		clock = batchingtest.NewClock(start)
+	})
+
+	It("only passes time when advanced", func() {
+		Expect(clock.Now()).To(Equal(start))
+
+		clock.Advance(time.Minute)
+
+		Expect(clock.Now()).To(Equal(start.Add(time.Minute)))
+		Expect(clock.Since(start)).To(Equal(time.Minute))
+	})
+
+	It("does not move backwards", func() {
+		clock.Set(start.Add(-time.Minute))
+
+		Expect(clock.Now()).To(Equal(start))
+	})
+
+	It("calls expired timers in the order of their deadlines", func() {
+		var fired []time.Time
+		record := func() { fired = append(fired, clock.Now()) }
+		clock.AfterFunc(2*time.Second, record)
+		clock.AfterFunc(time.Second, record)
+		clock.AfterFunc(time.Hour, record)
+
+		clock.Advance(time.Minute)
+
+		Expect(fired).To(Equal([]time.Time{start.Add(time.Second), start.Add(2 * time.Second)}))
+		Expect(clock.Now()).To(Equal(start.Add(time.Minute)))
+		Expect(clock.Timers()).To(Equal(1))
+	})
+
+	It("calls timers scheduled by an expired timer", func() {
+		var fired int
+		var tick func()
+		tick = func() {
+			fired++
+			clock.AfterFunc(time.Second, tick)
+		}
+		clock.AfterFunc(time.Second, tick)
+
+		clock.Advance(5 * time.Second)
+
+		Expect(fired).To(Equal(5))
+	})
+
+	It("does not call stopped timers", func() {
+		t := clock.AfterFunc(time.Second, func() {
+			Fail("stopped timer fired")
+		})
+
+		Expect(t.Stop()).To(BeTrue())
+		Expect(t.Stop()).To(BeFalse())
+		clock.Advance(time.Minute)
+	})
+
+	It("drives batchers that write from timers", func() {
+		writer := &recordingWriter{}
+		b := batching.NewBatcher(10, time.Hour, writer,
+			batching.WithClock(clock),
+			batching.WithMaxItemAge(time.Second),
+		)
+
+		b.Write("item")
+		clock.Advance(time.Second)
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"item"}}))
+	})
+})
+
+type recordingWriter struct {
+	batches [][]interface{}
+}
+
+func (w *recordingWriter) Write(batch []interface{}) {
+	w.batches = append(w.batches, batch)
+}
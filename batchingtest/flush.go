@@ -0,0 +1,44 @@
+package batchingtest
+
+import (
+	"code.cloudfoundry.org/go-batching"
+)
+
+// TB is the subset of testing.TB used to report failed expectations. It is
+// also implemented by GinkgoT().
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// ExpectFlush calls Flush on b and reports an error to t unless the batch
+// was written for reason.
+func ExpectFlush(t TB, b *batching.Batcher, reason batching.FlushReason) batching.FlushResult {
+	t.Helper()
+
+	res := b.Flush()
+	switch {
+	case !res.Written:
+		t.Errorf("expected a flush because of %s, but the batch was not written", reason)
+	case res.Reason != reason:
+		t.Errorf("expected a flush because of %s, but the batch was written because of %s", reason, res.Reason)
+	}
+	return res
+}
+
+// ExpectNoFlush calls Flush on b and reports an error to t if the batch was
+// written.
+func ExpectNoFlush(t TB, b *batching.Batcher) {
+	t.Helper()
+
+	if res := b.Flush(); res.Written {
+		t.Errorf("expected no flush, but %d elements were written because of %s", res.Items, res.Reason)
+	}
+}
+
+// FlushWhenDue advances c to the time b reports as its next flush time, see
+// Batcher.NextFlushTime, and calls Flush on b. b must use c as its clock.
+func FlushWhenDue(c *Clock, b *batching.Batcher) batching.FlushResult {
+	c.Set(b.NextFlushTime())
+	return b.Flush()
+}
@@ -0,0 +1,106 @@
+package batchingtest_test
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+	"code.cloudfoundry.org/go-batching/batchingtest"
+)
+
+var _ = Describe("Flush helpers", func() {
+	var (
+		clock *batchingtest.Clock
+		b     *batching.Batcher
+		t     *fakeTB
+	)
+
+	BeforeEach(func() {
+		clock = batchingtest.NewClock(time.Unix(0, 0))
+		b = batching.NewBatcher(10, time.Second, &recordingWriter{},
+			batching.WithClock(clock),
+		)
+		t = &fakeTB{}
+	})
+
+	Describe("ExpectFlush", func() {
+		It("passes if the batch is written for the reason", func() {
+			b.Write("item")
+			clock.Advance(time.Second)
+
+			res := batchingtest.ExpectFlush(t, b, batching.FlushInterval)
+
+			Expect(t.errors).To(BeEmpty())
+			Expect(res.Items).To(Equal(1))
+		})
+
+		It("fails if the batch is not written", func() {
+			b.Write("item")
+
+			batchingtest.ExpectFlush(t, b, batching.FlushInterval)
+
+			Expect(t.errors).To(ConsistOf(ContainSubstring("was not written")))
+		})
+
+		It("fails if the batch is written for another reason", func() {
+			b = batching.NewBatcher(10, time.Second, &recordingWriter{},
+				batching.WithClock(clock),
+				batching.WithDebounce(time.Millisecond, 0),
+			)
+			b.Write("item")
+			clock.Advance(time.Second)
+
+			batchingtest.ExpectFlush(t, b, batching.FlushInterval)
+
+			Expect(t.errors).To(ConsistOf(ContainSubstring("because of quiet")))
+		})
+	})
+
+	Describe("ExpectNoFlush", func() {
+		It("passes if the batch is not written", func() {
+			b.Write("item")
+
+			batchingtest.ExpectNoFlush(t, b)
+
+			Expect(t.errors).To(BeEmpty())
+		})
+
+		It("fails if the batch is written", func() {
+			b.Write("item")
+			clock.Advance(time.Second)
+
+			batchingtest.ExpectNoFlush(t, b)
+
+			Expect(t.errors).To(ConsistOf("expected no flush, but 1 elements were written because of interval"))
+		})
+	})
+
+	It("FlushWhenDue advances the clock until the batch is due", func() {
+		clock.Advance(300 * time.Millisecond)
+		b.Write("item")
+
+		res := batchingtest.FlushWhenDue(clock, b)
+
+		Expect(res.Written).To(BeTrue())
+		Expect(clock.Now()).To(Equal(time.Unix(1, 0)))
+	})
+
+	It("accepts GinkgoT", func() {
+		b.Write("item")
+
+		batchingtest.ExpectNoFlush(GinkgoT(), b)
+	})
+})
+
+type fakeTB struct {
+	errors []string
+}
+
+func (t *fakeTB) Helper() {}
+
+func (t *fakeTB) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,191 @@
+package batchingtest
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+// SpyWriter is a batching.ContextWriter that records the batches written to
+// it. It can be made to fail or to take time to write, and it is safe for
+// concurrent use, so it can be used with batchers that write from other
+// goroutines. SpyWriter should be created with NewSpyWriter().
+type SpyWriter struct {
+	mu       sync.Mutex
+	batches  [][]interface{}
+	calls    int
+	err      error
+	failures []error
+	latency  time.Duration
+	changed  chan struct{}
+}
+
+// NewSpyWriter returns a SpyWriter that successfully writes every batch.
+func NewSpyWriter() *SpyWriter {
+	return &SpyWriter{changed: make(chan struct{})}
+}
+
+// Write implements batching.ContextWriter. It waits for the latency, or
+// until ctx is done, and then records a copy of the batch unless the write
+// fails.
+func (w *SpyWriter) Write(ctx context.Context, batch []interface{}) error {
+	w.mu.Lock()
+	latency := w.latency
+	w.mu.Unlock()
+
+	if latency > 0 {
+		t := time.NewTimer(latency)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.calls++
+	err := w.err
+	if len(w.failures) > 0 {
+		err = w.failures[0]
+		w.failures = w.failures[1:]
+	}
+	if err == nil {
+		w.batches = append(w.batches, slices.Clone(batch))
+	}
+	close(w.changed)
+	w.changed = make(chan struct{})
+	return err
+}
+
+// Writer returns a batching.Writer that writes to w, ignoring errors.
+func (w *SpyWriter) Writer() batching.Writer {
+	return batching.WriterFunc(func(batch []interface{}) {
+		_ = w.Write(context.Background(), batch)
+	})
+}
+
+// FallibleWriter returns a batching.FallibleWriter that writes to w.
+func (w *SpyWriter) FallibleWriter() batching.FallibleWriter {
+	return batching.FallibleWriterFunc(func(batch []interface{}) error {
+		return w.Write(context.Background(), batch)
+	})
+}
+
+// ByteWriter returns a batching.ByteWriter that writes to w, ignoring
+// errors. The slices are recorded as elements of type []byte.
+func (w *SpyWriter) ByteWriter() batching.ByteWriter {
+	return batching.ByteWriterFunc(func(batch [][]byte) {
+		elems := make([]interface{}, len(batch))
+		for i, data := range batch {
+			elems[i] = data
+		}
+		_ = w.Write(context.Background(), elems)
+	})
+}
+
+// SetError makes every following write fail with err, or succeed if err is
+// nil. Failed batches are not recorded.
+func (w *SpyWriter) SetError(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.err = err
+}
+
+// FailNext makes the following writes fail with errs in turn before
+// falling back to the error set with SetError.
+func (w *SpyWriter) FailNext(errs ...error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.failures = append(w.failures, errs...)
+}
+
+// SetLatency makes every following write take d.
+func (w *SpyWriter) SetLatency(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.latency = d
+}
+
+// Batches returns the batches that were successfully written, in order.
+func (w *SpyWriter) Batches() [][]interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return slices.Clone(w.batches)
+}
+
+// Items returns the elements of all batches that were successfully written,
+// in order.
+func (w *SpyWriter) Items() []interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.items()
+}
+
+func (w *SpyWriter) items() []interface{} {
+	var items []interface{}
+	for _, batch := range w.batches {
+		items = append(items, batch...)
+	}
+	return items
+}
+
+// Calls returns how often the writer was invoked, including failed writes.
+func (w *SpyWriter) Calls() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.calls
+}
+
+// WaitForBatches waits until at least n batches were successfully written
+// and returns them. It returns the batches written so far and the error of
+// ctx if ctx is done first.
+func (w *SpyWriter) WaitForBatches(ctx context.Context, n int) ([][]interface{}, error) {
+	for {
+		w.mu.Lock()
+		batches := slices.Clone(w.batches)
+		changed := w.changed
+		w.mu.Unlock()
+
+		if len(batches) >= n {
+			return batches, nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return batches, ctx.Err()
+		}
+	}
+}
+
+// WaitForItems waits until at least n elements were successfully written
+// and returns them. It returns the elements written so far and the error of
+// ctx if ctx is done first.
+func (w *SpyWriter) WaitForItems(ctx context.Context, n int) ([]interface{}, error) {
+	for {
+		w.mu.Lock()
+		items := w.items()
+		changed := w.changed
+		w.mu.Unlock()
+
+		if len(items) >= n {
+			return items, nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return items, ctx.Err()
+		}
+	}
+}
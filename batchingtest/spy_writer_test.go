@@ -0,0 +1,129 @@
+package batchingtest_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+	"code.cloudfoundry.org/go-batching/batchingtest"
+)
+
+var _ = Describe("SpyWriter", func() {
+	var spy *batchingtest.SpyWriter
+
+	BeforeEach(func() {
+		spy = batchingtest.NewSpyWriter()
+	})
+
+	It("records the written batches", func() {
+		b := batching.NewBatcher(2, time.Hour, spy.Writer())
+
+		b.Write("a")
+		b.Write("b")
+		b.Write("c")
+		b.ForcedFlush()
+
+		Expect(spy.Batches()).To(Equal([][]interface{}{{"a", "b"}, {"c"}}))
+		Expect(spy.Items()).To(Equal([]interface{}{"a", "b", "c"}))
+		Expect(spy.Calls()).To(Equal(2))
+	})
+
+	It("records copies of the batches", func() {
+		batch := []interface{}{"a"}
+		Expect(spy.Write(context.Background(), batch)).To(Succeed())
+
+		batch[0] = "b"
+
+		Expect(spy.Items()).To(Equal([]interface{}{"a"}))
+	})
+
+	It("records byte slices", func() {
+		b := batching.NewByteBatcher(1, time.Hour, spy.ByteWriter())
+
+		b.Write([]byte("a"))
+
+		Expect(spy.Items()).To(Equal([]interface{}{[]byte("a")}))
+	})
+
+	It("fails writes with the configured error", func() {
+		err := errors.New("unavailable")
+		spy.SetError(err)
+		b := batching.NewFallibleBatcher(1, time.Hour, spy.FallibleWriter())
+
+		Expect(b.WriteContext(context.Background(), "a")).To(MatchError(err))
+
+		spy.SetError(nil)
+		Expect(b.WriteContext(context.Background(), "b")).To(Succeed())
+		Expect(spy.Items()).To(Equal([]interface{}{"b"}))
+		Expect(spy.Calls()).To(Equal(2))
+	})
+
+	It("fails the next writes with the queued errors", func() {
+		first, second := errors.New("first"), errors.New("second")
+		spy.FailNext(first, second)
+
+		Expect(spy.Write(context.Background(), []interface{}{"a"})).To(MatchError(first))
+		Expect(spy.Write(context.Background(), []interface{}{"b"})).To(MatchError(second))
+		Expect(spy.Write(context.Background(), []interface{}{"c"})).To(Succeed())
+		Expect(spy.Items()).To(Equal([]interface{}{"c"}))
+	})
+
+	It("takes the configured latency to write", func() {
+		spy.SetLatency(20 * time.Millisecond)
+
+		start := time.Now()
+		Expect(spy.Write(context.Background(), []interface{}{"a"})).To(Succeed())
+
+		Expect(time.Since(start)).To(BeNumerically(">=", 20*time.Millisecond))
+	})
+
+	It("stops waiting for the latency once ctx is done", func() {
+		spy.SetLatency(time.Hour)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		Expect(spy.Write(ctx, []interface{}{"a"})).To(MatchError(context.Canceled))
+		Expect(spy.Calls()).To(BeZero())
+	})
+
+	It("waits for batches written from other goroutines", func() {
+		b := batching.NewBatcher(10, time.Hour, spy.Writer(),
+			batching.WithMaxItemAge(time.Millisecond),
+		)
+
+		b.Write("a")
+		batches, err := spy.WaitForBatches(context.Background(), 1)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(batches).To(Equal([][]interface{}{{"a"}}))
+	})
+
+	It("waits for items", func() {
+		go func() {
+			defer GinkgoRecover()
+			for _, item := range []string{"a", "b", "c"} {
+				Expect(spy.Write(context.Background(), []interface{}{item})).To(Succeed())
+			}
+		}()
+
+		items, err := spy.WaitForItems(context.Background(), 3)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(items).To(Equal([]interface{}{"a", "b", "c"}))
+	})
+
+	It("stops waiting once ctx is done", func() {
+		Expect(spy.Write(context.Background(), []interface{}{"a"})).To(Succeed())
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		batches, err := spy.WaitForBatches(ctx, 2)
+
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Expect(batches).To(HaveLen(1))
+	})
+})
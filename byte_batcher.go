@@ -1,6 +1,9 @@
 package batching
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 // ByteBatcher batches slices of bytes.
 type ByteBatcher struct {
@@ -23,23 +26,59 @@ func (f ByteWriterFunc) Write(batch [][]byte) {
 }
 
 // NewByteBatcher creates a new ByteBatcher.
-func NewByteBatcher(size int, interval time.Duration, writer ByteWriter) *ByteBatcher {
+func NewByteBatcher(size int, interval time.Duration, writer ByteWriter, opts ...Option) *ByteBatcher {
 	genWriter := WriterFunc(func(batch []interface{}) {
-		byteBatch := make([][]byte, 0, len(batch))
-		for _, element := range batch {
-			byteBatch = append(byteBatch, element.([]byte))
-		}
-		writer.Write(byteBatch)
+		writer.Write(toByteBatch(batch))
 	})
 	return &ByteBatcher{
-		Batcher: NewBatcher(size, interval, genWriter),
+		Batcher: NewBatcher(size, interval, genWriter, append([]Option{WithSizeFunc(byteLen)}, opts...)...),
 	}
 }
 
+// NewByteBatcherWithByteLimit creates a new ByteBatcher that submits the
+// batch once adding to it would exceed maxBytes in total length, or the
+// interval has lapsed.
+func NewByteBatcherWithByteLimit(maxBytes int, interval time.Duration, writer ByteWriter, opts ...Option) *ByteBatcher {
+	return NewByteBatcher(math.MaxInt, interval, writer, append([]Option{WithMaxBytes(maxBytes)}, opts...)...)
+}
+
 // Write stores data to the batch. It will not submit the batch to the writer
 // until either the batch has been filled, or the interval has lapsed. NOTE:
-// Write is *not* thread safe and should be called by the same goroutine that
-// calls Flush.
+// Write is *not* thread safe unless the ByteBatcher was created WithLocking
+// and should otherwise be called by the same goroutine that calls Flush.
 func (b *ByteBatcher) Write(data []byte) {
 	b.Batcher.Write(data)
 }
+
+// WriteAll stores many slices to the batch at once. See Batcher.WriteAll.
+func (b *ByteBatcher) WriteAll(data ...[]byte) {
+	batch := make([]interface{}, 0, len(data))
+	for _, d := range data {
+		batch = append(batch, d)
+	}
+	b.Batcher.WriteAll(batch...)
+}
+
+// Peek returns a copy of the slices waiting to be written. The slices
+// themselves are not copied and must not be modified.
+func (b *ByteBatcher) Peek() [][]byte {
+	return toByteBatch(b.Batcher.Peek())
+}
+
+// Discard drops the slices waiting to be written without writing them and
+// returns them.
+func (b *ByteBatcher) Discard() [][]byte {
+	return toByteBatch(b.Batcher.Discard())
+}
+
+func toByteBatch(batch []interface{}) [][]byte {
+	byteBatch := make([][]byte, 0, len(batch))
+	for _, element := range batch {
+		byteBatch = append(byteBatch, element.([]byte))
+	}
+	return byteBatch
+}
+
+func byteLen(data interface{}) int {
+	return len(data.([]byte))
+}
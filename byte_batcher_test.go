@@ -19,6 +19,17 @@ var _ = Describe("ByteBatcher", func() {
 		Expect(writer.batch).To(HaveLen(1))
 		Expect(writer.batch[0]).To(Equal([]byte("item")))
 	})
+
+	It("honors a byte limit", func() {
+		writer := &spyByteWriter{}
+		b := batching.NewByteBatcherWithByteLimit(8, time.Minute, writer)
+
+		b.Write([]byte("item"))
+		b.Write([]byte("item"))
+
+		Expect(writer.called).To(Equal(1))
+		Expect(writer.batch).To(HaveLen(2))
+	})
 })
 
 type spyByteWriter struct {
@@ -0,0 +1,72 @@
+package batching
+
+// chunk is the prefix of the pending batch submitted in a single write,
+// together with its size and weight so that they never have to be read from
+// the elements again once the writer owns them.
+type chunk struct {
+	n      int
+	bytes  int
+	weight int
+}
+
+// nextChunk returns the pending elements to submit in a single write so that
+// no write exceeds the batch size or the byte limit. A chunk always holds at
+// least one element.
+func (b *Batcher) nextChunk() chunk {
+	if b.retained > 0 {
+		return b.prefix(b.retained)
+	}
+	if b.maxBytes <= 0 && b.weightFn == nil && (b.size <= 0 || len(b.batch) <= b.size || b.minFlushInterval > 0) {
+		return chunk{n: len(b.batch), bytes: b.pendingBytes, weight: b.pendingWeight}
+	}
+
+	// Batches held back by the minimum flush interval are written at once.
+	limit := b.size
+	if b.minFlushInterval > 0 {
+		limit = 0
+	}
+
+	var c chunk
+	for _, data := range b.batch {
+		if b.weightFn == nil && limit > 0 && c.n == limit {
+			break
+		}
+		size := b.sizeOf(data)
+		if b.maxBytes > 0 && c.n > 0 && c.bytes+size > b.maxBytes {
+			break
+		}
+
+		c.n++
+		c.bytes += size
+		if b.weightFn != nil {
+			c.weight += b.weightFn(data)
+			if limit > 0 && c.weight >= limit {
+				break
+			}
+		}
+	}
+	return c
+}
+
+// prefix returns the chunk of the first n pending elements.
+func (b *Batcher) prefix(n int) chunk {
+	c := chunk{n: n}
+	for _, data := range b.batch[:n] {
+		c.bytes += b.sizeOf(data)
+		if b.weightFn != nil {
+			c.weight += b.weightFn(data)
+		}
+	}
+	return c
+}
+
+// consume removes the chunk from the pending batch after it has been written.
+// The elements are not read or cleared as the writer may still reference or
+// have released them.
+func (b *Batcher) consume(c chunk) {
+	b.batch = b.batch[c.n:]
+	b.pendingBytes -= c.bytes
+	b.pendingWeight -= c.weight
+	b.failures = 0
+	b.retained = 0
+}
@@ -0,0 +1,128 @@
+package batching_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Oversized batches", func() {
+	var (
+		writer *recordingFallibleWriter
+		b      *batching.Batcher
+	)
+
+	BeforeEach(func() {
+		writer = &recordingFallibleWriter{errs: []error{errors.New("failed"), errors.New("failed")}}
+		b = batching.NewFallibleBatcher(2, time.Minute, writer,
+			batching.WithRetryPolicy(batching.RetainOnError(0)),
+		)
+
+		b.WriteAll("a", "b")
+		b.WriteAll("c", "d", "e")
+		Expect(b.Len()).To(Equal(5))
+		writer.batches = nil
+	})
+
+	It("writes the batch in chunks of at most the batch size", func() {
+		res := b.ForcedFlush()
+
+		Expect(res).To(Equal(batching.FlushResult{Written: true, Items: 5, Reason: batching.FlushForced}))
+		Expect(writer.batches).To(Equal([][]interface{}{{"a", "b"}, {"c", "d"}, {"e"}}))
+		Expect(b.Len()).To(Equal(0))
+	})
+
+	It("retains the remaining chunks if a chunk fails to write", func() {
+		writer.errs = []error{nil, errors.New("failed")}
+
+		b.ForcedFlush()
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"a", "b"}, {"c", "d"}}))
+		Expect(b.Peek()).To(Equal([]interface{}{"c", "d", "e"}))
+	})
+
+	It("writes the remaining chunks if a failed chunk is dropped", func() {
+		writer.errs = []error{errors.New("failed")}
+		b := batching.NewFallibleBatcher(2, time.Minute, writer,
+			batching.WithRetryPolicy(batching.RetryPolicyFunc(func(attempts int, _ error) bool {
+				return attempts < 2
+			})),
+		)
+		b.WriteAll("a", "b", "c")
+		writer.batches = nil
+
+		writer.errs = []error{errors.New("failed")}
+		b.ForcedFlush()
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"a", "b"}, {"c"}}))
+		Expect(b.Len()).To(Equal(0))
+	})
+
+	It("limits the capacity of each chunk", func() {
+		b.ForcedFlush()
+
+		Expect(cap(writer.batches[0])).To(Equal(2))
+	})
+
+	It("writes the batch in chunks of at most the byte limit", func() {
+		writer.errs = []error{errors.New("failed"), errors.New("failed"), errors.New("failed")}
+		b := batching.NewFallibleBatcher(100, time.Minute, writer,
+			batching.WithMaxBytes(10),
+			batching.WithSizeFunc(strLen),
+			batching.WithRetryPolicy(batching.RetainOnError(0)),
+		)
+		b.WriteAll("aaaaaa", "bbbbbb", "cccccc", "dddddd")
+		Expect(b.PendingBytes()).To(Equal(24))
+		writer.batches = nil
+
+		b.ForcedFlush()
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"aaaaaa"}, {"bbbbbb"}, {"cccccc"}, {"dddddd"}}))
+		Expect(b.PendingBytes()).To(Equal(0))
+	})
+
+	It("does not read a chunk after the writer released it", func() {
+		var b *batching.Batcher
+		var written []interface{}
+		fail := true
+		writer := batching.FallibleWriterFunc(func(batch []interface{}) error {
+			if fail {
+				return errors.New("failed")
+			}
+			written = append(written, batch...)
+			b.Release(batch)
+			return nil
+		})
+		b = batching.NewFallibleBatcher(4, time.Minute, writer,
+			batching.WithBatchPool(),
+			batching.WithWeightFunc(strLen),
+			batching.WithRetryPolicy(batching.RetainOnError(0)),
+		)
+		b.WriteAll("aa", "bb", "cc")
+		Expect(b.Len()).To(Equal(3))
+
+		fail = false
+		Expect(func() { b.ForcedFlush() }).NotTo(Panic())
+		Expect(written).To(Equal([]interface{}{"aa", "bb", "cc"}))
+		Expect(b.Len()).To(Equal(0))
+	})
+})
+
+type recordingFallibleWriter struct {
+	batches [][]interface{}
+	errs    []error
+}
+
+func (w *recordingFallibleWriter) Write(batch []interface{}) error {
+	w.batches = append(w.batches, batch)
+	if len(w.errs) == 0 {
+		return nil
+	}
+	err := w.errs[0]
+	w.errs = w.errs[1:]
+	return err
+}
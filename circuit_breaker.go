@@ -0,0 +1,124 @@
+package batching
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when a batch is not written because the circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("batching: circuit breaker is open")
+
+// BreakerPolicy decides what happens to a batch that is due to be written
+// while the circuit breaker is open.
+type BreakerPolicy int
+
+const (
+	// Buffer keeps the batch pending, so combine it with WithPendingLimit to
+	// bound the number of pending elements.
+	Buffer BreakerPolicy = iota
+
+	// Shed drops the batch and reports it to the drop callback.
+	Shed
+)
+
+// CircuitBreaker configures a circuit breaker around the writer.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failed writes that opens
+	// the circuit breaker.
+	FailureThreshold int
+
+	// Cooldown is how long the circuit breaker stays open before a single
+	// batch is written to probe whether the writer has recovered.
+	Cooldown time.Duration
+
+	// Policy decides what happens to batches while the circuit breaker is
+	// open.
+	Policy BreakerPolicy
+}
+
+// WithCircuitBreaker stops invoking the writer once it failed to write
+// cb.FailureThreshold batches in a row, so that a downstream that is down is
+// not hammered with writes. Once cb.Cooldown has passed the next batch is
+// written as a probe, which closes the circuit breaker if it succeeds and
+// opens it for another cooldown otherwise. Batches due while the circuit
+// breaker is open fail with ErrCircuitOpen and are kept or dropped according
+// to cb.Policy without applying the RetryPolicy. A Batcher created
+// WithWriterConcurrency hands batches off before they reach the circuit
+// breaker, so they are treated as failed writes of the pool instead.
+func WithCircuitBreaker(cb CircuitBreaker) Option {
+	return func(b *Batcher) {
+		b.circuitBreaker = &cb
+	}
+}
+
+// breakerWriter is a ContextWriter that stops writing to the underlying
+// ContextWriter while the circuit breaker is open.
+type breakerWriter struct {
+	w     ContextWriter
+	cb    CircuitBreaker
+	clock Clock
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func (b *Batcher) startCircuitBreaker() {
+	if b.circuitBreaker == nil {
+		return
+	}
+
+	cb := *b.circuitBreaker
+	cb.FailureThreshold = max(cb.FailureThreshold, 1)
+	b.w = &breakerWriter{w: b.w, cb: cb, clock: b.clock}
+}
+
+func (w *breakerWriter) Write(ctx context.Context, batch []interface{}) error {
+	if !w.allow() {
+		if w.cb.Policy == Shed {
+			return ErrCircuitOpen
+		}
+		return notSubmittedError{err: ErrCircuitOpen}
+	}
+
+	err := w.w.Write(ctx, batch)
+	w.record(err)
+	return err
+}
+
+// allow reports whether a batch may be written, which is the case while the
+// circuit breaker is closed or for a single probe once the cooldown passed.
+func (w *breakerWriter) allow() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.failures < w.cb.FailureThreshold {
+		return true
+	}
+	if w.probing || w.clock.Since(w.openedAt) < w.cb.Cooldown {
+		return false
+	}
+
+	w.probing = true
+	return true
+}
+
+func (w *breakerWriter) record(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.probing = false
+	if err == nil {
+		w.failures = 0
+		return
+	}
+
+	w.failures++
+	if w.failures >= w.cb.FailureThreshold {
+		w.openedAt = w.clock.Now()
+	}
+}
@@ -0,0 +1,107 @@
+package batching_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Circuit breaker", func() {
+	var (
+		clock    *fakeClock
+		writeErr error
+		writer   *recordingFallibleWriter
+		ctx      context.Context
+	)
+
+	newBatcher := func(policy batching.BreakerPolicy, opts ...batching.Option) *batching.Batcher {
+		return batching.NewFallibleBatcher(1, time.Minute, writer, append([]batching.Option{
+			batching.WithClock(clock),
+			batching.WithCircuitBreaker(batching.CircuitBreaker{
+				FailureThreshold: 2,
+				Cooldown:         time.Second,
+				Policy:           policy,
+			}),
+		}, opts...)...)
+	}
+
+	BeforeEach(func() {
+		clock = &fakeClock{now: time.Unix(0, 0)}
+		writeErr = errors.New("failed")
+		writer = &recordingFallibleWriter{errs: []error{writeErr, writeErr}}
+		ctx = context.Background()
+	})
+
+	It("stops writing once the writer failed too many times in a row", func() {
+		b := newBatcher(batching.Buffer)
+
+		b.Write("a")
+		b.Write("b")
+		b.Write("c")
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"a"}, {"b"}}))
+	})
+
+	It("keeps batches pending while open", func() {
+		b := newBatcher(batching.Buffer)
+		b.Write("a")
+		b.Write("b")
+
+		Expect(b.WriteContext(ctx, "c")).To(MatchError(batching.ErrCircuitOpen))
+		Expect(b.Peek()).To(Equal([]interface{}{"c"}))
+	})
+
+	It("drops batches while open", func() {
+		var dropped []interface{}
+		b := newBatcher(batching.Shed,
+			batching.WithRetryPolicy(batching.RetainOnError(0)),
+			batching.WithOnDrop(func(d []interface{}) {
+				dropped = append(dropped, d...)
+			}),
+		)
+		b.Write("a")
+		b.ForcedFlush()
+		Expect(b.Peek()).To(Equal([]interface{}{"a"}))
+
+		Expect(b.WriteContext(ctx, "b")).To(MatchError(batching.ErrCircuitOpen))
+		Expect(dropped).To(Equal([]interface{}{"a", "b"}))
+		Expect(b.Len()).To(Equal(0))
+	})
+
+	It("closes once a probe after the cooldown succeeds", func() {
+		b := newBatcher(batching.Buffer)
+		b.Write("a")
+		b.Write("b")
+		b.Write("c")
+
+		clock.Advance(time.Second)
+		b.ForcedFlush()
+		b.Write("d")
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"a"}, {"b"}, {"c"}, {"d"}}))
+	})
+
+	It("opens for another cooldown if a probe fails", func() {
+		writer.errs = append(writer.errs, writeErr)
+		b := newBatcher(batching.Buffer)
+		b.Write("a")
+		b.Write("b")
+
+		clock.Advance(time.Second)
+		b.Write("c")
+		Expect(writer.batches).To(HaveLen(3))
+
+		clock.Advance(time.Second / 2)
+		b.Write("d")
+		Expect(writer.batches).To(HaveLen(3))
+
+		clock.Advance(time.Second / 2)
+		b.ForcedFlush()
+		Expect(writer.batches).To(HaveLen(4))
+	})
+})
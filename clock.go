@@ -0,0 +1,37 @@
+package batching
+
+import "time"
+
+// Clock is the source of time used by a Batcher to decide when the interval
+// has lapsed.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// Since returns the time elapsed since t.
+	Since(t time.Time) time.Duration
+}
+
+// WithClock sets the Clock used by the Batcher. It defaults to the system
+// clock. This is mostly useful for testing interval based writes. Every
+// delay, including the timers of WithMaxItemAge, StartAutoFlush and
+// WithBackoff, is measured with the Clock, and scheduled with it if it is a
+// TimerClock. The system clock only uses the time package, so batchers
+// created within a testing/synctest bubble run on its fake time. Group,
+// AggregatingBatcher, RingBatcher, RoundRobinWriter and RotatingFile are not
+// configured with Options and take their Clock from SetClock instead.
+func WithClock(c Clock) Option {
+	return func(b *Batcher) {
+		b.clock = c
+	}
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
@@ -0,0 +1,47 @@
+package batching_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Clock", func() {
+	It("uses the clock to decide when the interval has lapsed", func() {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		writer := &spyWriter{}
+		b := batching.NewBatcher(10, time.Minute, writer, batching.WithClock(clock))
+
+		b.Write("item")
+		b.Flush()
+		Expect(writer.called).To(Equal(0))
+
+		clock.Advance(time.Minute)
+		b.Flush()
+		Expect(writer.called).To(Equal(1))
+
+		b.Write("other-item")
+		clock.Advance(59 * time.Second)
+		b.Flush()
+		Expect(writer.called).To(Equal(1))
+	})
+})
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Since(t time.Time) time.Duration {
+	return c.now.Sub(t)
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,64 @@
+package batching
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrClosed is returned when using a Batcher that has been closed.
+var ErrClosed = errors.New("batching: batcher is closed")
+
+// Close writes any pending data to the writer and closes the Batcher. Once
+// closed, Write drops data and the methods that return an error return
+// ErrClosed. Calling Close more than once returns ErrClosed.
+func (b *Batcher) Close() error {
+	return b.CloseWithContext(context.Background())
+}
+
+// CloseWithContext is like Close but passes ctx to the writer. The Batcher is
+// closed even if the final write fails, in which case the pending data is
+// dropped and the error is returned.
+func (b *Batcher) CloseWithContext(ctx context.Context) error {
+	_, err := b.Drain(ctx)
+	return err
+}
+
+// Drain closes the Batcher like CloseWithContext and reports how many of the
+// pending elements could not be delivered, because the writer failed to
+// write them or ctx expired before they were written. Batches that a Batcher
+// created WithWriterConcurrency failed to write after handing them off are
+// given up but not counted. Drain waits for the batches the pool is still
+// writing until ctx expires.
+func (b *Batcher) Drain(ctx context.Context) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return 0, ErrClosed
+	}
+
+	pending := len(b.batch)
+	flushed := b.stats.ItemsFlushed + b.stats.ItemsDeduplicated
+	_, err := b.writeBatch(ctx, FlushForced)
+	undelivered := pending - int(b.stats.ItemsFlushed+b.stats.ItemsDeduplicated-flushed)
+
+	b.closed = true
+	b.lost(b.batch, DropClosed)
+	b.reset()
+	if b.memory != nil {
+		b.memory.unregister(b.memoryMember)
+	}
+	for _, stop := range b.stopAutoFlush {
+		stop()
+	}
+	if b.pool != nil {
+		if !b.pool.close(ctx) && err == nil {
+			err = ctx.Err()
+		}
+		for _, batch := range b.pool.takeFailed() {
+			b.giveUp(batch)
+		}
+	}
+
+	return undelivered, err
+}
@@ -0,0 +1,67 @@
+package batching_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Close", func() {
+	It("writes the pending data", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(2, time.Minute, writer)
+
+		b.Write("item")
+		Expect(b.Close()).To(Succeed())
+
+		Expect(writer.batch).To(Equal([]interface{}{"item"}))
+	})
+
+	It("rejects use after it is closed", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(1, time.Nanosecond, writer)
+		Expect(b.Close()).To(Succeed())
+
+		b.Write("item")
+		b.Flush()
+		b.ForcedFlush()
+		Expect(writer.called).To(Equal(0))
+
+		ctx := context.Background()
+		Expect(b.WriteContext(ctx, "item")).To(MatchError(batching.ErrClosed))
+		_, err := b.FlushContext(ctx)
+		Expect(err).To(MatchError(batching.ErrClosed))
+		_, err = b.ForcedFlushContext(ctx)
+		Expect(err).To(MatchError(batching.ErrClosed))
+		Expect(b.Close()).To(MatchError(batching.ErrClosed))
+	})
+
+	It("closes even if the final write fails", func() {
+		writeErr := errors.New("failed")
+		writer := &spyFallibleWriter{err: writeErr}
+		b := batching.NewFallibleBatcher(2, time.Minute, writer,
+			batching.WithRetryPolicy(batching.RetainOnError(0)),
+		)
+
+		b.Write("item")
+		Expect(b.Close()).To(MatchError(writeErr))
+		Expect(b.WriteContext(context.Background(), "item")).To(MatchError(batching.ErrClosed))
+	})
+
+	It("passes the context to the writer", func() {
+		writer := &spyContextWriter{}
+		b := batching.NewContextBatcher(2, time.Minute, writer)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		b.Write("item")
+
+		Expect(b.CloseWithContext(ctx)).To(MatchError(context.Canceled))
+		Expect(writer.called).To(Equal(0))
+	})
+})
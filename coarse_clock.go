@@ -0,0 +1,67 @@
+package batching
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CoarseClock is a Clock that caches the current time and updates it in the
+// background once per resolution, so that reading the time on every Write
+// does not cost a call into the system clock. Batchers using it decide that
+// the interval has lapsed up to one resolution late, so the resolution
+// should be small compared to the interval. A CoarseClock can be shared by
+// many batchers and must be stopped once none of them use it anymore.
+type CoarseClock struct {
+	now     atomic.Int64
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// NewCoarseClock returns a CoarseClock that is updated once per resolution.
+func NewCoarseClock(resolution time.Duration) *CoarseClock {
+	c := &CoarseClock{
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	c.now.Store(time.Now().UnixNano())
+	go c.run(resolution)
+
+	return c
+}
+
+// Now returns the cached current time.
+func (c *CoarseClock) Now() time.Time {
+	return time.Unix(0, c.now.Load())
+}
+
+// Since returns the time elapsed since t according to the cached current
+// time.
+func (c *CoarseClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Stop stops updating the cached time, which no longer changes once Stop
+// returns. Calling Stop more than once is a NOP.
+func (c *CoarseClock) Stop() {
+	c.once.Do(func() {
+		close(c.done)
+	})
+	<-c.stopped
+}
+
+func (c *CoarseClock) run(resolution time.Duration) {
+	defer close(c.stopped)
+	t := time.NewTicker(resolution)
+	defer t.Stop()
+
+	for {
+		select {
+		case now := <-t.C:
+			c.now.Store(now.UnixNano())
+		case <-c.done:
+			return
+		}
+	}
+}
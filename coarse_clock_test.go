@@ -0,0 +1,48 @@
+package batching_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("CoarseClock", func() {
+	var clock *batching.CoarseClock
+
+	BeforeEach(func() {
+		clock = batching.NewCoarseClock(time.Millisecond)
+		DeferCleanup(clock.Stop)
+	})
+
+	It("caches the current time", func() {
+		Expect(clock.Now()).To(BeTemporally("~", time.Now(), 10*time.Millisecond))
+	})
+
+	It("updates the cached time once per resolution", func() {
+		start := clock.Now()
+
+		Eventually(clock.Since).WithArguments(start).Should(BeNumerically(">", 0))
+	})
+
+	It("stops updating the cached time when stopped", func() {
+		clock.Stop()
+		clock.Stop()
+		stopped := clock.Now()
+
+		Consistently(clock.Now, 20*time.Millisecond).Should(Equal(stopped))
+	})
+
+	It("can be used by a Batcher", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(10, 10*time.Millisecond, writer, batching.WithClock(clock))
+
+		b.Write("item")
+		Eventually(func() int {
+			b.Flush()
+			return writer.called
+		}).Should(Equal(1))
+	})
+})
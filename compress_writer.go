@@ -0,0 +1,62 @@
+package batching
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"sync"
+)
+
+// CompressWriter is an io.Writer that gzips everything written to it with a
+// single call to Write as a complete gzip stream and forwards the stream to
+// the underlying writer with a single call to Write. Used as the io.Writer
+// of an encoding writer, every serialized batch is therefore compressed on
+// its own. The compressors and buffers are reused. CompressWriter should be
+// created with NewCompressWriter().
+type CompressWriter struct {
+	w     io.Writer
+	level int
+	gzips sync.Pool
+	bufs  sync.Pool
+}
+
+// NewCompressWriter creates a new CompressWriter that compresses with the
+// given level, such as gzip.BestSpeed or gzip.DefaultCompression. It returns
+// an error if the level is invalid.
+func NewCompressWriter(w io.Writer, level int) (*CompressWriter, error) {
+	if _, err := gzip.NewWriterLevel(io.Discard, level); err != nil {
+		return nil, err
+	}
+	return &CompressWriter{w: w, level: level}, nil
+}
+
+// Write implements io.Writer.
+func (c *CompressWriter) Write(p []byte) (int, error) {
+	buf, ok := c.bufs.Get().(*bytes.Buffer)
+	if !ok {
+		buf = &bytes.Buffer{}
+	}
+	defer func() {
+		buf.Reset()
+		c.bufs.Put(buf)
+	}()
+
+	zw, ok := c.gzips.Get().(*gzip.Writer)
+	if ok {
+		zw.Reset(buf)
+	} else {
+		zw, _ = gzip.NewWriterLevel(buf, c.level)
+	}
+	defer c.gzips.Put(zw)
+
+	if _, err := zw.Write(p); err != nil {
+		return 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	if _, err := c.w.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
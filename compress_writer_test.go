@@ -0,0 +1,65 @@
+package batching_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("CompressWriter", func() {
+	It("compresses every batch on its own", func() {
+		sink := &countingIOWriter{}
+		cw, err := batching.NewCompressWriter(sink, gzip.BestSpeed)
+		Expect(err).ToNot(HaveOccurred())
+		b := batching.NewContextBatcher(2, time.Minute, batching.NewJSONLinesWriter(cw))
+
+		b.Write("item")
+		b.Write("other-item")
+		b.Write("next-item")
+		b.ForcedFlush()
+
+		Expect(sink.writes).To(HaveLen(2))
+		Expect(gunzip(sink.writes[0])).To(Equal("\"item\"\n\"other-item\"\n"))
+		Expect(gunzip(sink.writes[1])).To(Equal("\"next-item\"\n"))
+	})
+
+	It("reports the length of the uncompressed data", func() {
+		cw, err := batching.NewCompressWriter(io.Discard, gzip.DefaultCompression)
+		Expect(err).ToNot(HaveOccurred())
+
+		n, err := cw.Write([]byte("item"))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(4))
+	})
+
+	It("rejects invalid compression levels", func() {
+		_, err := batching.NewCompressWriter(io.Discard, 42)
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns the error of the underlying writer", func() {
+		cw, err := batching.NewCompressWriter(&countingIOWriter{err: errors.New("write failed")}, gzip.BestSpeed)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = cw.Write([]byte("item"))
+
+		Expect(err).To(MatchError("write failed"))
+	})
+})
+
+func gunzip(data string) string {
+	zr, err := gzip.NewReader(bytes.NewReader([]byte(data)))
+	Expect(err).ToNot(HaveOccurred())
+	out, err := io.ReadAll(zr)
+	Expect(err).ToNot(HaveOccurred())
+	return string(out)
+}
@@ -0,0 +1,18 @@
+package batching
+
+import "time"
+
+// ConcurrentBatcher is a Batcher whose Write, Flush and ForcedFlush methods
+// are safe to call from multiple goroutines. The Writer is invoked while the
+// batcher is locked, so it must not call back into the batcher.
+// ConcurrentBatcher should be created with NewConcurrentBatcher().
+type ConcurrentBatcher struct {
+	*Batcher
+}
+
+// NewConcurrentBatcher creates a new ConcurrentBatcher.
+func NewConcurrentBatcher(size int, interval time.Duration, writer Writer, opts ...Option) *ConcurrentBatcher {
+	return &ConcurrentBatcher{
+		Batcher: NewBatcher(size, interval, writer, append(opts, WithLocking())...),
+	}
+}
@@ -0,0 +1,51 @@
+package batching_test
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("ConcurrentBatcher", func() {
+	It("can be written to and flushed from multiple goroutines", func() {
+		writer := &countingWriter{}
+		b := batching.NewConcurrentBatcher(10, time.Nanosecond, writer)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					b.Write(j)
+					b.Flush()
+				}
+			}()
+		}
+		wg.Wait()
+		b.ForcedFlush()
+
+		Expect(writer.items()).To(Equal(1000))
+	})
+})
+
+type countingWriter struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (w *countingWriter) Write(batch []interface{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.count += len(batch)
+}
+
+func (w *countingWriter) items() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.count
+}
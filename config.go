@@ -0,0 +1,201 @@
+package batching
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the settings of a Batcher that are usually configured by
+// operators rather than in code. Its fields are tagged for envstruct, so it
+// can be embedded in the configuration of a component, or loaded on its own
+// with LoadConfig.
+//
+// A Config always describes all of these settings, so applying it with
+// ApplyConfig replaces them entirely: a zero MaxBytes removes the byte limit
+// and a RetryMaxAttempts of one or less removes the retries, even if they
+// were set in code with WithMaxBytes, NewByteBatcherWithByteLimit or
+// WithBackoff.
+type Config struct {
+	// Size is the batch size. Zero means there is no limit on the number
+	// of elements, in which case MaxBytes must be set.
+	Size int `env:"BATCH_SIZE, report"`
+
+	// Interval is the flush interval.
+	Interval time.Duration `env:"BATCH_INTERVAL, report"`
+
+	// MaxBytes is the byte limit of a batch. Zero means no limit. Batchers
+	// that are not ByteBatchers also need a size function, see
+	// WithSizeFunc.
+	MaxBytes int `env:"BATCH_MAX_BYTES, report"`
+
+	// RetryMaxAttempts is the maximum number of writes of a batch the
+	// writer fails to write, including the first one. Zero or one means
+	// failed batches are not retried.
+	RetryMaxAttempts int `env:"BATCH_RETRY_MAX_ATTEMPTS, report"`
+
+	// RetryInitialInterval is the delay before the first retry. Zero means
+	// the default of Backoff.InitialInterval.
+	RetryInitialInterval time.Duration `env:"BATCH_RETRY_INITIAL_INTERVAL, report"`
+
+	// RetryMaxInterval caps the delay between retries. Zero means no cap.
+	RetryMaxInterval time.Duration `env:"BATCH_RETRY_MAX_INTERVAL, report"`
+}
+
+// Validate reports whether c describes a usable Batcher.
+func (c Config) Validate() error {
+	switch {
+	case c.Size < 0 || c.MaxBytes < 0 || c.RetryMaxAttempts < 0:
+		return errors.New("batching: size, max bytes and retry attempts must not be negative")
+	case c.Size == 0 && c.MaxBytes == 0:
+		return errors.New("batching: size or max bytes must be set")
+	case c.Interval <= 0:
+		return errors.New("batching: interval must be positive")
+	case c.RetryInitialInterval < 0 || c.RetryMaxInterval < 0:
+		return errors.New("batching: retry intervals must not be negative")
+	}
+	return nil
+}
+
+// Options returns the Options that configure a Batcher according to c.
+// Failed batches are retried WithBackoff if RetryMaxAttempts is greater
+// than one.
+func (c Config) Options() []Option {
+	opts := []Option{WithSize(c.size()), WithInterval(c.Interval)}
+	if c.MaxBytes > 0 {
+		opts = append(opts, WithMaxBytes(c.MaxBytes))
+	}
+	if backoff := c.backoff(); backoff != nil {
+		opts = append(opts, WithBackoff(*backoff))
+	}
+	return opts
+}
+
+func (c Config) size() int {
+	if c.Size == 0 {
+		return math.MaxInt
+	}
+	return c.Size
+}
+
+func (c Config) backoff() *Backoff {
+	if c.RetryMaxAttempts <= 1 {
+		return nil
+	}
+	return &Backoff{
+		InitialInterval: c.RetryInitialInterval,
+		MaxInterval:     c.RetryMaxInterval,
+		MaxAttempts:     c.RetryMaxAttempts,
+	}
+}
+
+// NewBatcherFromConfig creates a new Batcher that submits batches to a
+// ContextWriter, configured by cfg. opts are applied after the Options of
+// cfg. It returns an error if cfg is not valid.
+func NewBatcherFromConfig(cfg Config, writer ContextWriter, opts ...Option) (*Batcher, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	opts = append(cfg.Options(), opts...)
+	return newBatcher(cfg.Size, cfg.Interval, writer, opts), nil
+}
+
+// ApplyConfig reconfigures the Batcher with cfg without losing pending data,
+// so that it can be retuned while it is in use. The size, interval, byte
+// limit and retries are replaced together while the Batcher is locked,
+// overwriting the ones set with Options, see Config. A changed interval restarts the
+// current interval, and if the pending batch is full under the new limits
+// it is written straight away, returning the error from the write. Batches
+// already being written keep the retries they started with. It returns an
+// error without changing anything if cfg is not valid.
+//
+// ApplyConfig may only be called while other goroutines use the Batcher if
+// it was created WithLocking or StartAutoFlush was called. StartAutoFlush
+// and Run wait for the batch to be due with the new configuration.
+func (b *Batcher) ApplyConfig(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+
+	b.size = cfg.size()
+	b.maxBytes = cfg.MaxBytes
+	b.backoff = cfg.backoff()
+	b.retry.Store(b.backoff)
+	if cfg.Interval != b.interval {
+		b.interval = cfg.Interval
+		b.restartInterval()
+	}
+	b.notifyReconfigured()
+
+	if reason, ok := b.trigger(); ok {
+		_, err := b.writeBatch(context.Background(), reason)
+		return err
+	}
+	return nil
+}
+
+// reconfiguredChan returns a channel that is closed the next time the
+// Batcher is reconfigured, so that goroutines waiting for the batch to be
+// due can wait again with the new configuration.
+func (b *Batcher) reconfiguredChan() <-chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.reconfigured == nil {
+		b.reconfigured = make(chan struct{})
+	}
+	return b.reconfigured
+}
+
+func (b *Batcher) notifyReconfigured() {
+	if b.reconfigured != nil {
+		close(b.reconfigured)
+		b.reconfigured = nil
+	}
+}
+
+// LoadConfig overrides the fields of cfg with the environment variables
+// named by their env tags, such as BATCH_SIZE. Fields whose variable is not
+// set keep their value, so cfg should hold the defaults. Durations are
+// parsed with time.ParseDuration.
+func LoadConfig(cfg *Config) error {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("env"), ",")
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		field := v.Field(i)
+		switch field.Interface().(type) {
+		case time.Duration:
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("batching: invalid %s: %w", name, err)
+			}
+			field.SetInt(int64(d))
+		case int:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("batching: invalid %s: %w", name, err)
+			}
+			field.SetInt(int64(n))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+package batching_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+	"code.cloudfoundry.org/go-batching/batchingtest"
+)
+
+var _ = Describe("Config", func() {
+	valid := batching.Config{Size: 2, Interval: time.Second}
+
+	setenv := func(name, value string) {
+		Expect(os.Setenv(name, value)).To(Succeed())
+		DeferCleanup(os.Unsetenv, name)
+	}
+
+	It("creates a Batcher with the size and interval", func() {
+		writer := &recordingWriter{}
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		b, err := batching.NewBatcherFromConfig(valid, contextWriter(writer), batching.WithClock(clock))
+		Expect(err).ToNot(HaveOccurred())
+
+		b.Write("a")
+		b.Write("b")
+		b.Write("c")
+		Expect(writer.batches).To(Equal([][]interface{}{{"a", "b"}}))
+
+		clock.Advance(time.Second)
+		b.Flush()
+		Expect(writer.batches).To(Equal([][]interface{}{{"a", "b"}, {"c"}}))
+	})
+
+	It("limits the bytes of a batch", func() {
+		writer := &recordingWriter{}
+		cfg := batching.Config{MaxBytes: 4, Interval: time.Second}
+		b, err := batching.NewBatcherFromConfig(cfg, contextWriter(writer), batching.WithSizeFunc(strLen))
+		Expect(err).ToNot(HaveOccurred())
+
+		b.Write("ab")
+		b.Write("cd")
+		b.Write("e")
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"ab", "cd"}}))
+	})
+
+	It("retries failed writes", func() {
+		var attempts int
+		writer := batching.ContextWriterFunc(func(context.Context, []interface{}) error {
+			attempts++
+			return errors.New("failed")
+		})
+		cfg := valid
+		cfg.Size = 1
+		cfg.RetryMaxAttempts = 3
+		cfg.RetryInitialInterval = time.Millisecond
+		b, err := batching.NewBatcherFromConfig(cfg, writer)
+		Expect(err).ToNot(HaveOccurred())
+
+		b.Write("a")
+
+		Expect(attempts).To(Equal(3))
+	})
+
+	It("applies options after the config", func() {
+		writer := &recordingWriter{}
+		b, err := batching.NewBatcherFromConfig(valid, contextWriter(writer), batching.WithSize(1))
+		Expect(err).ToNot(HaveOccurred())
+
+		b.Write("a")
+
+		Expect(writer.batches).To(HaveLen(1))
+	})
+
+	DescribeTable("rejects invalid configuration",
+		func(cfg batching.Config) {
+			_, err := batching.NewBatcherFromConfig(cfg, contextWriter(&recordingWriter{}))
+
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("no size or max bytes", batching.Config{Interval: time.Second}),
+		Entry("negative size", batching.Config{Size: -1, Interval: time.Second}),
+		Entry("no interval", batching.Config{Size: 1}),
+		Entry("negative retry interval", batching.Config{Size: 1, Interval: time.Second, RetryInitialInterval: -1}),
+	)
+
+	Describe("LoadConfig", func() {
+		It("overrides the fields from the environment", func() {
+			setenv("BATCH_SIZE", "100")
+			setenv("BATCH_INTERVAL", "250ms")
+			setenv("BATCH_RETRY_MAX_ATTEMPTS", "5")
+			cfg := batching.Config{Size: 1, Interval: time.Second, MaxBytes: 1024}
+
+			Expect(batching.LoadConfig(&cfg)).To(Succeed())
+
+			Expect(cfg).To(Equal(batching.Config{
+				Size:             100,
+				Interval:         250 * time.Millisecond,
+				MaxBytes:         1024,
+				RetryMaxAttempts: 5,
+			}))
+		})
+
+		It("returns an error for invalid values", func() {
+			setenv("BATCH_INTERVAL", "soon")
+			var cfg batching.Config
+
+			Expect(batching.LoadConfig(&cfg)).To(MatchError(ContainSubstring("BATCH_INTERVAL")))
+		})
+	})
+
+	Describe("ApplyConfig", func() {
+		var (
+			clock  *fakeClock
+			writer *recordingWriter
+			b      *batching.Batcher
+		)
+
+		BeforeEach(func() {
+			clock = &fakeClock{now: time.Unix(0, 0)}
+			writer = &recordingWriter{}
+			var err error
+			b, err = batching.NewBatcherFromConfig(batching.Config{Size: 10, Interval: time.Second}, contextWriter(writer),
+				batching.WithClock(clock),
+			)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("writes the pending batch if it is full under the new size", func() {
+			for i := 0; i < 5; i++ {
+				b.Write(i)
+			}
+
+			Expect(b.ApplyConfig(batching.Config{Size: 2, Interval: time.Second})).To(Succeed())
+
+			Expect(writer.batches).To(Equal([][]interface{}{{0, 1}, {2, 3}, {4}}))
+		})
+
+		It("keeps the pending data when the size grows", func() {
+			for i := 0; i < 5; i++ {
+				b.Write(i)
+			}
+
+			Expect(b.ApplyConfig(batching.Config{Size: 20, Interval: time.Second})).To(Succeed())
+			for i := 5; i < 19; i++ {
+				b.Write(i)
+			}
+			Expect(writer.batches).To(BeEmpty())
+
+			b.Write(19)
+			Expect(writer.batches).To(HaveLen(1))
+			Expect(writer.batches[0]).To(HaveLen(20))
+		})
+
+		It("restarts the interval when it changes", func() {
+			b.Write("a")
+			clock.Advance(900 * time.Millisecond)
+
+			Expect(b.ApplyConfig(batching.Config{Size: 10, Interval: 500 * time.Millisecond})).To(Succeed())
+			clock.Advance(400 * time.Millisecond)
+			Expect(b.Flush().Written).To(BeFalse())
+
+			clock.Advance(100 * time.Millisecond)
+			Expect(b.Flush().Written).To(BeTrue())
+		})
+
+		It("does not restart an unchanged interval", func() {
+			b.Write("a")
+			clock.Advance(900 * time.Millisecond)
+
+			Expect(b.ApplyConfig(batching.Config{Size: 20, Interval: time.Second})).To(Succeed())
+			clock.Advance(100 * time.Millisecond)
+
+			Expect(b.Flush().Written).To(BeTrue())
+		})
+
+		It("replaces the retries", func() {
+			spy := batchingtest.NewSpyWriter()
+			b, err := batching.NewBatcherFromConfig(batching.Config{Size: 1, Interval: time.Second}, spy)
+			Expect(err).ToNot(HaveOccurred())
+
+			spy.FailNext(errors.New("failed"))
+			Expect(b.WriteContext(context.Background(), "a")).To(HaveOccurred())
+
+			Expect(b.ApplyConfig(batching.Config{
+				Size:                 1,
+				Interval:             time.Second,
+				RetryMaxAttempts:     2,
+				RetryInitialInterval: time.Millisecond,
+			})).To(Succeed())
+			spy.FailNext(errors.New("failed"))
+			Expect(b.WriteContext(context.Background(), "b")).To(Succeed())
+
+			Expect(b.ApplyConfig(batching.Config{Size: 1, Interval: time.Second})).To(Succeed())
+			spy.FailNext(errors.New("failed"))
+			Expect(b.WriteContext(context.Background(), "c")).To(HaveOccurred())
+			Expect(spy.Items()).To(Equal([]interface{}{"b"}))
+		})
+
+		It("overwrites the retries and byte limit set in code", func() {
+			spy := batchingtest.NewSpyWriter()
+			b := batching.NewContextBatcher(1, time.Second, spy,
+				batching.WithBackoff(batching.Backoff{InitialInterval: time.Millisecond}),
+			)
+			Expect(b.ApplyConfig(batching.Config{Size: 1, Interval: time.Second})).To(Succeed())
+
+			spy.FailNext(errors.New("failed"))
+			Expect(b.WriteContext(context.Background(), "a")).To(HaveOccurred())
+			Expect(spy.Calls()).To(Equal(1))
+
+			bytes := &spyByteWriter{}
+			bb := batching.NewByteBatcherWithByteLimit(4, time.Hour, bytes)
+			Expect(bb.ApplyConfig(batching.Config{Size: 10, Interval: time.Hour})).To(Succeed())
+
+			bb.WriteAll([]byte("aaa"), []byte("bbb"))
+			Expect(bytes.called).To(Equal(0))
+		})
+
+		It("does not change anything if the config is not valid", func() {
+			b.Write("a")
+
+			Expect(b.ApplyConfig(batching.Config{Size: 1})).To(HaveOccurred())
+
+			Expect(writer.batches).To(BeEmpty())
+			Expect(b.Peek()).To(Equal([]interface{}{"a"}))
+		})
+
+		It("returns an error once the Batcher is closed", func() {
+			Expect(b.Close()).To(Succeed())
+
+			Expect(b.ApplyConfig(batching.Config{Size: 1, Interval: time.Second})).To(MatchError(batching.ErrClosed))
+		})
+
+		It("wakes up StartAutoFlush", func() {
+			spy := batchingtest.NewSpyWriter()
+			b := batching.NewContextBatcher(10, time.Hour, spy)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			b.StartAutoFlush(ctx)
+
+			b.Write("a")
+			Expect(b.ApplyConfig(batching.Config{Size: 10, Interval: 10 * time.Millisecond})).To(Succeed())
+
+			Eventually(spy.Items).Should(Equal([]interface{}{"a"}))
+		})
+
+		It("wakes up Run", func() {
+			spy := batchingtest.NewSpyWriter()
+			b := batching.NewContextBatcher(10, time.Hour, spy, batching.WithLocking())
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			in := make(chan interface{})
+			go func() {
+				_ = b.Run(ctx, in)
+			}()
+
+			in <- "a"
+			Expect(b.ApplyConfig(batching.Config{Size: 10, Interval: 10 * time.Millisecond})).To(Succeed())
+
+			Eventually(spy.Items).Should(Equal([]interface{}{"a"}))
+		})
+
+		It("does not lose data written concurrently", func() {
+			spy := batchingtest.NewSpyWriter()
+			b := batching.NewContextBatcher(10, time.Hour, spy, batching.WithLocking())
+
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					b.Write(i)
+				}
+			}()
+			for size := 1; size <= 20; size++ {
+				Expect(b.ApplyConfig(batching.Config{Size: size, Interval: time.Hour})).To(Succeed())
+			}
+			wg.Wait()
+			b.ForcedFlush()
+
+			Expect(spy.Items()).To(HaveLen(1000))
+		})
+	})
+})
+
+func contextWriter(w batching.Writer) batching.ContextWriter {
+	return batching.ContextWriterFunc(func(_ context.Context, batch []interface{}) error {
+		w.Write(batch)
+		return nil
+	})
+}
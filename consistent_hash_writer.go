@@ -0,0 +1,127 @@
+package batching
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// virtualNodes is the number of points each writer has on the hash ring,
+// which evens out the share of keys routed to each writer.
+const virtualNodes = 128
+
+// ConsistentHashWriter is a ContextWriter that routes every batch to one of
+// several named writers, such as a pool of downstream connections, by
+// consistent hashing on the key of the batch. All batches with the same key
+// go to the same writer, which preserves their order, and adding or removing
+// a writer only moves the keys of that writer. Combined with a KeyedBatcher,
+// whose batches only hold data of a single key, this routes every key to a
+// fixed connection. The methods of a ConsistentHashWriter are safe to call
+// from multiple goroutines. ConsistentHashWriter should be created with
+// NewConsistentHashWriter().
+type ConsistentHashWriter struct {
+	keyFn func(batch []interface{}) string
+
+	mu      sync.RWMutex
+	writers map[string]ContextWriter
+	ring    []ringPoint
+}
+
+type ringPoint struct {
+	hash uint64
+	name string
+}
+
+// NewConsistentHashWriter creates a new ConsistentHashWriter that routes
+// batches by the key returned by keyFn to writers, which are keyed by name.
+func NewConsistentHashWriter(keyFn func(batch []interface{}) string, writers map[string]ContextWriter) *ConsistentHashWriter {
+	c := &ConsistentHashWriter{
+		keyFn:   keyFn,
+		writers: make(map[string]ContextWriter, len(writers)),
+	}
+	for name, w := range writers {
+		c.writers[name] = w
+	}
+	c.rebuild()
+
+	return c
+}
+
+// Write writes the batch to the writer its key is routed to.
+func (c *ConsistentHashWriter) Write(ctx context.Context, batch []interface{}) error {
+	w := c.Route(c.keyFn(batch))
+	if w == nil {
+		return errors.New("batching: no writers")
+	}
+	return w.Write(ctx, batch)
+}
+
+// Route returns the writer key is routed to, or nil if there are no writers.
+func (c *ConsistentHashWriter) Route(key string) ContextWriter {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if len(c.ring) == 0 {
+		return nil
+	}
+	h := hashString(key)
+	i, _ := slices.BinarySearchFunc(c.ring, h, func(p ringPoint, h uint64) int {
+		return cmp.Compare(p.hash, h)
+	})
+	if i == len(c.ring) {
+		i = 0
+	}
+	return c.writers[c.ring[i].name]
+}
+
+// Add adds or replaces the writer with the given name.
+func (c *ConsistentHashWriter) Add(name string, w ContextWriter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.writers[name] = w
+	c.rebuild()
+}
+
+// Remove removes the writer with the given name, routing its keys to the
+// remaining writers.
+func (c *ConsistentHashWriter) Remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.writers, name)
+	c.rebuild()
+}
+
+func (c *ConsistentHashWriter) rebuild() {
+	c.ring = c.ring[:0]
+	for name := range c.writers {
+		for i := 0; i < virtualNodes; i++ {
+			c.ring = append(c.ring, ringPoint{
+				hash: hashString(name + "#" + strconv.Itoa(i)),
+				name: name,
+			})
+		}
+	}
+	slices.SortFunc(c.ring, func(a, b ringPoint) int {
+		return cmp.Compare(a.hash, b.hash)
+	})
+}
+
+// hashString hashes s with FNV-1a followed by the SplitMix64 finalizer, since
+// FNV-1a alone spreads similar strings such as the virtual node names poorly.
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,95 @@
+package batching_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("ConsistentHashWriter", func() {
+	var (
+		written map[string][]interface{}
+		writers map[string]batching.ContextWriter
+	)
+
+	named := func(name string) batching.ContextWriter {
+		return batching.ContextWriterFunc(func(_ context.Context, batch []interface{}) error {
+			written[name] = append(written[name], batch...)
+			return nil
+		})
+	}
+	firstElement := func(batch []interface{}) string {
+		return batch[0].(string)
+	}
+
+	BeforeEach(func() {
+		written = make(map[string][]interface{})
+		writers = map[string]batching.ContextWriter{
+			"conn-1": named("conn-1"),
+			"conn-2": named("conn-2"),
+			"conn-3": named("conn-3"),
+		}
+	})
+
+	It("routes all batches with the same key to the same writer", func() {
+		w := batching.NewConsistentHashWriter(firstElement, writers)
+		b := batching.NewContextBatcher(1, time.Minute, w)
+		for i := 0; i < 5; i++ {
+			b.Write("app-1")
+		}
+
+		Expect(written).To(HaveLen(1))
+		for _, batch := range written {
+			Expect(batch).To(HaveLen(5))
+		}
+	})
+
+	It("spreads the keys across the writers", func() {
+		w := batching.NewConsistentHashWriter(firstElement, writers)
+		for i := 0; i < 300; i++ {
+			Expect(w.Write(context.Background(), []interface{}{fmt.Sprintf("app-%d", i)})).To(Succeed())
+		}
+
+		Expect(written).To(HaveLen(3))
+		for _, batch := range written {
+			Expect(len(batch)).To(BeNumerically(">", 50))
+		}
+	})
+
+	It("only moves the keys of a removed writer", func() {
+		w := batching.NewConsistentHashWriter(firstElement, writers)
+		before := make(map[string]batching.ContextWriter)
+		keys := make([]string, 100)
+		for i := range keys {
+			keys[i] = fmt.Sprintf("app-%d", i)
+			before[keys[i]] = w.Route(keys[i])
+		}
+
+		w.Remove("conn-1")
+
+		for _, key := range keys {
+			Expect(w.Write(context.Background(), []interface{}{key})).To(Succeed())
+		}
+		Expect(written).NotTo(HaveKey("conn-1"))
+		moved := 0
+		for _, key := range keys {
+			if fmt.Sprint(before[key]) != fmt.Sprint(w.Route(key)) {
+				moved++
+			}
+		}
+		Expect(moved).To(BeNumerically("<", 60))
+	})
+
+	It("fails without writers", func() {
+		w := batching.NewConsistentHashWriter(firstElement, nil)
+
+		Expect(w.Write(context.Background(), []interface{}{"app-1"})).To(HaveOccurred())
+		w.Add("conn-1", named("conn-1"))
+		Expect(w.Write(context.Background(), []interface{}{"app-1"})).To(Succeed())
+	})
+})
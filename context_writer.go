@@ -0,0 +1,70 @@
+package batching
+
+import (
+	"context"
+	"time"
+)
+
+// ContextWriter is used to submit the completed batch when submitting it
+// should honor cancellation or a deadline. The context is the one passed to
+// the Batcher method that triggered the write, or context.Background() for
+// methods that do not accept one. As with a FallibleWriter, what happens to a
+// batch that could not be written is decided by the Batcher's RetryPolicy.
+type ContextWriter interface {
+	// Write submits the batch.
+	Write(ctx context.Context, batch []interface{}) error
+}
+
+// ContextWriterFunc is an adapter to allow ordinary functions to be a
+// ContextWriter.
+type ContextWriterFunc func(ctx context.Context, batch []interface{}) error
+
+// Write implements ContextWriter.
+func (f ContextWriterFunc) Write(ctx context.Context, batch []interface{}) error {
+	return f(ctx, batch)
+}
+
+// NewContextBatcher creates a new Batcher that submits batches to a
+// ContextWriter.
+func NewContextBatcher(size int, interval time.Duration, writer ContextWriter, opts ...Option) *Batcher {
+	return newBatcher(size, interval, writer, opts)
+}
+
+// WriteContext is like Write but passes ctx to the writer if the batch is
+// submitted. It returns the error from the write, or the error of ctx if it
+// is done before the batch is submitted, in which case the batch is kept.
+func (b *Batcher) WriteContext(ctx context.Context, data interface{}) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.write(ctx, data)
+}
+
+// FlushContext is like Flush but passes ctx to the writer. It also returns
+// the error from the write, or the error of ctx if it is done before the
+// batch is submitted, in which case the batch is kept.
+func (b *Batcher) FlushContext(ctx context.Context) (FlushResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.flush(ctx)
+}
+
+// ForcedFlushContext is like ForcedFlush but passes ctx to the writer. It
+// also returns the error from the write, or the error of ctx if it is done
+// before the batch is submitted, in which case the batch is kept.
+func (b *Batcher) ForcedFlushContext(ctx context.Context) (FlushResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.writeBatch(ctx, FlushForced)
+}
+
+// fallibleContextWriter adapts a FallibleWriter to a ContextWriter.
+type fallibleContextWriter struct {
+	w FallibleWriter
+}
+
+func (w fallibleContextWriter) Write(_ context.Context, batch []interface{}) error {
+	return w.w.Write(batch)
+}
@@ -0,0 +1,90 @@
+package batching_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("ContextWriter", func() {
+	type ctxKey struct{}
+
+	It("passes the context to the writer", func() {
+		writer := &spyContextWriter{}
+		b := batching.NewContextBatcher(1, time.Minute, writer)
+		ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+		Expect(b.WriteContext(ctx, "item")).To(Succeed())
+
+		Expect(writer.ctx.Value(ctxKey{})).To(Equal("value"))
+		Expect(writer.batch).To(Equal([]interface{}{"item"}))
+	})
+
+	It("passes a background context for methods without one", func() {
+		writer := &spyContextWriter{}
+		b := batching.NewContextBatcher(1, time.Minute, writer)
+
+		b.Write("item")
+
+		Expect(writer.ctx).To(Equal(context.Background()))
+	})
+
+	It("returns the error of the writer", func() {
+		writeErr := errors.New("failed")
+		writer := &spyContextWriter{err: writeErr}
+		b := batching.NewContextBatcher(2, time.Minute, writer)
+
+		Expect(b.WriteContext(context.Background(), "item")).To(Succeed())
+		res, err := b.ForcedFlushContext(context.Background())
+		Expect(err).To(MatchError(writeErr))
+		Expect(res).To(Equal(batching.FlushResult{Written: true, Items: 1, Reason: batching.FlushForced}))
+	})
+
+	It("keeps the batch if the context is done", func() {
+		writer := &spyContextWriter{}
+		b := batching.NewContextBatcher(1, time.Nanosecond, writer)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		Expect(b.WriteContext(ctx, "item")).To(MatchError(context.Canceled))
+		_, err := b.FlushContext(ctx)
+		Expect(err).To(MatchError(context.Canceled))
+		_, err = b.ForcedFlushContext(ctx)
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(writer.called).To(Equal(0))
+
+		_, err = b.FlushContext(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(writer.batch).To(Equal([]interface{}{"item"}))
+	})
+
+	It("works with a Writer", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(2, time.Minute, writer)
+
+		Expect(b.WriteContext(context.Background(), "item")).To(Succeed())
+		res, err := b.ForcedFlushContext(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(batching.FlushResult{Written: true, Items: 1, Reason: batching.FlushForced}))
+		Expect(writer.batch).To(Equal([]interface{}{"item"}))
+	})
+})
+
+type spyContextWriter struct {
+	ctx    context.Context
+	batch  []interface{}
+	called int
+	err    error
+}
+
+func (w *spyContextWriter) Write(ctx context.Context, batch []interface{}) error {
+	w.ctx = ctx
+	w.batch = batch
+	w.called++
+	return w.err
+}
@@ -0,0 +1,86 @@
+package batching
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// EthernetDatagramSize is the largest UDP payload that fits in a
+	// single Ethernet frame with an MTU of 1500 bytes over IPv4.
+	EthernetDatagramSize = 1500 - 20 - 8
+
+	// JumboDatagramSize is the largest UDP payload that fits in a single
+	// jumbo frame with an MTU of 9000 bytes over IPv4.
+	JumboDatagramSize = 9000 - 20 - 8
+)
+
+// DatagramWriter is a ByteWriter that sends every batch as few datagrams as
+// possible, packing as many slices separated by a separator into each
+// datagram as fit under the datagram size. A slice is never split across
+// datagrams: a slice that does not fit in a datagram of its own is sent on
+// its own anyway, and most likely fails to send. Since a ByteWriter cannot
+// fail, datagrams that fail to send are counted instead. DatagramWriter
+// should be created with NewDatagramWriter().
+type DatagramWriter struct {
+	w    io.Writer
+	size int
+	sep  []byte
+
+	mu  sync.Mutex
+	buf []byte
+
+	sent   atomic.Uint64
+	failed atomic.Uint64
+}
+
+// NewDatagramWriter creates a new DatagramWriter that sends datagrams of up
+// to size bytes to w, typically a connected *net.UDPConn. A size of zero or
+// less uses EthernetDatagramSize.
+func NewDatagramWriter(w io.Writer, size int, sep []byte) *DatagramWriter {
+	if size <= 0 {
+		size = EthernetDatagramSize
+	}
+	return &DatagramWriter{w: w, size: size, sep: sep}
+}
+
+// Write implements ByteWriter.
+func (d *DatagramWriter) Write(batch [][]byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	buf := d.buf[:0]
+	for _, data := range batch {
+		if len(buf) > 0 && len(buf)+len(d.sep)+len(data) > d.size {
+			d.send(buf)
+			buf = buf[:0]
+		}
+		if len(buf) > 0 {
+			buf = append(buf, d.sep...)
+		}
+		buf = append(buf, data...)
+	}
+	if len(buf) > 0 {
+		d.send(buf)
+	}
+	d.buf = buf
+}
+
+// Sent returns the number of datagrams that were sent.
+func (d *DatagramWriter) Sent() uint64 {
+	return d.sent.Load()
+}
+
+// Failed returns the number of datagrams that failed to send.
+func (d *DatagramWriter) Failed() uint64 {
+	return d.failed.Load()
+}
+
+func (d *DatagramWriter) send(datagram []byte) {
+	if _, err := d.w.Write(datagram); err != nil {
+		d.failed.Add(1)
+		return
+	}
+	d.sent.Add(1)
+}
@@ -0,0 +1,66 @@
+package batching_test
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("DatagramWriter", func() {
+	It("packs as many slices as fit into each datagram", func() {
+		w := &countingIOWriter{}
+		d := batching.NewDatagramWriter(w, 10, []byte("\n"))
+
+		d.Write([][]byte{[]byte("aaaa"), []byte("bbbbb"), []byte("cc"), []byte("ddddddd")})
+
+		Expect(w.writes).To(Equal([]string{"aaaa\nbbbbb", "cc\nddddddd"}))
+		Expect(d.Sent()).To(Equal(uint64(2)))
+	})
+
+	It("sends slices larger than a datagram on their own", func() {
+		w := &countingIOWriter{}
+		d := batching.NewDatagramWriter(w, 4, nil)
+
+		d.Write([][]byte{[]byte("a"), []byte("bbbbbb"), []byte("c")})
+
+		Expect(w.writes).To(Equal([]string{"a", "bbbbbb", "c"}))
+	})
+
+	It("counts datagrams that fail to send", func() {
+		d := batching.NewDatagramWriter(&countingIOWriter{err: errors.New("refused")}, 0, nil)
+
+		d.Write([][]byte{[]byte("a")})
+
+		Expect(d.Sent()).To(BeZero())
+		Expect(d.Failed()).To(Equal(uint64(1)))
+	})
+
+	It("sends datagrams of the Ethernet datagram size over UDP by default", func() {
+		server, err := net.ListenPacket("udp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(server.Close)
+		conn, err := net.Dial("udp", server.LocalAddr().String())
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(conn.Close)
+
+		b := batching.NewByteBatcher(100, time.Minute, batching.NewDatagramWriter(conn, 0, []byte("\n")))
+		item := []byte(strings.Repeat("x", 699))
+		b.WriteAll(item, item, item)
+		b.ForcedFlush()
+
+		buf := make([]byte, batching.JumboDatagramSize)
+		Expect(server.SetReadDeadline(time.Now().Add(time.Second))).To(Succeed())
+		n, _, err := server.ReadFrom(buf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(1399))
+		n, _, err = server.ReadFrom(buf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(699))
+	})
+})
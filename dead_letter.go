@@ -0,0 +1,25 @@
+package batching
+
+// WithDeadLetterWriter hands every batch the writer failed to write and that
+// is not retained by the RetryPolicy to w, instead of discarding it. This
+// allows failed batches to be kept elsewhere, for instance in a local file,
+// or just to be counted. w is called while the Batcher is locked.
+func WithDeadLetterWriter(w Writer) Option {
+	return func(b *Batcher) {
+		b.deadLetter = w
+	}
+}
+
+// giveUp hands a batch that failed to write and is not retained to the spool
+// or, if it cannot be spooled, the dead letter writer. Otherwise the batch is
+// lost.
+func (b *Batcher) giveUp(batch []interface{}) {
+	if b.spool != nil && b.spool.Push(batch) == nil {
+		return
+	}
+	if b.deadLetter != nil {
+		b.deadLetter.Write(batch)
+		return
+	}
+	b.lost(batch, DropWriteFailed)
+}
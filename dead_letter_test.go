@@ -0,0 +1,67 @@
+package batching_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Dead letter writer", func() {
+	var writeErr = errors.New("failed")
+
+	It("hands batches that failed to write to the dead letter writer", func() {
+		writer := &recordingFallibleWriter{errs: []error{writeErr}}
+		deadLetter := &recordingWriter{}
+		b := batching.NewFallibleBatcher(2, time.Minute, writer,
+			batching.WithDeadLetterWriter(deadLetter),
+		)
+
+		for _, d := range []string{"a", "b", "c", "d"} {
+			b.Write(d)
+		}
+
+		Expect(deadLetter.batches).To(Equal([][]interface{}{{"a", "b"}}))
+		Expect(writer.batches).To(Equal([][]interface{}{{"a", "b"}, {"c", "d"}}))
+	})
+
+	It("does not hand retained batches to the dead letter writer", func() {
+		writer := &recordingFallibleWriter{errs: []error{writeErr, writeErr}}
+		deadLetter := &recordingWriter{}
+		b := batching.NewFallibleBatcher(1, time.Minute, writer,
+			batching.WithRetryPolicy(batching.RetainOnError(2)),
+			batching.WithDeadLetterWriter(deadLetter),
+		)
+
+		b.Write("a")
+		Expect(deadLetter.batches).To(BeEmpty())
+
+		b.ForcedFlush()
+		Expect(deadLetter.batches).To(Equal([][]interface{}{{"a"}}))
+	})
+
+	It("hands batches the writer pool failed to write to the dead letter writer", func() {
+		var mu sync.Mutex
+		var deadLettered []interface{}
+		writer := batching.FallibleWriterFunc(func([]interface{}) error {
+			return writeErr
+		})
+		b := batching.NewFallibleBatcher(1, time.Minute, writer,
+			batching.WithWriterConcurrency(2),
+			batching.WithDeadLetterWriter(batching.WriterFunc(func(batch []interface{}) {
+				mu.Lock()
+				defer mu.Unlock()
+				deadLettered = append(deadLettered, batch...)
+			})),
+		)
+
+		b.Write("a")
+		Expect(b.Close()).To(Succeed())
+
+		Expect(deadLettered).To(Equal([]interface{}{"a"}))
+	})
+})
@@ -0,0 +1,42 @@
+package batching
+
+import "time"
+
+// WithDebounce writes partial batches once no element was added for the
+// quiet period, instead of when the interval lapses, so that a burst of data
+// is written in one batch. If maxWait is greater than zero a batch is also
+// written once its oldest element has been pending for maxWait, so that a
+// source that never pauses does not hold back data forever. Writes caused
+// by the batch size, WithMaxItemAge or forced flushes are not affected.
+func WithDebounce(quiet, maxWait time.Duration) Option {
+	return func(b *Batcher) {
+		b.quietPeriod = quiet
+		b.maxWait = maxWait
+	}
+}
+
+// debounced reports whether the pending batch may be written because the
+// source went quiet or the maximum wait elapsed.
+func (b *Batcher) debounced() (FlushReason, bool) {
+	if len(b.batch) == 0 {
+		return 0, false
+	}
+	if b.clock.Since(b.lastItemAt) >= b.quietPeriod {
+		return FlushQuiet, true
+	}
+	if b.maxWait > 0 && b.clock.Since(b.firstItemAt) >= b.maxWait {
+		return FlushAge, true
+	}
+	return 0, false
+}
+
+// debounceDeadline returns when the pending batch is debounced.
+func (b *Batcher) debounceDeadline() time.Time {
+	deadline := b.lastItemAt.Add(b.quietPeriod)
+	if b.maxWait > 0 {
+		if wait := b.firstItemAt.Add(b.maxWait); wait.Before(deadline) {
+			deadline = wait
+		}
+	}
+	return deadline
+}
@@ -0,0 +1,83 @@
+package batching_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Debounce", func() {
+	var (
+		clock  *fakeClock
+		writer *spyWriter
+		b      *batching.Batcher
+	)
+
+	BeforeEach(func() {
+		clock = &fakeClock{now: time.Unix(0, 0)}
+		writer = &spyWriter{}
+		b = batching.NewBatcher(10, time.Second, writer,
+			batching.WithClock(clock),
+			batching.WithDebounce(100*time.Millisecond, 500*time.Millisecond),
+		)
+	})
+
+	It("writes the batch once no data was added for the quiet period", func() {
+		for i := 0; i < 3; i++ {
+			b.Write(i)
+			clock.Advance(90 * time.Millisecond)
+			Expect(b.Flush().Written).To(BeFalse())
+		}
+
+		clock.Advance(10 * time.Millisecond)
+		res := b.Flush()
+
+		Expect(res.Written).To(BeTrue())
+		Expect(res.Reason).To(Equal(batching.FlushQuiet))
+		Expect(writer.batch).To(Equal([]interface{}{0, 1, 2}))
+	})
+
+	It("writes the batch once the oldest data waited for the maximum wait", func() {
+		for i := 0; i < 5; i++ {
+			b.Write(i)
+			clock.Advance(90 * time.Millisecond)
+			Expect(b.Flush().Written).To(BeFalse())
+		}
+		b.Write(5)
+		clock.Advance(50 * time.Millisecond)
+
+		res := b.Flush()
+
+		Expect(res.Written).To(BeTrue())
+		Expect(res.Reason).To(Equal(batching.FlushAge))
+		Expect(writer.batch).To(HaveLen(6))
+	})
+
+	It("does not write partial batches at the interval", func() {
+		b = batching.NewBatcher(10, time.Second, writer,
+			batching.WithClock(clock),
+			batching.WithDebounce(100*time.Millisecond, 0),
+		)
+
+		for i := 0; i < 15; i++ {
+			clock.Advance(90 * time.Millisecond)
+			b.Write(i)
+			b.Flush()
+		}
+
+		Expect(writer.called).To(Equal(1))
+		Expect(writer.batch).To(HaveLen(10))
+		Expect(b.Peek()).To(HaveLen(5))
+	})
+
+	It("still writes full batches", func() {
+		for i := 0; i < 10; i++ {
+			b.Write(i)
+		}
+
+		Expect(writer.batch).To(HaveLen(10))
+	})
+})
@@ -0,0 +1,48 @@
+package batching
+
+// WithDedupKey collapses the elements of the pending batch that have the same
+// key, as returned by keyFn, right before the batch is written. Only the
+// last element written with each key is kept, which reduces the size of
+// batches of metric or state style data where only the latest value
+// matters. Keys must be comparable. Since duplicates are only collapsed when
+// the batch is written, they still count towards the batch size.
+func WithDedupKey(keyFn func(data interface{}) interface{}) Option {
+	return func(b *Batcher) {
+		b.dedupKey = keyFn
+	}
+}
+
+// dedup removes the elements of the pending batch that are followed by an
+// element with the same key, keeping the order of the remaining elements.
+func (b *Batcher) dedup() {
+	// A retained batch is written again as it was.
+	start := b.retained
+	if b.dedupKey == nil || len(b.batch)-start < 2 {
+		return
+	}
+
+	seen := make(map[interface{}]struct{}, len(b.batch)-start)
+	kept := len(b.batch)
+	for i := len(b.batch) - 1; i >= start; i-- {
+		data := b.batch[i]
+		key := b.dedupKey(data)
+		if _, ok := seen[key]; ok {
+			b.pendingBytes -= b.sizeOf(data)
+			if b.weightFn != nil {
+				b.pendingWeight -= b.weightFn(data)
+			}
+			continue
+		}
+		seen[key] = struct{}{}
+		kept--
+		b.batch[kept] = data
+	}
+	if kept == start {
+		return
+	}
+
+	n := start + copy(b.batch[start:], b.batch[kept:])
+	clear(b.batch[n:])
+	b.batch = b.batch[:n]
+	b.stats.ItemsDeduplicated += uint64(kept - start)
+}
@@ -0,0 +1,64 @@
+package batching_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Dedup key", func() {
+	name := func(data interface{}) interface{} {
+		n, _, _ := strings.Cut(data.(string), "=")
+		return n
+	}
+
+	It("keeps only the last element written with each key", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(10, time.Minute, writer, batching.WithDedupKey(name))
+		b.WriteAll("cpu=1", "mem=1", "cpu=2", "disk=1", "mem=2")
+
+		b.ForcedFlush()
+
+		Expect(writer.batch).To(Equal([]interface{}{"cpu=2", "disk=1", "mem=2"}))
+		Expect(b.Stats().ItemsDeduplicated).To(Equal(uint64(2)))
+	})
+
+	It("only collapses elements within the pending batch", func() {
+		writer := &recordingWriter{}
+		b := batching.NewBatcher(2, time.Minute, writer, batching.WithDedupKey(name))
+		b.WriteAll("cpu=1", "cpu=2", "cpu=3")
+
+		b.ForcedFlush()
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"cpu=2"}, {"cpu=3"}}))
+	})
+
+	It("updates the pending byte count", func() {
+		writer := &recordingWriter{}
+		b := batching.NewBatcher(10, time.Minute, writer,
+			batching.WithDedupKey(name),
+			batching.WithMaxBytes(10),
+			batching.WithSizeFunc(strLen),
+		)
+		b.WriteAll("cpu=1", "cpu=2")
+
+		b.ForcedFlush()
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"cpu=2"}}))
+	})
+
+	It("does not report collapsed elements as undelivered", func() {
+		b := batching.NewBatcher(10, time.Minute, &spyWriter{}, batching.WithDedupKey(name))
+		b.WriteAll("cpu=1", "cpu=2")
+
+		undelivered, err := b.Drain(context.Background())
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(undelivered).To(Equal(0))
+	})
+})
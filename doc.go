@@ -1,5 +1,8 @@
 // Package batching provides mechanisms for batching writes of various types.
-// A batcher's methods should be invoked from a single goroutine. It is the
-// responsibility of the caller to invoke Flush on the batcher frequently to
-// flush the current batch out to the writer.
+// A batcher's methods should be invoked from a single goroutine unless it was
+// created WithLocking or with NewConcurrentBatcher. It is the responsibility
+// of the caller to invoke Flush on the batcher frequently to flush the current
+// batch out to the writer, unless the batcher is driven by Run or
+// StartAutoFlush. Optional behavior is configured by passing Options to the
+// batcher's constructor.
 package batching
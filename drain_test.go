@@ -0,0 +1,141 @@
+package batching_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Drain", func() {
+	It("writes the pending data and closes the Batcher", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(10, time.Minute, writer)
+		b.WriteAll("a", "b")
+
+		undelivered, err := b.Drain(context.Background())
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(undelivered).To(Equal(0))
+		Expect(writer.batch).To(Equal([]interface{}{"a", "b"}))
+		Expect(b.WriteContext(context.Background(), "c")).To(MatchError(batching.ErrClosed))
+	})
+
+	It("reports data the writer failed to write", func() {
+		writeErr := errors.New("failed")
+		writer := &recordingFallibleWriter{errs: []error{writeErr}}
+		b := batching.NewFallibleBatcher(10, time.Minute, writer)
+		b.WriteAll("a", "b", "c")
+
+		undelivered, err := b.Drain(context.Background())
+
+		Expect(err).To(MatchError(writeErr))
+		Expect(undelivered).To(Equal(3))
+	})
+
+	It("reports the pending data if ctx is done", func() {
+		b := batching.NewBatcher(10, time.Minute, &spyWriter{})
+		b.WriteAll("a", "b")
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		undelivered, err := b.Drain(ctx)
+
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(undelivered).To(Equal(2))
+		_, err = b.Drain(context.Background())
+		Expect(err).To(MatchError(batching.ErrClosed))
+	})
+
+	It("gives up on the queued data of an AsyncBatcher once ctx expires", func() {
+		release := make(chan struct{})
+		started := make(chan struct{}, 1)
+		writer := batching.WriterFunc(func([]interface{}) {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-release
+		})
+		a := batching.NewAsyncBatcher(batching.NewBatcher(1, time.Minute, writer), 10)
+		for _, d := range []string{"a", "b", "c"} {
+			Expect(a.WriteContext(context.Background(), d)).To(Succeed())
+		}
+		Eventually(started).Should(Receive())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		go func() {
+			<-ctx.Done()
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+		}()
+		undelivered, err := a.Drain(ctx)
+
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Expect(undelivered).To(Equal(2))
+		Expect(a.TryWrite("d")).To(BeFalse())
+	})
+
+	It("returns once ctx expires while the writer of an AsyncBatcher hangs", func() {
+		hung := make(chan struct{})
+		DeferCleanup(func() { close(hung) })
+		writer := batching.WriterFunc(func([]interface{}) {
+			<-hung
+		})
+		a := batching.NewAsyncBatcher(batching.NewBatcher(10, time.Minute, writer), 10)
+		Expect(a.WriteContext(context.Background(), "a")).To(Succeed())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		done := make(chan error)
+		go func() {
+			_, err := a.Drain(ctx)
+			done <- err
+		}()
+
+		Eventually(done).Should(Receive(MatchError(context.DeadlineExceeded)))
+	})
+
+	It("cancels the final write of an AsyncBatcher once ctx expires", func() {
+		canceled := make(chan struct{})
+		writer := batching.ContextWriterFunc(func(ctx context.Context, _ []interface{}) error {
+			<-ctx.Done()
+			close(canceled)
+			return ctx.Err()
+		})
+		a := batching.NewAsyncBatcher(batching.NewContextBatcher(10, time.Minute, writer), 10)
+		Expect(a.WriteContext(context.Background(), "a")).To(Succeed())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := a.Drain(ctx)
+
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Eventually(canceled).Should(BeClosed())
+	})
+
+	It("returns once ctx expires while a writer of the writer pool hangs", func() {
+		hung := make(chan struct{})
+		DeferCleanup(func() { close(hung) })
+		writer := batching.WriterFunc(func([]interface{}) {
+			<-hung
+		})
+		b := batching.NewBatcher(1, time.Minute, writer, batching.WithWriterConcurrency(1))
+		b.Write("a")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		done := make(chan error)
+		go func() {
+			_, err := b.Drain(ctx)
+			done <- err
+		}()
+
+		Eventually(done).Should(Receive(MatchError(context.DeadlineExceeded)))
+	})
+})
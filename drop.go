@@ -0,0 +1,149 @@
+package batching
+
+import (
+	"fmt"
+	"time"
+)
+
+// DropReason describes why elements were dropped without writing them.
+type DropReason int
+
+const (
+	// DropOverflow means the pending limit was reached.
+	DropOverflow DropReason = iota
+
+	// DropExpired means the elements were older than the item TTL.
+	DropExpired
+
+	// DropWriteFailed means the writer failed to write the elements and
+	// they were neither retained by the RetryPolicy, nor spooled or handed
+	// to the dead letter writer.
+	DropWriteFailed
+
+	// DropCircuitOpen means the circuit breaker was open and its policy is
+	// Shed.
+	DropCircuitOpen
+
+	// DropClosed means the Batcher was closed before the elements could be
+	// written.
+	DropClosed
+
+	// DropMemoryLimit means the MemoryLimiter was over its budget and the
+	// PressurePolicy is DropOnPressure.
+	DropMemoryLimit
+)
+
+// String implements fmt.Stringer.
+func (r DropReason) String() string {
+	switch r {
+	case DropOverflow:
+		return "overflow"
+	case DropExpired:
+		return "expired"
+	case DropWriteFailed:
+		return "write_failed"
+	case DropCircuitOpen:
+		return "circuit_open"
+	case DropClosed:
+		return "closed"
+	case DropMemoryLimit:
+		return "memory_limit"
+	default:
+		return fmt.Sprintf("DropReason(%d)", int(r))
+	}
+}
+
+// WithOnDrop sets the callback that is called with any elements the Batcher
+// drops without writing them, for instance because of a pending limit or
+// because they expired. It is called while the Batcher is locked, so it must
+// not call back into the Batcher.
+func WithOnDrop(onDrop func(dropped []interface{})) Option {
+	return func(b *Batcher) {
+		b.onDrop = onDrop
+	}
+}
+
+// WithOnDropReason sets a callback like WithOnDrop that is also passed why
+// the elements were dropped, so that loss can be counted and alerted on by
+// reason. Unlike the drop callback, it is also called for batches the writer
+// failed to write that are not retained by the RetryPolicy, spooled or
+// handed to the dead letter writer, and for data that is still pending when
+// closing the Batcher fails. Both callbacks can be set.
+func WithOnDropReason(onDrop func(dropped []interface{}, reason DropReason)) Option {
+	return func(b *Batcher) {
+		b.onDropReason = onDrop
+	}
+}
+
+// WithItemTTL drops elements that are older than ttl when the batch is
+// written instead of writing them. The age of every element is determined
+// by timestampFn. Dropped elements are reported to the drop callback, see
+// WithOnDrop.
+func WithItemTTL(ttl time.Duration, timestampFn func(data interface{}) time.Time) Option {
+	return func(b *Batcher) {
+		b.itemTTL = ttl
+		b.timestampFn = timestampFn
+	}
+}
+
+func (b *Batcher) dropped(data []interface{}, reason DropReason) {
+	b.lost(data, reason)
+	if b.onDrop != nil && len(data) > 0 {
+		b.onDrop(data)
+	}
+}
+
+// lost reports data that was dropped without writing it to the callback set
+// WithOnDropReason and the metrics and logs, but not to the drop callback.
+func (b *Batcher) lost(data []interface{}, reason DropReason) {
+	if len(data) == 0 {
+		return
+	}
+	b.observeDropped(len(data), reason)
+	b.logDropped(len(data), reason)
+	if b.onDropReason != nil {
+		b.onDropReason(data, reason)
+	}
+}
+
+// dropExpired removes the elements that are older than the item TTL from the
+// pending batch. It reports whether there is no data left to write.
+func (b *Batcher) dropExpired() bool {
+	if b.itemTTL <= 0 || len(b.batch) == 0 {
+		return false
+	}
+
+	cutoff := b.clock.Now().Add(-b.itemTTL)
+	var expired []interface{}
+	kept := b.batch[:0]
+	for i, data := range b.batch {
+		if b.timestampFn(data).Before(cutoff) {
+			if i < b.retained {
+				b.retained = 0
+			}
+			expired = append(expired, data)
+			continue
+		}
+		kept = append(kept, data)
+	}
+	if len(expired) == 0 {
+		return false
+	}
+
+	clear(b.batch[len(kept):])
+	b.batch = kept
+	for _, data := range expired {
+		b.pendingBytes -= b.sizeOf(data)
+		if b.weightFn != nil {
+			b.pendingWeight -= b.weightFn(data)
+		}
+	}
+	b.dropped(expired, DropExpired)
+
+	if len(b.batch) == 0 {
+		b.reset()
+		b.truncateWAL()
+		return true
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package batching_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Item TTL", func() {
+	type item struct {
+		name string
+		ts   time.Time
+	}
+
+	var (
+		clock   *fakeClock
+		writer  *spyWriter
+		dropped []interface{}
+		b       *batching.Batcher
+	)
+
+	BeforeEach(func() {
+		clock = &fakeClock{now: time.Unix(100, 0)}
+		writer = &spyWriter{}
+		dropped = nil
+		b = batching.NewBatcher(10, time.Minute, writer,
+			batching.WithClock(clock),
+			batching.WithItemTTL(10*time.Second, func(data interface{}) time.Time {
+				return data.(item).ts
+			}),
+			batching.WithOnDrop(func(d []interface{}) {
+				dropped = append(dropped, d...)
+			}),
+			batching.WithSizeFunc(func(data interface{}) int {
+				return len(data.(item).name)
+			}),
+		)
+	})
+
+	It("drops expired elements when the batch is written", func() {
+		old := item{name: "old", ts: time.Unix(85, 0)}
+		fresh := item{name: "fresh", ts: time.Unix(95, 0)}
+		b.Write(old)
+		b.Write(fresh)
+
+		res := b.ForcedFlush()
+
+		Expect(res.Items).To(Equal(1))
+		Expect(writer.batch).To(Equal([]interface{}{fresh}))
+		Expect(dropped).To(Equal([]interface{}{old}))
+	})
+
+	It("does not write if every element expired", func() {
+		b.Write(item{name: "old", ts: time.Unix(85, 0)})
+
+		Expect(b.ForcedFlush().Written).To(BeFalse())
+		Expect(writer.called).To(Equal(0))
+		Expect(b.Len()).To(Equal(0))
+		Expect(b.PendingBytes()).To(Equal(0))
+	})
+})
+
+var _ = Describe("Drop reasons", func() {
+	type drop struct {
+		batch  []interface{}
+		reason batching.DropReason
+	}
+
+	var drops []drop
+
+	onDrop := batching.WithOnDropReason(func(dropped []interface{}, reason batching.DropReason) {
+		drops = append(drops, drop{batch: dropped, reason: reason})
+	})
+
+	BeforeEach(func() {
+		drops = nil
+	})
+
+	It("reports elements dropped because of the pending limit", func() {
+		b := batching.NewBatcher(10, time.Minute, &spyWriter{}, onDrop,
+			batching.WithPendingLimit(1, batching.DropOldest),
+		)
+		b.WriteAll("a", "b")
+
+		Expect(drops).To(Equal([]drop{{batch: []interface{}{"a"}, reason: batching.DropOverflow}}))
+	})
+
+	It("reports batches the writer failed to write", func() {
+		writer := &recordingFallibleWriter{errs: []error{errors.New("failed")}}
+		b := batching.NewFallibleBatcher(1, time.Minute, writer, onDrop)
+		b.Write("a")
+
+		Expect(drops).To(Equal([]drop{{batch: []interface{}{"a"}, reason: batching.DropWriteFailed}}))
+	})
+
+	It("does not report batches handed to the dead letter writer", func() {
+		writer := &recordingFallibleWriter{errs: []error{errors.New("failed")}}
+		b := batching.NewFallibleBatcher(1, time.Minute, writer, onDrop,
+			batching.WithDeadLetterWriter(&spyWriter{}),
+		)
+		b.Write("a")
+
+		Expect(drops).To(BeEmpty())
+	})
+
+	It("reports data still pending when closing fails", func() {
+		writer := &recordingFallibleWriter{errs: []error{errors.New("failed")}}
+		b := batching.NewFallibleBatcher(10, time.Minute, writer, onDrop,
+			batching.WithRetryPolicy(batching.RetainOnError(0)),
+		)
+		b.Write("a")
+
+		Expect(b.Close()).To(HaveOccurred())
+		Expect(drops).To(Equal([]drop{{batch: []interface{}{"a"}, reason: batching.DropClosed}}))
+	})
+
+	It("describes drop reasons", func() {
+		Expect(batching.DropOverflow.String()).To(Equal("overflow"))
+		Expect(batching.DropExpired.String()).To(Equal("expired"))
+		Expect(batching.DropWriteFailed.String()).To(Equal("write_failed"))
+		Expect(batching.DropCircuitOpen.String()).To(Equal("circuit_open"))
+		Expect(batching.DropClosed.String()).To(Equal("closed"))
+		Expect(batching.DropReason(-1).String()).To(Equal("DropReason(-1)"))
+	})
+})
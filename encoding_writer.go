@@ -0,0 +1,64 @@
+package batching
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Encoding serializes a batch to w.
+type Encoding func(w io.Writer, batch []interface{}) error
+
+// JSONLines is an Encoding that writes every element of the batch as JSON,
+// followed by a newline.
+func JSONLines(w io.Writer, batch []interface{}) error {
+	enc := json.NewEncoder(w)
+	for _, data := range batch {
+		if err := enc.Encode(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewEncodingWriter returns a ContextWriter that serializes every batch with
+// enc and writes it to w with a single call to Write, so that a batch is
+// never written partially because one of its elements could not be
+// encoded. The buffers used to serialize batches are reused.
+func NewEncodingWriter(enc Encoding, w io.Writer) ContextWriter {
+	return &encodingWriter{enc: enc, w: w}
+}
+
+// NewJSONLinesWriter returns a ContextWriter that writes every batch to w as
+// newline-delimited JSON. See NewEncodingWriter.
+func NewJSONLinesWriter(w io.Writer) ContextWriter {
+	return NewEncodingWriter(JSONLines, w)
+}
+
+type encodingWriter struct {
+	enc  Encoding
+	w    io.Writer
+	bufs sync.Pool
+}
+
+func (w *encodingWriter) Write(ctx context.Context, batch []interface{}) error {
+	buf, ok := w.bufs.Get().(*bytes.Buffer)
+	if !ok {
+		buf = &bytes.Buffer{}
+	}
+	defer func() {
+		buf.Reset()
+		w.bufs.Put(buf)
+	}()
+
+	if err := w.enc(buf, batch); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := w.w.Write(buf.Bytes())
+	return err
+}
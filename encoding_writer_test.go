@@ -0,0 +1,92 @@
+package batching_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("EncodingWriter", func() {
+	It("writes batches as newline-delimited JSON", func() {
+		var out bytes.Buffer
+		b := batching.NewContextBatcher(2, time.Minute, batching.NewJSONLinesWriter(&out))
+
+		b.Write(map[string]int{"a": 1})
+		b.Write("item")
+		b.Write(2)
+		b.ForcedFlush()
+
+		Expect(out.String()).To(Equal("{\"a\":1}\n\"item\"\n2\n"))
+	})
+
+	It("writes every batch with a single write", func() {
+		w := &countingIOWriter{}
+		writer := batching.NewJSONLinesWriter(w)
+
+		Expect(writer.Write(context.Background(), []interface{}{1, 2, 3})).To(Succeed())
+		Expect(writer.Write(context.Background(), []interface{}{4})).To(Succeed())
+
+		Expect(w.writes).To(Equal([]string{"1\n2\n3\n", "4\n"}))
+	})
+
+	It("does not write batches that cannot be encoded", func() {
+		w := &countingIOWriter{}
+		writer := batching.NewJSONLinesWriter(w)
+
+		err := writer.Write(context.Background(), []interface{}{1, make(chan int)})
+
+		Expect(err).To(HaveOccurred())
+		Expect(w.writes).To(BeEmpty())
+	})
+
+	It("uses the given encoding", func() {
+		w := &countingIOWriter{}
+		enc := batching.Encoding(func(w io.Writer, batch []interface{}) error {
+			_, err := io.WriteString(w, "batch")
+			return err
+		})
+		writer := batching.NewEncodingWriter(enc, w)
+
+		Expect(writer.Write(context.Background(), []interface{}{1})).To(Succeed())
+
+		Expect(w.writes).To(Equal([]string{"batch"}))
+	})
+
+	It("returns the error of the io.Writer", func() {
+		writer := batching.NewJSONLinesWriter(&countingIOWriter{err: errors.New("write failed")})
+
+		err := writer.Write(context.Background(), []interface{}{1})
+
+		Expect(err).To(MatchError("write failed"))
+	})
+
+	It("does not write if ctx is done", func() {
+		w := &countingIOWriter{}
+		writer := batching.NewJSONLinesWriter(w)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		Expect(writer.Write(ctx, []interface{}{1})).To(MatchError(context.Canceled))
+		Expect(w.writes).To(BeEmpty())
+	})
+})
+
+type countingIOWriter struct {
+	writes []string
+	err    error
+}
+
+func (w *countingIOWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
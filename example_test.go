@@ -1,6 +1,8 @@
 package batching_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -45,3 +47,55 @@ func ExampleByteBatcher() {
 	// data 1
 	// data 2
 }
+
+func ExampleByteBatcher_Run() {
+	writer := batching.ByteWriterFunc(func(batch [][]byte) {
+		for _, data := range batch {
+			fmt.Printf("%s\n", data)
+		}
+	})
+	batcher := batching.NewByteBatcher(100, time.Second, writer)
+
+	dataSource := make(chan []byte)
+	go func() {
+		defer close(dataSource)
+		for i := 0; i < 3; i++ {
+			dataSource <- []byte(fmt.Sprintf("data %d", i))
+		}
+	}()
+
+	// Run returns once the data source is closed, after writing any
+	// pending data.
+	_ = batcher.Run(context.Background(), dataSource)
+
+	// Output:
+	// data 0
+	// data 1
+	// data 2
+}
+
+func ExampleWithPendingLimit() {
+	// A downstream that is down makes the writer fail, so the batches are
+	// retained. Once the pending limit is reached the oldest log lines are
+	// dropped, as the newest ones are usually the most valuable.
+	writer := batching.FallibleWriterFunc(func([]interface{}) error {
+		return errors.New("downstream unavailable")
+	})
+	batcher := batching.NewFallibleBatcher(2, time.Minute, writer,
+		batching.WithRetryPolicy(batching.RetainOnError(0)),
+		batching.WithPendingLimit(3, batching.DropOldest),
+		batching.WithOnDrop(func(dropped []interface{}) {
+			fmt.Printf("dropped %s\n", dropped...)
+		}),
+	)
+
+	for i := 0; i < 5; i++ {
+		batcher.Write(fmt.Sprintf("line %d", i))
+	}
+	fmt.Println(batcher.Peek()...)
+
+	// Output:
+	// dropped line 0
+	// dropped line 1
+	// line 2 line 3 line 4
+}
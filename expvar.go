@@ -0,0 +1,40 @@
+package batching
+
+import "expvar"
+
+// WithExpvar publishes the counters of the Batcher with the expvar package,
+// which exposes them at /debug/vars. Every counter of Stats is published as
+// a separate variable named prefix followed by a dot and the name of the
+// counter, for example "logs.items_written". As with expvar.Publish, it
+// panics if a variable with the same name is already published, so every
+// Batcher needs its own prefix. Since the variables are read from other
+// goroutines, the Batcher is made safe for concurrent use as if created
+// WithLocking.
+func WithExpvar(prefix string) Option {
+	return func(b *Batcher) {
+		b.ensureLocking()
+
+		counters := map[string]func(s Stats) interface{}{
+			"items_written":      func(s Stats) interface{} { return s.ItemsWritten },
+			"items_flushed":      func(s Stats) interface{} { return s.ItemsFlushed },
+			"items_replayed":     func(s Stats) interface{} { return s.ItemsReplayed },
+			"items_filtered":     func(s Stats) interface{} { return s.ItemsFiltered },
+			"items_deduplicated": func(s Stats) interface{} { return s.ItemsDeduplicated },
+			"batches":            func(s Stats) interface{} { return s.Batches },
+			"write_errors":       func(s Stats) interface{} { return s.WriteErrors },
+			"pending":            func(s Stats) interface{} { return s.Pending },
+			"flushes": func(s Stats) interface{} {
+				flushes := make(map[string]uint64, len(s.Flushes))
+				for r, n := range s.Flushes {
+					flushes[r.String()] = n
+				}
+				return flushes
+			},
+		}
+		for name, counter := range counters {
+			expvar.Publish(prefix+"."+name, expvar.Func(func() interface{} {
+				return counter(b.Stats())
+			}))
+		}
+	}
+}
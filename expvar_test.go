@@ -0,0 +1,35 @@
+package batching_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Expvar", func() {
+	It("publishes the counters of the Batcher", func() {
+		b := batching.NewBatcher(2, time.Minute, &spyWriter{}, batching.WithExpvar("expvar-test"))
+		b.WriteAll("a", "b", "c")
+
+		Expect(expvar.Get("expvar-test.items_written").String()).To(Equal("3"))
+		Expect(expvar.Get("expvar-test.items_flushed").String()).To(Equal("2"))
+		Expect(expvar.Get("expvar-test.pending").String()).To(Equal("1"))
+
+		var flushes map[string]uint64
+		Expect(json.Unmarshal([]byte(expvar.Get("expvar-test.flushes").String()), &flushes)).To(Succeed())
+		Expect(flushes).To(Equal(map[string]uint64{"size": 1}))
+	})
+
+	It("panics if the prefix is already in use", func() {
+		batching.NewBatcher(2, time.Minute, &spyWriter{}, batching.WithExpvar("expvar-dup"))
+
+		Expect(func() {
+			batching.NewBatcher(2, time.Minute, &spyWriter{}, batching.WithExpvar("expvar-dup"))
+		}).To(Panic())
+	})
+})
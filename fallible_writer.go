@@ -0,0 +1,43 @@
+package batching
+
+import (
+	"context"
+	"time"
+)
+
+// FallibleWriter is used to submit the completed batch when submitting it may
+// fail. What happens to a batch that could not be written is decided by the
+// Batcher's RetryPolicy. Unless the Batcher was created WithBatchReuse, the
+// writer owns the batch even if it returns an error, since the writer is
+// passed a copy whenever a failed batch could be retained, spooled or
+// reported.
+type FallibleWriter interface {
+	// Write submits the batch.
+	Write(batch []interface{}) error
+}
+
+// FallibleWriterFunc is an adapter to allow ordinary functions to be a
+// FallibleWriter.
+type FallibleWriterFunc func(batch []interface{}) error
+
+// Write implements FallibleWriter.
+func (f FallibleWriterFunc) Write(batch []interface{}) error {
+	return f(batch)
+}
+
+// NewFallibleBatcher creates a new Batcher that submits batches to a
+// FallibleWriter. By default a batch that fails to write is dropped, use
+// WithRetryPolicy to retain it instead.
+func NewFallibleBatcher(size int, interval time.Duration, writer FallibleWriter, opts ...Option) *Batcher {
+	return newBatcher(size, interval, fallibleContextWriter{w: writer}, opts)
+}
+
+// infallibleWriter adapts a Writer to a ContextWriter.
+type infallibleWriter struct {
+	w Writer
+}
+
+func (w infallibleWriter) Write(_ context.Context, batch []interface{}) error {
+	w.w.Write(batch)
+	return nil
+}
@@ -0,0 +1,40 @@
+package batching
+
+// WithFilter drops the elements for which keep returns false instead of
+// storing them to the batch, for example to filter logs by level or to
+// apply a denylist. Filtered elements are counted in Stats.ItemsFiltered and
+// are not reported to the drop callback.
+func WithFilter(keep func(data interface{}) bool) Option {
+	return func(b *Batcher) {
+		b.filter = keep
+	}
+}
+
+// accept returns data as it should be stored to the batch, after applying
+// the transform. It reports false if data is filtered.
+func (b *Batcher) accept(data interface{}) (interface{}, bool) {
+	if b.filter != nil && !b.filter(data) {
+		b.stats.ItemsFiltered++
+		return nil, false
+	}
+	if b.transform != nil {
+		data = b.transform(data)
+	}
+	return data, true
+}
+
+// acceptAll returns the elements of data as they should be stored to the
+// batch. data is copied rather than modified if elements are filtered or
+// transformed.
+func (b *Batcher) acceptAll(data []interface{}) []interface{} {
+	if b.filter == nil && b.transform == nil {
+		return data
+	}
+	kept := make([]interface{}, 0, len(data))
+	for _, d := range data {
+		if d, ok := b.accept(d); ok {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
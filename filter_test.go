@@ -0,0 +1,45 @@
+package batching_test
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Filter", func() {
+	var (
+		writer *spyWriter
+		b      *batching.Batcher
+	)
+
+	BeforeEach(func() {
+		writer = &spyWriter{}
+		b = batching.NewBatcher(2, time.Minute, writer,
+			batching.WithFilter(func(data interface{}) bool {
+				return !strings.HasPrefix(data.(string), "DEBUG")
+			}),
+		)
+	})
+
+	It("does not store filtered elements", func() {
+		b.Write("DEBUG a")
+		b.Write("INFO b")
+		b.Write("DEBUG c")
+
+		Expect(b.Len()).To(Equal(1))
+		Expect(b.Stats().ItemsFiltered).To(Equal(uint64(2)))
+	})
+
+	It("filters the data of WriteAll", func() {
+		data := []interface{}{"INFO a", "DEBUG b", "INFO c"}
+		b.WriteAll(data...)
+
+		Expect(writer.batch).To(Equal([]interface{}{"INFO a", "INFO c"}))
+		Expect(data).To(Equal([]interface{}{"INFO a", "DEBUG b", "INFO c"}))
+		Expect(b.Stats().ItemsFiltered).To(Equal(uint64(1)))
+	})
+})
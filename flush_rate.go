@@ -0,0 +1,28 @@
+package batching
+
+import "time"
+
+// WithMinFlushInterval prevents writes caused by the batch size from
+// happening more often than once every d. Data written in the meantime keeps
+// being added to the batch, which is written in a single write once d has
+// passed since the last write, so that bursts result in fewer, larger writes
+// to rate limited downstreams. Batches written this way can therefore be
+// larger than the batch size, but never exceed the byte limit. Interval
+// based and forced flushes are not affected.
+func WithMinFlushInterval(d time.Duration) Option {
+	return func(b *Batcher) {
+		b.minFlushInterval = d
+	}
+}
+
+// full reports whether the batch is due to be written because of the batch
+// size or the byte limit, regardless of the minimum flush interval.
+func (b *Batcher) full() bool {
+	return !b.partialBatch() || !b.partialBytes()
+}
+
+// rateLimited reports whether writes caused by the batch size are held back
+// by the minimum flush interval.
+func (b *Batcher) rateLimited() bool {
+	return b.minFlushInterval > 0 && b.clock.Since(b.lastSent) < b.minFlushInterval
+}
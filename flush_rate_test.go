@@ -0,0 +1,78 @@
+package batching_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Min flush interval", func() {
+	var (
+		clock  *fakeClock
+		writer *recordingWriter
+		b      *batching.Batcher
+	)
+
+	BeforeEach(func() {
+		clock = &fakeClock{now: time.Unix(0, 0)}
+		writer = &recordingWriter{}
+		b = batching.NewBatcher(2, time.Minute, writer,
+			batching.WithClock(clock),
+			batching.WithMinFlushInterval(time.Second),
+		)
+	})
+
+	It("holds back full batches until the minimum interval passed", func() {
+		b.WriteAll("a", "b", "c")
+		Expect(writer.batches).To(BeEmpty())
+
+		clock.Advance(time.Second)
+		b.Write("d")
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"a", "b", "c", "d"}}))
+	})
+
+	It("writes a held back batch on Flush once the minimum interval passed", func() {
+		b.WriteAll("a", "b", "c")
+		Expect(b.Flush().Written).To(BeFalse())
+
+		clock.Advance(time.Second)
+		result := b.Flush()
+
+		Expect(result.Written).To(BeTrue())
+		Expect(result.Reason).To(Equal(batching.FlushSize))
+		Expect(writer.batches).To(Equal([][]interface{}{{"a", "b", "c"}}))
+	})
+
+	It("rate limits consecutive size triggered writes", func() {
+		clock.Advance(time.Second)
+		b.WriteAll("a", "b")
+		b.WriteAll("c", "d")
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"a", "b"}}))
+	})
+
+	It("does not affect forced flushes", func() {
+		b.WriteAll("a", "b", "c")
+
+		Expect(b.ForcedFlush().Written).To(BeTrue())
+		Expect(writer.batches).To(Equal([][]interface{}{{"a", "b", "c"}}))
+	})
+
+	It("still splits batches at the byte limit", func() {
+		b = batching.NewBatcher(2, time.Minute, writer,
+			batching.WithClock(clock),
+			batching.WithMinFlushInterval(time.Second),
+			batching.WithMaxBytes(2),
+			batching.WithSizeFunc(strLen),
+		)
+		b.WriteAll("a", "b", "c")
+		clock.Advance(time.Second)
+
+		Expect(b.ForcedFlush().Written).To(BeTrue())
+		Expect(writer.batches).To(Equal([][]interface{}{{"a", "b"}, {"c"}}))
+	})
+})
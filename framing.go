@@ -0,0 +1,68 @@
+package batching
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// FramerFunc appends data, framed so that the receiver can tell the
+// elements of a batch apart, to dst and returns the extended slice.
+type FramerFunc func(dst, data []byte) []byte
+
+// NewlineFramer terminates every element with a newline.
+func NewlineFramer(dst, data []byte) []byte {
+	dst = append(dst, data...)
+	return append(dst, '\n')
+}
+
+// LengthPrefixFramer prefixes every element with its length as a 32-bit
+// big-endian unsigned integer.
+func LengthPrefixFramer(dst, data []byte) []byte {
+	dst = binary.BigEndian.AppendUint32(dst, uint32(len(data)))
+	return append(dst, data...)
+}
+
+// NewIOWriter returns a ContextWriter that frames every element of the
+// batch with frame and writes the batch to w with a single call to Write,
+// turning files, pipes and network connections into writers. The elements
+// must be a []byte or a string. The buffers used to frame batches are
+// reused.
+func NewIOWriter(w io.Writer, frame FramerFunc) ContextWriter {
+	return &framedWriter{w: w, frame: frame}
+}
+
+type framedWriter struct {
+	w     io.Writer
+	frame FramerFunc
+	bufs  sync.Pool
+}
+
+func (w *framedWriter) Write(ctx context.Context, batch []interface{}) error {
+	buf, ok := w.bufs.Get().(*[]byte)
+	if !ok {
+		buf = new([]byte)
+	}
+	defer w.bufs.Put(buf)
+
+	framed := (*buf)[:0]
+	for _, data := range batch {
+		switch d := data.(type) {
+		case []byte:
+			framed = w.frame(framed, d)
+		case string:
+			framed = w.frame(framed, []byte(d))
+		default:
+			return fmt.Errorf("batching: cannot frame %T", data)
+		}
+	}
+	*buf = framed
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := w.w.Write(framed)
+	return err
+}
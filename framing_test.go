@@ -0,0 +1,56 @@
+package batching_test
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("IOWriter", func() {
+	It("writes every batch framed by newlines", func() {
+		w := &countingIOWriter{}
+		b := batching.NewContextBatcher(2, time.Minute, batching.NewIOWriter(w, batching.NewlineFramer))
+
+		b.Write([]byte("item"))
+		b.Write("other-item")
+
+		Expect(w.writes).To(Equal([]string{"item\nother-item\n"}))
+	})
+
+	It("writes every batch framed by length prefixes", func() {
+		var out bytes.Buffer
+		w := batching.NewIOWriter(&out, batching.LengthPrefixFramer)
+
+		Expect(w.Write(context.Background(), []interface{}{"ab", []byte("c")})).To(Succeed())
+
+		Expect(out.Bytes()).To(Equal([]byte{0, 0, 0, 2, 'a', 'b', 0, 0, 0, 1, 'c'}))
+	})
+
+	It("uses custom framing", func() {
+		var out bytes.Buffer
+		w := batching.NewIOWriter(&out, func(dst, data []byte) []byte {
+			dst = append(dst, '[')
+			dst = append(dst, data...)
+			return append(dst, ']')
+		})
+
+		Expect(w.Write(context.Background(), []interface{}{"a", "b"})).To(Succeed())
+
+		Expect(out.String()).To(Equal("[a][b]"))
+	})
+
+	It("fails elements that cannot be framed", func() {
+		w := &countingIOWriter{}
+		writer := batching.NewIOWriter(w, batching.NewlineFramer)
+
+		err := writer.Write(context.Background(), []interface{}{"a", 1})
+
+		Expect(err).To(MatchError(ContainSubstring("cannot frame int")))
+		Expect(w.writes).To(BeEmpty())
+	})
+})
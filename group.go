@@ -0,0 +1,146 @@
+package batching
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Group flushes many Batchers from a single goroutine, instead of each
+// Batcher needing a goroutine of its own to call Flush. The methods of a
+// Group are safe to call from multiple goroutines. Group should be created
+// with NewGroup().
+type Group struct {
+	mu       sync.Mutex
+	batchers []*Batcher
+	closed   bool
+	tick     time.Duration
+	clock    Clock
+	timer    Timer
+
+	ticks chan struct{}
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewGroup creates a new Group and starts the goroutine that flushes every
+// Batcher in the Group each tick.
+func NewGroup(tick time.Duration) *Group {
+	g := &Group{
+		tick:  tick,
+		clock: systemClock{},
+		ticks: make(chan struct{}, 1),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+
+	g.mu.Lock()
+	g.arm()
+	g.mu.Unlock()
+
+	go g.run()
+
+	return g
+}
+
+// SetClock sets the Clock used to schedule the ticks, as WithClock does for a
+// Batcher. It defaults to the system clock. The next tick is a full tick
+// after the time of c.
+func (g *Group) SetClock(c Clock) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.clock = c
+	if !g.closed {
+		g.arm()
+	}
+}
+
+// Add adds b to the Group. Since b is then flushed from another goroutine, it
+// is made safe for concurrent use as if created WithLocking, so Add must be
+// called before b is shared between goroutines. Adding a Batcher to a closed
+// Group returns ErrClosed.
+func (g *Group) Add(b *Batcher) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.closed {
+		return ErrClosed
+	}
+
+	b.ensureLocking()
+	g.batchers = append(g.batchers, b)
+	return nil
+}
+
+// ForceFlushAll writes the pending data of every Batcher in the Group.
+func (g *Group) ForceFlushAll() {
+	for _, b := range g.members() {
+		b.ForcedFlush()
+	}
+}
+
+// CloseAll stops flushing and closes every Batcher in the Group, writing any
+// pending data. It returns the errors of the Batchers that failed to close
+// joined together. Calling CloseAll more than once returns ErrClosed.
+func (g *Group) CloseAll() error {
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return ErrClosed
+	}
+	g.closed = true
+	g.timer.Stop()
+	g.mu.Unlock()
+
+	close(g.stop)
+	<-g.done
+
+	var errs []error
+	for _, b := range g.members() {
+		errs = append(errs, b.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (g *Group) members() []*Batcher {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.batchers
+}
+
+// arm schedules the next tick on the clock of the Group, replacing the one
+// already scheduled. It must be called with the lock held.
+func (g *Group) arm() {
+	if g.timer != nil {
+		g.timer.Stop()
+	}
+	g.timer = afterFunc(g.clock, g.tick, func() {
+		select {
+		case g.ticks <- struct{}{}:
+		default:
+		}
+	})
+}
+
+func (g *Group) run() {
+	defer close(g.done)
+
+	for {
+		select {
+		case <-g.ticks:
+			for _, b := range g.members() {
+				b.Flush()
+			}
+
+			g.mu.Lock()
+			if !g.closed {
+				g.arm()
+			}
+			g.mu.Unlock()
+		case <-g.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package batching_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+	"code.cloudfoundry.org/go-batching/batchingtest"
+)
+
+var _ = Describe("Group", func() {
+	var g *batching.Group
+
+	BeforeEach(func() {
+		g = batching.NewGroup(time.Millisecond)
+	})
+
+	It("flushes every Batcher in the group", func() {
+		w1, w2 := &countingWriter{}, &countingWriter{}
+		b1 := batching.NewBatcher(10, time.Millisecond, w1)
+		b2 := batching.NewBatcher(10, time.Millisecond, w2)
+		Expect(g.Add(b1)).To(Succeed())
+		Expect(g.Add(b2)).To(Succeed())
+
+		b1.Write("a")
+		b2.Write("b")
+
+		Eventually(w1.items).Should(Equal(1))
+		Eventually(w2.items).Should(Equal(1))
+		Expect(g.CloseAll()).To(Succeed())
+	})
+
+	It("schedules the ticks with its clock", func() {
+		clock := batchingtest.NewClock(time.Unix(0, 0))
+		g := batching.NewGroup(time.Second)
+		g.SetClock(clock)
+		writer := &countingWriter{}
+		b := batching.NewBatcher(10, time.Second, writer, batching.WithClock(clock))
+		Expect(g.Add(b)).To(Succeed())
+		b.Write("a")
+
+		Consistently(writer.items).Should(Equal(0))
+		clock.Advance(time.Second)
+
+		Eventually(writer.items).Should(Equal(1))
+		Expect(g.CloseAll()).To(Succeed())
+	})
+
+	It("force flushes every Batcher in the group", func() {
+		w1, w2 := &countingWriter{}, &countingWriter{}
+		b1 := batching.NewBatcher(10, time.Hour, w1)
+		b2 := batching.NewBatcher(10, time.Hour, w2)
+		Expect(g.Add(b1)).To(Succeed())
+		Expect(g.Add(b2)).To(Succeed())
+		b1.Write("a")
+		b2.Write("b")
+
+		g.ForceFlushAll()
+
+		Expect(w1.items()).To(Equal(1))
+		Expect(w2.items()).To(Equal(1))
+		Expect(g.CloseAll()).To(Succeed())
+	})
+
+	It("closes every Batcher in the group", func() {
+		writer := &countingWriter{}
+		b := batching.NewBatcher(10, time.Hour, writer)
+		Expect(g.Add(b)).To(Succeed())
+		b.Write("a")
+
+		Expect(g.CloseAll()).To(Succeed())
+
+		Expect(writer.items()).To(Equal(1))
+		Expect(b.Close()).To(MatchError(batching.ErrClosed))
+		Expect(g.CloseAll()).To(MatchError(batching.ErrClosed))
+		Expect(g.Add(b)).To(MatchError(batching.ErrClosed))
+	})
+})
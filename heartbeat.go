@@ -0,0 +1,11 @@
+package batching
+
+// WithHeartbeat makes Flush invoke the writer with an empty batch when the
+// interval lapses without any data having been written. This lets downstream
+// connections use the writes as keepalives. Writers used with this option
+// must accept empty batches.
+func WithHeartbeat() Option {
+	return func(b *Batcher) {
+		b.heartbeat = true
+	}
+}
@@ -0,0 +1,58 @@
+package batching_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Heartbeat", func() {
+	It("writes an empty batch when the interval lapses without data", func() {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		writer := &spyWriter{}
+		b := batching.NewBatcher(2, time.Minute, writer,
+			batching.WithClock(clock),
+			batching.WithHeartbeat(),
+		)
+
+		b.Flush()
+		Expect(writer.called).To(Equal(0))
+
+		clock.Advance(time.Minute)
+		res := b.Flush()
+
+		Expect(res).To(Equal(batching.FlushResult{Written: true, Reason: batching.FlushHeartbeat}))
+		Expect(writer.called).To(Equal(1))
+		Expect(writer.batch).To(BeEmpty())
+
+		b.Flush()
+		Expect(writer.called).To(Equal(1))
+	})
+
+	It("writes pending data instead of a heartbeat", func() {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		writer := &spyWriter{}
+		b := batching.NewBatcher(2, time.Minute, writer,
+			batching.WithClock(clock),
+			batching.WithHeartbeat(),
+		)
+
+		b.Write("item")
+		clock.Advance(time.Minute)
+
+		Expect(b.Flush().Reason).To(Equal(batching.FlushInterval))
+		Expect(writer.batch).To(Equal([]interface{}{"item"}))
+	})
+
+	It("does not write an empty batch on a forced flush", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(2, time.Nanosecond, writer, batching.WithHeartbeat())
+
+		b.ForcedFlush()
+
+		Expect(writer.called).To(Equal(0))
+	})
+})
@@ -0,0 +1,27 @@
+package batching
+
+import "time"
+
+// OnBeforeFlush registers f to be called with every batch right before it is
+// submitted to the writer. f may modify the elements of the batch. Hooks are
+// called in the order they were registered while the Batcher is locked, so
+// they must not call back into the Batcher. Hooks should be registered before
+// the Batcher is used.
+func (b *Batcher) OnBeforeFlush(f func(batch []interface{})) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.beforeFlush = append(b.beforeFlush, f)
+}
+
+// OnAfterFlush registers f to be called with every batch after it was
+// submitted to the writer, together with the error returned by the writer
+// and how long the write took. f is called with a copy of the batch taken
+// before the write, as the writer may have released the batch by then. See
+// OnBeforeFlush.
+func (b *Batcher) OnAfterFlush(f func(batch []interface{}, err error, d time.Duration)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.afterFlush = append(b.afterFlush, f)
+}
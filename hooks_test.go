@@ -0,0 +1,71 @@
+package batching_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Flush hooks", func() {
+	It("calls the hooks around each write", func() {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		writeErr := errors.New("failed")
+		var calls []string
+		writer := batching.FallibleWriterFunc(func(batch []interface{}) error {
+			calls = append(calls, "write")
+			clock.Advance(time.Second)
+			return writeErr
+		})
+		b := batching.NewFallibleBatcher(2, time.Minute, writer, batching.WithClock(clock))
+
+		b.OnBeforeFlush(func(batch []interface{}) {
+			calls = append(calls, "before-1")
+			Expect(batch).To(Equal([]interface{}{"a", "b"}))
+		})
+		b.OnBeforeFlush(func([]interface{}) {
+			calls = append(calls, "before-2")
+		})
+		b.OnAfterFlush(func(batch []interface{}, err error, d time.Duration) {
+			calls = append(calls, "after")
+			Expect(batch).To(Equal([]interface{}{"a", "b"}))
+			Expect(err).To(MatchError(writeErr))
+			Expect(d).To(Equal(time.Second))
+		})
+
+		b.WriteAll("a", "b")
+
+		Expect(calls).To(Equal([]string{"before-1", "before-2", "write", "after"}))
+	})
+
+	It("allows the batch to be modified before it is written", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(1, time.Minute, writer)
+		b.OnBeforeFlush(func(batch []interface{}) {
+			batch[0] = "changed"
+		})
+
+		b.Write("item")
+
+		Expect(writer.batch).To(Equal([]interface{}{"changed"}))
+	})
+
+	It("calls the after flush hooks with the batch even if the writer released it", func() {
+		var b *batching.Batcher
+		writer := batching.WriterFunc(func(batch []interface{}) {
+			b.Release(batch)
+		})
+		b = batching.NewBatcher(2, time.Minute, writer, batching.WithBatchPool())
+		var flushed []interface{}
+		b.OnAfterFlush(func(batch []interface{}, _ error, _ time.Duration) {
+			flushed = batch
+		})
+
+		b.WriteAll("a", "b")
+
+		Expect(flushed).To(Equal([]interface{}{"a", "b"}))
+	})
+})
@@ -0,0 +1,13 @@
+package httpbatching_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestHttpbatching(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Httpbatching Suite")
+}
@@ -0,0 +1,168 @@
+// Package httpbatching writes batches to HTTP endpoints, such as webhooks and
+// ingest APIs.
+package httpbatching
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+// StatusError is returned by a Writer when the endpoint responds with a
+// status code other than 2xx. It reports whether the batch should be
+// written again, so that batching.RetainRetryable drops batches the endpoint
+// rejected for good.
+type StatusError struct {
+	// StatusCode is the status code of the response.
+	StatusCode int
+
+	url       string
+	retryable bool
+}
+
+// Error implements error.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("httpbatching: POST %s: %d %s", e.url, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Retryable reports whether the status code is considered retryable.
+func (e *StatusError) Retryable() bool {
+	return e.retryable
+}
+
+// Option configures a Writer.
+type Option func(w *Writer)
+
+// WithClient sets the http.Client used to send the requests. It defaults to
+// http.DefaultClient.
+func WithClient(c *http.Client) Option {
+	return func(w *Writer) {
+		w.client = c
+	}
+}
+
+// WithEncoding sets the encoding of the request bodies and their content
+// type. It defaults to batching.JSONLines with a content type of
+// application/x-ndjson.
+func WithEncoding(enc batching.Encoding, contentType string) Option {
+	return func(w *Writer) {
+		w.enc = enc
+		w.contentType = contentType
+	}
+}
+
+// WithHeader adds a header to every request, for instance to authenticate
+// with the endpoint.
+func WithHeader(key, value string) Option {
+	return func(w *Writer) {
+		w.header.Add(key, value)
+	}
+}
+
+// WithTimeout bounds how long each request may take. By default requests
+// are only bounded by the context passed to Write and the http.Client.
+func WithTimeout(d time.Duration) Option {
+	return func(w *Writer) {
+		w.timeout = d
+	}
+}
+
+// WithRetryableStatus sets the function that decides whether a batch that
+// the endpoint responded to with a status code other than 2xx should be
+// written again. By default 408 Request Timeout, 429 Too Many Requests and
+// every 5xx status code are retryable.
+func WithRetryableStatus(retryable func(code int) bool) Option {
+	return func(w *Writer) {
+		w.retryable = retryable
+	}
+}
+
+// Writer is a batching.ContextWriter that POSTs every batch to a URL. Writer
+// should be created with NewWriter().
+type Writer struct {
+	url         string
+	client      *http.Client
+	enc         batching.Encoding
+	contentType string
+	header      http.Header
+	timeout     time.Duration
+	retryable   func(code int) bool
+	bufs        sync.Pool
+}
+
+// NewWriter creates a new Writer that POSTs to url.
+func NewWriter(url string, opts ...Option) *Writer {
+	w := &Writer{
+		url:         url,
+		client:      http.DefaultClient,
+		enc:         batching.JSONLines,
+		contentType: "application/x-ndjson",
+		header:      make(http.Header),
+		retryable:   retryableStatus,
+	}
+	for _, o := range opts {
+		o(w)
+	}
+
+	return w
+}
+
+// Write implements batching.ContextWriter. Errors sending the request are
+// returned as is and are retryable, a response with a status code other
+// than 2xx is returned as a *StatusError.
+func (w *Writer) Write(ctx context.Context, batch []interface{}) error {
+	buf, ok := w.bufs.Get().(*bytes.Buffer)
+	if !ok {
+		buf = &bytes.Buffer{}
+	}
+	defer func() {
+		buf.Reset()
+		w.bufs.Put(buf)
+	}()
+
+	if err := w.enc(buf, batch); err != nil {
+		return err
+	}
+
+	if w.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return err
+	}
+	for key, values := range w.header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", w.contentType)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &StatusError{
+			StatusCode: resp.StatusCode,
+			url:        w.url,
+			retryable:  w.retryable(resp.StatusCode),
+		}
+	}
+	return nil
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusRequestTimeout ||
+		code == http.StatusTooManyRequests ||
+		code >= 500
+}
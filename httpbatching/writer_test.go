@@ -0,0 +1,131 @@
+package httpbatching_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+	"code.cloudfoundry.org/go-batching/httpbatching"
+)
+
+var _ = Describe("Writer", func() {
+	var (
+		mu       sync.Mutex
+		requests []*http.Request
+		bodies   []string
+		status   int
+		server   *httptest.Server
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		bodies = nil
+		status = http.StatusOK
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			defer mu.Unlock()
+			requests = append(requests, r)
+			bodies = append(bodies, string(body))
+			w.WriteHeader(status)
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	It("posts every batch as newline-delimited JSON", func() {
+		b := batching.NewContextBatcher(2, time.Minute, httpbatching.NewWriter(server.URL))
+
+		b.Write("item")
+		b.Write(map[string]int{"a": 1})
+
+		Expect(bodies).To(Equal([]string{"\"item\"\n{\"a\":1}\n"}))
+		Expect(requests[0].Method).To(Equal(http.MethodPost))
+		Expect(requests[0].Header.Get("Content-Type")).To(Equal("application/x-ndjson"))
+	})
+
+	It("uses the given encoding and headers", func() {
+		enc := batching.Encoding(func(w io.Writer, batch []interface{}) error {
+			_, err := fmt.Fprint(w, len(batch))
+			return err
+		})
+		w := httpbatching.NewWriter(server.URL,
+			httpbatching.WithEncoding(enc, "text/plain"),
+			httpbatching.WithHeader("Authorization", "Bearer token"),
+		)
+
+		Expect(w.Write(context.Background(), []interface{}{1, 2, 3})).To(Succeed())
+
+		Expect(bodies).To(Equal([]string{"3"}))
+		Expect(requests[0].Header.Get("Content-Type")).To(Equal("text/plain"))
+		Expect(requests[0].Header.Get("Authorization")).To(Equal("Bearer token"))
+	})
+
+	DescribeTable("classifies status codes",
+		func(code int, retryable bool) {
+			status = code
+			w := httpbatching.NewWriter(server.URL)
+
+			err := w.Write(context.Background(), []interface{}{1})
+
+			var statusErr *httpbatching.StatusError
+			Expect(errors.As(err, &statusErr)).To(BeTrue())
+			Expect(statusErr.StatusCode).To(Equal(code))
+			Expect(statusErr.Retryable()).To(Equal(retryable))
+		},
+		Entry("bad request", http.StatusBadRequest, false),
+		Entry("request timeout", http.StatusRequestTimeout, true),
+		Entry("too many requests", http.StatusTooManyRequests, true),
+		Entry("service unavailable", http.StatusServiceUnavailable, true),
+	)
+
+	It("uses the given retryable status codes", func() {
+		status = http.StatusConflict
+		w := httpbatching.NewWriter(server.URL, httpbatching.WithRetryableStatus(func(code int) bool {
+			return code == http.StatusConflict
+		}))
+
+		err := w.Write(context.Background(), []interface{}{1})
+
+		var statusErr *httpbatching.StatusError
+		Expect(errors.As(err, &statusErr)).To(BeTrue())
+		Expect(statusErr.Retryable()).To(BeTrue())
+	})
+
+	It("lets batching.RetainRetryable drop rejected batches", func() {
+		status = http.StatusBadRequest
+		b := batching.NewContextBatcher(1, time.Minute, httpbatching.NewWriter(server.URL),
+			batching.WithRetryPolicy(batching.RetainRetryable(0)),
+		)
+
+		Expect(b.WriteContext(context.Background(), "item")).To(HaveOccurred())
+		Expect(b.Len()).To(BeZero())
+
+		status = http.StatusServiceUnavailable
+		Expect(b.WriteContext(context.Background(), "item")).To(HaveOccurred())
+		Expect(b.Len()).To(Equal(1))
+	})
+
+	It("times out slow requests", func() {
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(200 * time.Millisecond):
+			}
+		}))
+		DeferCleanup(slow.Close)
+		w := httpbatching.NewWriter(slow.URL, httpbatching.WithTimeout(10*time.Millisecond))
+
+		err := w.Write(context.Background(), []interface{}{1})
+
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+})
@@ -0,0 +1,32 @@
+package batching
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// IOWriter returns an io.Writer that stores every slice written to it to the
+// ByteBatcher. It allows a ByteBatcher to be used with loggers and encoders
+// that write to an io.Writer. A copy of each slice is stored since an
+// io.Writer must not retain the slices written to it. Write errors are those
+// returned by Batcher.WriteContext, such as ErrClosed or the error of a
+// FallibleWriter. Since the slice is stored even if writing the batch fails,
+// Write only reports that nothing was written if the Batcher is closed or
+// the slice was rejected with ErrOverflow.
+func (b *ByteBatcher) IOWriter() io.Writer {
+	return byteBatcherIOWriter{b: b}
+}
+
+type byteBatcherIOWriter struct {
+	b *ByteBatcher
+}
+
+func (w byteBatcherIOWriter) Write(p []byte) (int, error) {
+	data := append([]byte(nil), p...)
+	err := w.b.WriteContext(context.Background(), data)
+	if errors.Is(err, ErrClosed) || errors.Is(err, ErrOverflow) {
+		return 0, err
+	}
+	return len(p), err
+}
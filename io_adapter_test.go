@@ -0,0 +1,62 @@
+package batching_test
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("ByteBatcher IOWriter", func() {
+	It("stores a copy of each write", func() {
+		writer := &spyByteWriter{}
+		b := batching.NewByteBatcher(2, time.Minute, writer)
+		w := b.IOWriter()
+
+		p := []byte("item")
+		n, err := w.Write(p)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(4))
+		copy(p, "xxxx")
+
+		_, err = fmt.Fprint(w, "other-item")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(writer.batch).To(Equal([][]byte{[]byte("item"), []byte("other-item")}))
+	})
+
+	It("returns an error once the batcher is closed", func() {
+		b := batching.NewByteBatcher(2, time.Minute, &spyByteWriter{})
+		Expect(b.Close()).To(Succeed())
+
+		_, err := b.IOWriter().Write([]byte("item"))
+
+		Expect(err).To(MatchError(batching.ErrClosed))
+	})
+
+	It("reports slices that were stored even if writing the batch failed", func() {
+		release := make(chan struct{})
+		DeferCleanup(func() { close(release) })
+		writer := batching.ByteWriterFunc(func([][]byte) { <-release })
+		b := batching.NewByteBatcher(1, time.Minute, writer,
+			batching.WithWriterConcurrency(1),
+			batching.WithMaxInFlight(1, batching.Fail),
+			batching.WithPendingLimit(1, batching.Reject),
+		)
+		w := b.IOWriter()
+		_, err := w.Write([]byte("first"))
+		Expect(err).ToNot(HaveOccurred())
+
+		n, err := w.Write([]byte("second"))
+		Expect(err).To(MatchError(batching.ErrBackpressure))
+		Expect(n).To(Equal(6))
+		Expect(b.Len()).To(Equal(1))
+
+		n, err = w.Write([]byte("third"))
+		Expect(err).To(MatchError(batching.ErrOverflow))
+		Expect(n).To(Equal(0))
+	})
+})
@@ -0,0 +1,48 @@
+package batching
+
+import "context"
+
+// ContextCarrier is implemented by elements that carry the context they were
+// produced in, for example the context of the request that produced a log
+// line, so that trace context survives the batching boundary.
+type ContextCarrier interface {
+	// Context returns the context the element was produced in.
+	Context() context.Context
+}
+
+// WithItemContexts collects the contexts of the elements of every batch that
+// implement ContextCarrier and passes them to the writer in the context of
+// the write, from which a ContextWriter gets them with
+// ItemContextsFromContext. This allows a writer to link the span of the write
+// to the spans of the elements, see also the otelbatching package.
+func WithItemContexts() Option {
+	return func(b *Batcher) {
+		b.itemContexts = true
+	}
+}
+
+// ItemContextsFromContext returns the contexts of the elements of the batch
+// being written by a Batcher created WithItemContexts, in the order of the
+// elements. Elements that do not implement ContextCarrier are skipped.
+func ItemContextsFromContext(ctx context.Context) []context.Context {
+	ctxs, _ := ctx.Value(itemContextsKey{}).([]context.Context)
+	return ctxs
+}
+
+type itemContextsKey struct{}
+
+// withItemContexts returns ctx carrying the contexts of the elements of
+// batch, if the Batcher collects them.
+func (b *Batcher) withItemContexts(ctx context.Context, batch []interface{}) context.Context {
+	if !b.itemContexts {
+		return ctx
+	}
+
+	var ctxs []context.Context
+	for _, data := range batch {
+		if c, ok := data.(ContextCarrier); ok {
+			ctxs = append(ctxs, c.Context())
+		}
+	}
+	return context.WithValue(ctx, itemContextsKey{}, ctxs)
+}
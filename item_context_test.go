@@ -0,0 +1,55 @@
+package batching_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+type requestKey struct{}
+
+type logLine struct {
+	ctx  context.Context
+	line string
+}
+
+func (l logLine) Context() context.Context {
+	return l.ctx
+}
+
+var _ = Describe("Item contexts", func() {
+	It("passes the contexts of the elements to the writer", func() {
+		var requests []interface{}
+		writer := batching.ContextWriterFunc(func(ctx context.Context, _ []interface{}) error {
+			for _, itemCtx := range batching.ItemContextsFromContext(ctx) {
+				requests = append(requests, itemCtx.Value(requestKey{}))
+			}
+			return nil
+		})
+		b := batching.NewContextBatcher(3, time.Minute, writer, batching.WithItemContexts())
+
+		b.Write(logLine{ctx: context.WithValue(context.Background(), requestKey{}, "req-1"), line: "a"})
+		b.Write("not a carrier")
+		b.Write(logLine{ctx: context.WithValue(context.Background(), requestKey{}, "req-2"), line: "b"})
+
+		Expect(requests).To(Equal([]interface{}{"req-1", "req-2"}))
+	})
+
+	It("does not collect the contexts by default", func() {
+		called := false
+		writer := batching.ContextWriterFunc(func(ctx context.Context, _ []interface{}) error {
+			called = true
+			Expect(batching.ItemContextsFromContext(ctx)).To(BeNil())
+			return nil
+		})
+		b := batching.NewContextBatcher(1, time.Minute, writer)
+
+		b.Write(logLine{ctx: context.Background(), line: "a"})
+
+		Expect(called).To(BeTrue())
+	})
+})
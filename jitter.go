@@ -0,0 +1,31 @@
+package batching
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// WithJitter randomizes each interval by up to the given fraction of the
+// interval in either direction. For example a fraction of 0.1 with an
+// interval of one second results in intervals between 900ms and 1.1s. This
+// avoids many batchers with the same interval writing in lockstep.
+func WithJitter(fraction float64) Option {
+	return func(b *Batcher) {
+		b.jitter = fraction
+	}
+}
+
+// restartInterval starts a new interval at the current time.
+func (b *Batcher) restartInterval() {
+	interval := b.adaptInterval()
+	b.lastSent = b.clock.Now()
+	b.currentInterval = interval
+	if b.alignInterval && interval > 0 {
+		b.currentInterval = b.alignedInterval(interval)
+		return
+	}
+	if b.jitter > 0 {
+		offset := (rand.Float64()*2 - 1) * b.jitter
+		b.currentInterval += time.Duration(offset * float64(interval))
+	}
+}
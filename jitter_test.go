@@ -0,0 +1,39 @@
+package batching_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Jitter", func() {
+	It("randomizes the interval within the fraction", func() {
+		flushed := 0
+		for i := 0; i < 100; i++ {
+			clock := &fakeClock{now: time.Unix(0, 0)}
+			b := batching.NewBatcher(10, time.Minute, &spyWriter{},
+				batching.WithClock(clock),
+				batching.WithJitter(0.5),
+			)
+			b.Write("item")
+
+			clock.Advance(29 * time.Second)
+			Expect(b.Flush().Written).To(BeFalse())
+
+			clock.Advance(31 * time.Second)
+			if b.Flush().Written {
+				flushed++
+			}
+
+			clock.Advance(31 * time.Second)
+			b.Flush()
+			Expect(b.Len()).To(Equal(0))
+		}
+
+		Expect(flushed).To(BeNumerically(">", 0))
+		Expect(flushed).To(BeNumerically("<", 100))
+	})
+})
@@ -0,0 +1,13 @@
+package kafkabatching_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestKafkabatching(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Kafkabatching Suite")
+}
@@ -0,0 +1,129 @@
+// Package kafkabatching writes batches to Kafka through a Producer, which
+// adapts whichever Kafka client is in use.
+package kafkabatching
+
+import (
+	"encoding/json"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+// AnyPartition lets the Producer choose the partition of a message.
+const AnyPartition int32 = -1
+
+// Message is a record to produce to Kafka.
+type Message struct {
+	Topic     string
+	Partition int32
+	Key       []byte
+	Value     []byte
+}
+
+// Producer sends messages to Kafka, typically by wrapping the synchronous
+// producer of a Kafka client.
+type Producer interface {
+	// Produce sends msgs and returns the indexes of the messages that
+	// failed to send, together with the error.
+	Produce(msgs []Message) (failed []int, err error)
+}
+
+// ProducerFunc is an adapter to allow ordinary functions to be a Producer.
+type ProducerFunc func(msgs []Message) (failed []int, err error)
+
+// Produce implements Producer.
+func (f ProducerFunc) Produce(msgs []Message) ([]int, error) {
+	return f(msgs)
+}
+
+// Option configures the writer returned by NewWriter.
+type Option func(w *writer)
+
+// WithKey sets the function that returns the key of the message for each
+// element. Messages have no key by default.
+func WithKey(keyFn func(data interface{}) []byte) Option {
+	return func(w *writer) {
+		w.keyFn = keyFn
+	}
+}
+
+// WithValue sets the function that encodes each element to the value of its
+// message. By default a []byte is used as is and anything else is encoded
+// as JSON.
+func WithValue(valueFn func(data interface{}) ([]byte, error)) Option {
+	return func(w *writer) {
+		w.valueFn = valueFn
+	}
+}
+
+// WithPartitioner sets the function that returns the partition of the
+// message for each element, or AnyPartition. By default the Producer
+// chooses the partition of every message.
+func WithPartitioner(partitionFn func(data interface{}) int32) Option {
+	return func(w *writer) {
+		w.partitionFn = partitionFn
+	}
+}
+
+// NewWriter returns a batching.PartialWriter, to be used with
+// batching.NewPartialBatcher, that produces every element of the batch as
+// a message to topic. Elements whose messages failed to send while others
+// were sent are put back by the Batcher, to be written with the next batch.
+// If every message failed, or an element could not be encoded, the batch
+// failed as a whole and the RetryPolicy of the Batcher decides what happens
+// to it.
+func NewWriter(p Producer, topic string, opts ...Option) batching.PartialWriter {
+	w := &writer{
+		producer: p,
+		topic:    topic,
+		valueFn:  encodeValue,
+	}
+	for _, o := range opts {
+		o(w)
+	}
+
+	return w
+}
+
+type writer struct {
+	producer    Producer
+	topic       string
+	keyFn       func(data interface{}) []byte
+	valueFn     func(data interface{}) ([]byte, error)
+	partitionFn func(data interface{}) int32
+}
+
+func (w *writer) Write(batch []interface{}) ([]interface{}, error) {
+	msgs := make([]Message, 0, len(batch))
+	for _, data := range batch {
+		value, err := w.valueFn(data)
+		if err != nil {
+			return nil, err
+		}
+		msg := Message{Topic: w.topic, Partition: AnyPartition, Value: value}
+		if w.keyFn != nil {
+			msg.Key = w.keyFn(data)
+		}
+		if w.partitionFn != nil {
+			msg.Partition = w.partitionFn(data)
+		}
+		msgs = append(msgs, msg)
+	}
+
+	failed, err := w.producer.Produce(msgs)
+	if len(failed) == 0 || len(failed) >= len(batch) {
+		return nil, err
+	}
+
+	unwritten := make([]interface{}, 0, len(failed))
+	for _, i := range failed {
+		unwritten = append(unwritten, batch[i])
+	}
+	return unwritten, err
+}
+
+func encodeValue(data interface{}) ([]byte, error) {
+	if b, ok := data.([]byte); ok {
+		return b, nil
+	}
+	return json.Marshal(data)
+}
@@ -0,0 +1,95 @@
+package kafkabatching_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+	"code.cloudfoundry.org/go-batching/kafkabatching"
+)
+
+var _ = Describe("Writer", func() {
+	var (
+		produced [][]kafkabatching.Message
+		failed   []int
+		err      error
+		producer kafkabatching.Producer
+	)
+
+	BeforeEach(func() {
+		produced = nil
+		failed = nil
+		err = nil
+		producer = kafkabatching.ProducerFunc(func(msgs []kafkabatching.Message) ([]int, error) {
+			produced = append(produced, msgs)
+			return failed, err
+		})
+	})
+
+	It("produces every element as a message to the topic", func() {
+		b := batching.NewPartialBatcher(2, time.Minute, kafkabatching.NewWriter(producer, "logs"))
+
+		b.Write([]byte("item"))
+		b.Write(map[string]int{"a": 1})
+
+		Expect(produced).To(Equal([][]kafkabatching.Message{{
+			{Topic: "logs", Partition: kafkabatching.AnyPartition, Value: []byte("item")},
+			{Topic: "logs", Partition: kafkabatching.AnyPartition, Value: []byte(`{"a":1}`)},
+		}}))
+	})
+
+	It("uses the given key, value and partition functions", func() {
+		w := kafkabatching.NewWriter(producer, "logs",
+			kafkabatching.WithKey(func(data interface{}) []byte {
+				return []byte("key-" + data.(string))
+			}),
+			kafkabatching.WithValue(func(data interface{}) ([]byte, error) {
+				return []byte(data.(string)), nil
+			}),
+			kafkabatching.WithPartitioner(func(data interface{}) int32 {
+				return int32(len(data.(string)))
+			}),
+		)
+
+		_, writeErr := w.Write([]interface{}{"item"})
+
+		Expect(writeErr).ToNot(HaveOccurred())
+		Expect(produced).To(Equal([][]kafkabatching.Message{{
+			{Topic: "logs", Partition: 4, Key: []byte("key-item"), Value: []byte("item")},
+		}}))
+	})
+
+	It("puts back the elements whose messages failed", func() {
+		failed = []int{1}
+		err = errors.New("not enough replicas")
+		b := batching.NewPartialBatcher(3, time.Minute, kafkabatching.NewWriter(producer, "logs"))
+
+		b.WriteAll([]byte("a"), []byte("b"), []byte("c"))
+
+		Expect(b.Peek()).To(Equal([]interface{}{[]byte("b")}))
+	})
+
+	It("fails the batch as a whole if every message failed", func() {
+		failed = []int{0, 1}
+		err = errors.New("broker unavailable")
+		w := kafkabatching.NewWriter(producer, "logs")
+
+		unwritten, writeErr := w.Write([]interface{}{[]byte("a"), []byte("b")})
+
+		Expect(unwritten).To(BeEmpty())
+		Expect(writeErr).To(MatchError("broker unavailable"))
+	})
+
+	It("fails the batch if an element cannot be encoded", func() {
+		w := kafkabatching.NewWriter(producer, "logs")
+
+		unwritten, writeErr := w.Write([]interface{}{[]byte("a"), make(chan int)})
+
+		Expect(unwritten).To(BeEmpty())
+		Expect(writeErr).To(HaveOccurred())
+		Expect(produced).To(BeEmpty())
+	})
+})
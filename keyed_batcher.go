@@ -0,0 +1,166 @@
+package batching
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// KeyedWriter is used to submit the completed batch of a key.
+type KeyedWriter interface {
+	// Write submits the batch of data written with key.
+	Write(key interface{}, batch []interface{})
+}
+
+// KeyedWriterFunc is an adapter to allow ordinary functions to be a
+// KeyedWriter.
+type KeyedWriterFunc func(key interface{}, batch []interface{})
+
+// Write implements KeyedWriter.
+func (f KeyedWriterFunc) Write(key interface{}, batch []interface{}) {
+	f(key, batch)
+}
+
+// KeyedBatcher maintains an independent batch per key, for example per app
+// or per partition, each of which is written on its own size and interval.
+// The key of each element is returned by the key function and must be
+// comparable. The batches of all keys share the default configuration given
+// to NewKeyedBatcher, which Configure can override per key. Keys without
+// pending data are forgotten by Flush once they have not been written to for
+// an interval. The methods of a KeyedBatcher are safe to call from multiple
+// goroutines. KeyedBatcher should be created with NewKeyedBatcher().
+type KeyedBatcher struct {
+	mu       sync.Mutex
+	size     int
+	interval time.Duration
+	keyFn    func(data interface{}) interface{}
+	w        KeyedWriter
+	opts     []Option
+	profiles map[interface{}][]Option
+	keys     map[interface{}]*keyedBatch
+	closed   bool
+}
+
+type keyedBatch struct {
+	b         *Batcher
+	lastWrite time.Time
+}
+
+// NewKeyedBatcher creates a new KeyedBatcher. The batch of every key is a
+// Batcher created with size, interval and opts.
+func NewKeyedBatcher(size int, interval time.Duration, keyFn func(data interface{}) interface{}, writer KeyedWriter, opts ...Option) *KeyedBatcher {
+	return &KeyedBatcher{
+		size:     size,
+		interval: interval,
+		keyFn:    keyFn,
+		w:        writer,
+		opts:     opts,
+		profiles: make(map[interface{}][]Option),
+		keys:     make(map[interface{}]*keyedBatch),
+	}
+}
+
+// Write stores data in the batch of its key, writing the batch if it is
+// full. Data written after Close is dropped.
+func (k *KeyedBatcher) Write(data interface{}) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.closed {
+		return
+	}
+
+	key := k.keyFn(data)
+	e, ok := k.keys[key]
+	if !ok {
+		writer := WriterFunc(func(batch []interface{}) {
+			k.w.Write(key, batch)
+		})
+		opts := append(k.opts[:len(k.opts):len(k.opts)], k.profiles[key]...)
+		e = &keyedBatch{b: NewBatcher(k.size, k.interval, writer, opts...)}
+		k.keys[key] = e
+	}
+	e.lastWrite = e.b.clock.Now()
+	e.b.Write(data)
+}
+
+// Configure overrides the default configuration for the batch of key with
+// opts, which are applied after the default options. WithSize, WithInterval
+// and WithMaxBytes allow, for example, bigger batches for noisy apps or
+// lower latency for audit streams. If key has a batch already, its pending
+// data is written and the next Write starts a batch with the new
+// configuration.
+func (k *KeyedBatcher) Configure(key interface{}, opts ...Option) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.profiles[key] = opts
+	if e, ok := k.keys[key]; ok {
+		_ = e.b.Close()
+		delete(k.keys, key)
+	}
+}
+
+// Flush writes the batches that are due to be written and forgets the keys
+// that have been idle for the interval of their batch.
+func (k *KeyedBatcher) Flush() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for key, e := range k.keys {
+		e.b.Flush()
+		if e.b.Len() == 0 && e.b.clock.Since(e.lastWrite) >= e.b.interval {
+			_ = e.b.Close()
+			delete(k.keys, key)
+		}
+	}
+}
+
+// ForcedFlush writes the batches of all keys.
+func (k *KeyedBatcher) ForcedFlush() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for _, e := range k.keys {
+		e.b.ForcedFlush()
+	}
+}
+
+// Len returns the number of pending elements across all keys.
+func (k *KeyedBatcher) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	n := 0
+	for _, e := range k.keys {
+		n += e.b.Len()
+	}
+	return n
+}
+
+// Keys returns the number of keys being tracked.
+func (k *KeyedBatcher) Keys() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return len(k.keys)
+}
+
+// Close writes the batches of all keys and closes the KeyedBatcher. It
+// returns the errors of the batches that failed to close joined together.
+func (k *KeyedBatcher) Close() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.closed {
+		return ErrClosed
+	}
+	k.closed = true
+
+	errs := make([]error, 0, len(k.keys))
+	for key, e := range k.keys {
+		errs = append(errs, e.b.Close())
+		delete(k.keys, key)
+	}
+	return errors.Join(errs...)
+}
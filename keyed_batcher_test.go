@@ -0,0 +1,135 @@
+package batching_test
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("KeyedBatcher", func() {
+	type keyedBatch struct {
+		key   interface{}
+		batch []interface{}
+	}
+
+	var (
+		written []keyedBatch
+		writer  batching.KeyedWriter
+	)
+
+	appID := func(data interface{}) interface{} {
+		app, _, _ := strings.Cut(data.(string), "/")
+		return app
+	}
+
+	BeforeEach(func() {
+		written = nil
+		writer = batching.KeyedWriterFunc(func(key interface{}, batch []interface{}) {
+			written = append(written, keyedBatch{key: key, batch: batch})
+		})
+	})
+
+	It("batches the data of each key separately", func() {
+		b := batching.NewKeyedBatcher(2, time.Minute, appID, writer)
+		b.Write("app-1/a")
+		b.Write("app-2/a")
+		b.Write("app-1/b")
+
+		Expect(written).To(Equal([]keyedBatch{
+			{key: "app-1", batch: []interface{}{"app-1/a", "app-1/b"}},
+		}))
+		Expect(b.Len()).To(Equal(1))
+	})
+
+	It("writes the batches of all keys on ForcedFlush", func() {
+		b := batching.NewKeyedBatcher(10, time.Minute, appID, writer)
+		b.Write("app-1/a")
+		b.Write("app-2/a")
+
+		b.ForcedFlush()
+
+		Expect(written).To(ConsistOf(
+			keyedBatch{key: "app-1", batch: []interface{}{"app-1/a"}},
+			keyedBatch{key: "app-2", batch: []interface{}{"app-2/a"}},
+		))
+	})
+
+	It("forgets keys that have been idle for an interval", func() {
+		b := batching.NewKeyedBatcher(10, time.Millisecond, appID, writer)
+		b.Write("app-1/a")
+		Expect(b.Keys()).To(Equal(1))
+
+		time.Sleep(time.Millisecond)
+		b.Flush()
+
+		Expect(written).To(HaveLen(1))
+		Expect(b.Keys()).To(Equal(0))
+	})
+
+	It("keeps keys with pending data", func() {
+		b := batching.NewKeyedBatcher(10, time.Minute, appID, writer)
+		b.Write("app-1/a")
+
+		b.Flush()
+
+		Expect(b.Keys()).To(Equal(1))
+	})
+
+	It("writes the pending data on Close", func() {
+		b := batching.NewKeyedBatcher(10, time.Minute, appID, writer)
+		b.Write("app-1/a")
+
+		Expect(b.Close()).To(Succeed())
+		Expect(written).To(HaveLen(1))
+
+		b.Write("app-1/b")
+		Expect(b.Len()).To(Equal(0))
+		Expect(b.Close()).To(MatchError(batching.ErrClosed))
+	})
+	Describe("Configure", func() {
+		It("forgets a key once it has been idle for its own interval", func() {
+			clock := &fakeClock{now: time.Unix(0, 0)}
+			b := batching.NewKeyedBatcher(10, time.Minute, appID, writer, batching.WithClock(clock))
+			b.Configure("audit", batching.WithInterval(time.Hour))
+			b.Write("audit/a")
+			b.ForcedFlush()
+
+			clock.Advance(time.Minute)
+			b.Flush()
+			Expect(b.Keys()).To(Equal(1))
+
+			clock.Advance(time.Hour)
+			b.Flush()
+			Expect(b.Keys()).To(Equal(0))
+		})
+
+		It("overrides the configuration of a key", func() {
+			b := batching.NewKeyedBatcher(2, time.Minute, appID, writer)
+			b.Configure("audit", batching.WithSize(1))
+			b.Write("audit/a")
+			b.Write("app-1/a")
+
+			Expect(written).To(Equal([]keyedBatch{
+				{key: "audit", batch: []interface{}{"audit/a"}},
+			}))
+		})
+
+		It("writes the pending data of the key before reconfiguring it", func() {
+			b := batching.NewKeyedBatcher(2, time.Minute, appID, writer)
+			b.Write("app-1/a")
+
+			b.Configure("app-1", batching.WithSize(3))
+			Expect(written).To(HaveLen(1))
+
+			b.Write("app-1/b")
+			b.Write("app-1/c")
+			Expect(written).To(HaveLen(1))
+			b.Write("app-1/d")
+			Expect(written).To(HaveLen(2))
+		})
+	})
+})
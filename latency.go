@@ -0,0 +1,36 @@
+package batching
+
+import (
+	"log/slog"
+	"time"
+)
+
+// WithSlowWriteThreshold counts writes that take longer than d in
+// Stats.SlowWrites and logs a warning for each of them, if a logger is set
+// WithLogger, so that operators can see when the downstream is the
+// bottleneck.
+func WithSlowWriteThreshold(d time.Duration) Option {
+	return func(b *Batcher) {
+		b.slowWrite = d
+	}
+}
+
+// observeLatency records how long the writer took to write a batch of n
+// elements.
+func (b *Batcher) observeLatency(n int, d time.Duration) {
+	b.stats.LastWriteDuration = d
+	b.stats.TotalWriteDuration += d
+	b.stats.MaxWriteDuration = max(b.stats.MaxWriteDuration, d)
+
+	if b.slowWrite <= 0 || d <= b.slowWrite {
+		return
+	}
+	b.stats.SlowWrites++
+	if b.logger != nil {
+		b.logger.Warn("slow write",
+			slog.Int("items", n),
+			slog.Duration("duration", d),
+			slog.Duration("threshold", b.slowWrite),
+		)
+	}
+}
@@ -0,0 +1,62 @@
+package batching_test
+
+import (
+	"bytes"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Write latency", func() {
+	var (
+		clock    *fakeClock
+		writer   batching.Writer
+		latency  time.Duration
+		logs     *bytes.Buffer
+		opts     []batching.Option
+		newBatch func() *batching.Batcher
+	)
+
+	BeforeEach(func() {
+		clock = &fakeClock{now: time.Unix(0, 0)}
+		writer = batching.WriterFunc(func([]interface{}) {
+			clock.Advance(latency)
+		})
+		logs = &bytes.Buffer{}
+		opts = []batching.Option{
+			batching.WithClock(clock),
+			batching.WithSlowWriteThreshold(time.Second),
+			batching.WithLogger(slog.New(slog.NewTextHandler(logs, nil))),
+		}
+		newBatch = func() *batching.Batcher {
+			return batching.NewBatcher(1, time.Minute, writer, opts...)
+		}
+	})
+
+	It("tracks the duration of the writes", func() {
+		b := newBatch()
+		latency = 300 * time.Millisecond
+		b.Write("a")
+		latency = 100 * time.Millisecond
+		b.Write("b")
+
+		stats := b.Stats()
+		Expect(stats.LastWriteDuration).To(Equal(100 * time.Millisecond))
+		Expect(stats.MaxWriteDuration).To(Equal(300 * time.Millisecond))
+		Expect(stats.TotalWriteDuration).To(Equal(400 * time.Millisecond))
+		Expect(stats.SlowWrites).To(BeZero())
+	})
+
+	It("warns about writes slower than the threshold", func() {
+		b := newBatch()
+		latency = 2 * time.Second
+		b.Write("a")
+
+		Expect(b.Stats().SlowWrites).To(Equal(uint64(1)))
+		Expect(logs.String()).To(ContainSubstring(`level=WARN msg="slow write" items=1 duration=2s threshold=1s`))
+	})
+})
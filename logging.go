@@ -0,0 +1,66 @@
+package batching
+
+import (
+	"log/slog"
+	"time"
+)
+
+// errorLogInterval is the minimum time between two logs of writer errors.
+const errorLogInterval = time.Second
+
+// WithLogger emits structured logs to logger: debug logs for every write and
+// every batch retained for a retry, and warnings for dropped data and writer
+// errors. Writer errors are logged at most once per second, reporting how
+// many errors were not logged in between, to avoid log storms while the
+// downstream is unavailable.
+func WithLogger(logger *slog.Logger) Option {
+	return func(b *Batcher) {
+		b.logger = logger
+	}
+}
+
+func (b *Batcher) logFlush(reason FlushReason, items int, err error, d time.Duration) {
+	if b.logger == nil {
+		return
+	}
+
+	if err == nil {
+		b.logger.Debug("batch written",
+			slog.String("reason", reason.String()),
+			slog.Int("items", items),
+			slog.Duration("duration", d),
+		)
+		return
+	}
+
+	now := b.clock.Now()
+	if now.Sub(b.lastErrorLog) < errorLogInterval {
+		b.suppressedErrors++
+		return
+	}
+	b.logger.Warn("failed to write batch",
+		slog.String("reason", reason.String()),
+		slog.Int("items", items),
+		slog.Duration("duration", d),
+		slog.Any("error", err),
+		slog.Int("suppressed", b.suppressedErrors),
+	)
+	b.lastErrorLog = now
+	b.suppressedErrors = 0
+}
+
+func (b *Batcher) logRetained(err error) {
+	if b.logger != nil {
+		b.logger.Debug("batch retained for retry",
+			slog.Int("failures", b.failures),
+			slog.Int("pending", len(b.batch)),
+			slog.Any("error", err),
+		)
+	}
+}
+
+func (b *Batcher) logDropped(n int, reason DropReason) {
+	if b.logger != nil {
+		b.logger.Warn("dropped data", slog.Int("items", n), slog.String("reason", reason.String()))
+	}
+}
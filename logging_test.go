@@ -0,0 +1,95 @@
+package batching_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Logger", func() {
+	var (
+		buf    *bytes.Buffer
+		logger *slog.Logger
+		clock  *fakeClock
+	)
+
+	logs := func() []map[string]interface{} {
+		var entries []map[string]interface{}
+		dec := json.NewDecoder(buf)
+		for dec.More() {
+			var e map[string]interface{}
+			Expect(dec.Decode(&e)).To(Succeed())
+			entries = append(entries, e)
+		}
+		return entries
+	}
+
+	BeforeEach(func() {
+		buf = &bytes.Buffer{}
+		logger = slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		clock = &fakeClock{now: time.Unix(0, 0)}
+	})
+
+	It("logs writes at debug level", func() {
+		b := batching.NewBatcher(2, time.Minute, &spyWriter{}, batching.WithLogger(logger))
+		b.WriteAll("a", "b")
+
+		entries := logs()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0]).To(HaveKeyWithValue("level", "DEBUG"))
+		Expect(entries[0]).To(HaveKeyWithValue("msg", "batch written"))
+		Expect(entries[0]).To(HaveKeyWithValue("reason", "size"))
+		Expect(entries[0]).To(HaveKeyWithValue("items", 2.0))
+	})
+
+	It("logs retained batches and dropped data", func() {
+		writer := &recordingFallibleWriter{errs: []error{errors.New("failed"), errors.New("failed")}}
+		b := batching.NewFallibleBatcher(1, time.Minute, writer,
+			batching.WithClock(clock),
+			batching.WithLogger(logger),
+			batching.WithRetryPolicy(batching.RetainOnError(0)),
+			batching.WithPendingLimit(1, batching.DropOldest),
+		)
+		b.Write("a")
+		b.Write("b")
+
+		var msgs []interface{}
+		for _, e := range logs() {
+			msgs = append(msgs, e["msg"])
+		}
+		Expect(msgs).To(ContainElements("batch retained for retry", "dropped data", "failed to write batch"))
+	})
+
+	It("rate limits the logs of writer errors", func() {
+		writer := batching.FallibleWriterFunc(func([]interface{}) error {
+			return errors.New("failed")
+		})
+		b := batching.NewFallibleBatcher(1, time.Minute, writer,
+			batching.WithClock(clock),
+			batching.WithLogger(logger),
+		)
+		for i := 0; i < 5; i++ {
+			b.Write(i)
+		}
+		clock.Advance(time.Second)
+		b.Write(5)
+
+		var warnings []map[string]interface{}
+		for _, e := range logs() {
+			if e["msg"] == "failed to write batch" {
+				warnings = append(warnings, e)
+			}
+		}
+		Expect(warnings).To(HaveLen(2))
+		Expect(warnings[0]).To(HaveKeyWithValue("suppressed", 0.0))
+		Expect(warnings[1]).To(HaveKeyWithValue("suppressed", 4.0))
+		Expect(warnings[1]).To(HaveKeyWithValue("error", "failed"))
+	})
+})
@@ -0,0 +1,81 @@
+package batching
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TimerClock is a Clock that can also schedule functions. A Batcher uses it,
+// when its Clock implements it, for anything that must happen after a delay
+// without waiting for the next call to Flush.
+type TimerClock interface {
+	Clock
+
+	// AfterFunc waits for d to elapse and then calls f in its own goroutine.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a scheduled call created by a TimerClock.
+type Timer interface {
+	// Stop prevents the call from happening. It returns false if the call
+	// already happened or the timer was already stopped.
+	Stop() bool
+}
+
+// WithMaxItemAge guarantees that data is written no later than maxAge after
+// it entered an empty batch, regardless of the interval or of how often Flush
+// is called. A timer writes the batch once maxAge has elapsed, so the writer
+// may be called from another goroutine and the Batcher is made safe for
+// concurrent use as if created WithLocking.
+func WithMaxItemAge(maxAge time.Duration) Option {
+	return func(b *Batcher) {
+		b.maxItemAge = maxAge
+		b.mu = &sync.Mutex{}
+	}
+}
+
+// expired reports whether the batch has been pending longer than the maximum
+// item age.
+func (b *Batcher) expired() bool {
+	return b.maxItemAge > 0 && len(b.batch) > 0 && !b.clock.Now().Before(b.ageDeadline)
+}
+
+// startAgeTimer (re)starts the maximum item age deadline for the pending
+// batch.
+func (b *Batcher) startAgeTimer() {
+	if b.maxItemAge <= 0 {
+		return
+	}
+
+	b.stopAgeTimer()
+	b.ageDeadline = b.clock.Now().Add(b.maxItemAge)
+	b.ageTimer = b.afterFunc(b.maxItemAge, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if b.expired() {
+			_, _ = b.writeBatch(context.Background(), FlushAge)
+		}
+	})
+}
+
+func (b *Batcher) stopAgeTimer() {
+	if b.ageTimer != nil {
+		b.ageTimer.Stop()
+		b.ageTimer = nil
+	}
+}
+
+func (b *Batcher) afterFunc(d time.Duration, f func()) Timer {
+	return afterFunc(b.clock, d, f)
+}
+
+// afterFunc schedules f on clock if it is a TimerClock, so that fake clocks
+// control every delay, and on the system clock otherwise.
+func afterFunc(clock Clock, d time.Duration, f func()) Timer {
+	if c, ok := clock.(TimerClock); ok {
+		return c.AfterFunc(d, f)
+	}
+	return time.AfterFunc(d, f)
+}
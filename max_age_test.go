@@ -0,0 +1,98 @@
+package batching_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Max item age", func() {
+	It("writes the batch once the first element is too old", func() {
+		writer := &countingWriter{}
+		b := batching.NewBatcher(10, time.Hour, writer,
+			batching.WithMaxItemAge(10*time.Millisecond),
+		)
+
+		b.Write("item")
+
+		Eventually(writer.items).Should(Equal(1))
+	})
+
+	It("measures the age from when the first element entered the batch", func() {
+		clock := &fakeTimerClock{fakeClock: fakeClock{now: time.Unix(0, 0)}}
+		writer := &spyWriter{}
+		b := batching.NewBatcher(10, time.Minute, writer,
+			batching.WithClock(clock),
+			batching.WithMaxItemAge(10*time.Second),
+		)
+
+		clock.Advance(30 * time.Second)
+		b.Write("item")
+		clock.Advance(5 * time.Second)
+		b.Write("other-item")
+		b.Flush()
+		Expect(writer.called).To(Equal(0))
+
+		clock.Advance(5 * time.Second)
+		b.Flush()
+		Expect(writer.called).To(Equal(1))
+		Expect(writer.batch).To(HaveLen(2))
+	})
+
+	It("writes the batch from the timer without a call to Flush", func() {
+		clock := &fakeTimerClock{fakeClock: fakeClock{now: time.Unix(0, 0)}}
+		writer := &spyWriter{}
+		b := batching.NewBatcher(10, time.Minute, writer,
+			batching.WithClock(clock),
+			batching.WithMaxItemAge(10*time.Second),
+		)
+
+		b.Write("item")
+		Expect(clock.timers).To(HaveLen(1))
+		Expect(clock.timers[0].d).To(Equal(10 * time.Second))
+
+		clock.Advance(10 * time.Second)
+		clock.timers[0].f()
+
+		Expect(writer.called).To(Equal(1))
+	})
+
+	It("stops the timer once the batch is written", func() {
+		clock := &fakeTimerClock{fakeClock: fakeClock{now: time.Unix(0, 0)}}
+		b := batching.NewBatcher(1, time.Minute, &spyWriter{},
+			batching.WithClock(clock),
+			batching.WithMaxItemAge(10*time.Second),
+		)
+
+		b.Write("item")
+
+		Expect(clock.timers).To(HaveLen(1))
+		Expect(clock.timers[0].stopped).To(BeTrue())
+	})
+})
+
+type fakeTimerClock struct {
+	fakeClock
+	timers []*fakeTimer
+}
+
+func (c *fakeTimerClock) AfterFunc(d time.Duration, f func()) batching.Timer {
+	t := &fakeTimer{d: d, f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+type fakeTimer struct {
+	d       time.Duration
+	f       func()
+	stopped bool
+}
+
+func (t *fakeTimer) Stop() bool {
+	stopped := t.stopped
+	t.stopped = true
+	return !stopped
+}
@@ -0,0 +1,153 @@
+package batching
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// PressurePolicy decides how a Batcher registered with a MemoryLimiter frees
+// memory once the limiter is over its budget.
+type PressurePolicy int
+
+const (
+	// FlushOnPressure writes the pending batch.
+	FlushOnPressure PressurePolicy = iota
+
+	// DropOnPressure drops the pending batch without writing it.
+	DropOnPressure
+)
+
+// MemoryLimiter bounds the bytes buffered by all of the batchers registered
+// with it, as measured by their size functions. Once the total exceeds the
+// budget, the limiter selects batchers to free memory in order of priority,
+// lowest first, until enough memory would be freed. Each selected Batcher
+// flushes or drops its pending batch, depending on its PressurePolicy, the
+// next time it is written to or flushed.
+type MemoryLimiter struct {
+	budget int
+
+	mu      sync.Mutex
+	used    int
+	members []*memoryMember
+}
+
+type memoryMember struct {
+	priority int
+	policy   PressurePolicy
+	bytes    int
+	pressed  atomic.Bool
+}
+
+// NewMemoryLimiter returns a MemoryLimiter with a budget of the given number
+// of bytes.
+func NewMemoryLimiter(budget int) *MemoryLimiter {
+	return &MemoryLimiter{budget: budget}
+}
+
+// WithMemoryLimiter registers the Batcher with the limiter. Batchers with a
+// higher priority are selected to free memory only after the batchers with
+// a lower priority. The pending bytes are measured by the size function
+// configured with WithSizeFunc, so the Batcher does not count towards the
+// budget without one. A closed Batcher is unregistered.
+func WithMemoryLimiter(l *MemoryLimiter, priority int, policy PressurePolicy) Option {
+	return func(b *Batcher) {
+		b.memory = l
+		b.memoryMember = l.register(priority, policy)
+	}
+}
+
+// Used returns the number of bytes buffered by the registered batchers.
+func (l *MemoryLimiter) Used() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.used
+}
+
+func (l *MemoryLimiter) register(priority int, policy PressurePolicy) *memoryMember {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	m := &memoryMember{priority: priority, policy: policy}
+	l.members = append(l.members, m)
+	return m
+}
+
+func (l *MemoryLimiter) unregister(m *memoryMember) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.used -= m.bytes
+	m.bytes = 0
+	for i, member := range l.members {
+		if member == m {
+			l.members = append(l.members[:i], l.members[i+1:]...)
+			break
+		}
+	}
+}
+
+// update records the pending bytes of m and selects the members that must
+// free memory if the budget is exceeded.
+func (l *MemoryLimiter) update(m *memoryMember, bytes int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.used += bytes - m.bytes
+	m.bytes = bytes
+	if l.used <= l.budget {
+		return
+	}
+
+	var candidates []*memoryMember
+	excess := l.used - l.budget
+	for _, member := range l.members {
+		if member.pressed.Load() {
+			excess -= member.bytes
+			continue
+		}
+		if member.bytes > 0 {
+			candidates = append(candidates, member)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].priority != candidates[j].priority {
+			return candidates[i].priority < candidates[j].priority
+		}
+		return candidates[i].bytes > candidates[j].bytes
+	})
+	for _, member := range candidates {
+		if excess <= 0 {
+			return
+		}
+		member.pressed.Store(true)
+		excess -= member.bytes
+	}
+}
+
+// observeMemory reports the pending bytes to the memory limiter, if any.
+func (b *Batcher) observeMemory() {
+	if b.memory != nil {
+		b.memory.update(b.memoryMember, b.pendingBytes)
+	}
+}
+
+// pressured reports whether the memory limiter selected the Batcher to free
+// memory since it was last asked.
+func (b *Batcher) pressured() bool {
+	return b.memory != nil && b.memoryMember.pressed.Swap(false)
+}
+
+// shed drops the pending batch to free memory if the PressurePolicy says so.
+// It reports whether the batch was dropped.
+func (b *Batcher) shed(reason FlushReason) bool {
+	if reason != FlushMemory || b.memoryMember.policy != DropOnPressure {
+		return false
+	}
+
+	b.dropped(b.batch, DropMemoryLimit)
+	b.reset()
+	b.truncateWAL()
+	return true
+}
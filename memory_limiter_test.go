@@ -0,0 +1,114 @@
+package batching_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("MemoryLimiter", func() {
+	var limiter *batching.MemoryLimiter
+
+	BeforeEach(func() {
+		limiter = batching.NewMemoryLimiter(10)
+	})
+
+	It("counts the pending bytes of the registered batchers", func() {
+		b1 := batching.NewBatcher(100, time.Minute, &spyWriter{},
+			batching.WithSizeFunc(strLen),
+			batching.WithMemoryLimiter(limiter, 0, batching.FlushOnPressure),
+		)
+		b2 := batching.NewBatcher(100, time.Minute, &spyWriter{},
+			batching.WithSizeFunc(strLen),
+			batching.WithMemoryLimiter(limiter, 0, batching.FlushOnPressure),
+		)
+
+		b1.Write("abc")
+		b2.Write("de")
+		Expect(limiter.Used()).To(Equal(5))
+
+		b1.ForcedFlush()
+		Expect(limiter.Used()).To(Equal(2))
+
+		Expect(b2.Close()).To(Succeed())
+		Expect(limiter.Used()).To(BeZero())
+	})
+
+	It("flushes the batchers with the lowest priority first", func() {
+		low := &recordingWriter{}
+		high := &recordingWriter{}
+		lowBatcher := batching.NewBatcher(100, time.Minute, low,
+			batching.WithSizeFunc(strLen),
+			batching.WithMemoryLimiter(limiter, 0, batching.FlushOnPressure),
+		)
+		highBatcher := batching.NewBatcher(100, time.Minute, high,
+			batching.WithSizeFunc(strLen),
+			batching.WithMemoryLimiter(limiter, 1, batching.FlushOnPressure),
+		)
+
+		lowBatcher.Write("aaaa")
+		highBatcher.Write("bbbb")
+		highBatcher.Write("cccc")
+		Expect(low.batches).To(BeEmpty())
+		Expect(high.batches).To(BeEmpty())
+
+		res := lowBatcher.Flush()
+		Expect(res.Written).To(BeTrue())
+		Expect(res.Reason).To(Equal(batching.FlushMemory))
+		Expect(low.batches).To(Equal([][]interface{}{{"aaaa"}}))
+		Expect(high.batches).To(BeEmpty())
+		Expect(limiter.Used()).To(Equal(8))
+	})
+
+	It("flushes the Batcher that is written to if it is selected", func() {
+		writer := &recordingWriter{}
+		b := batching.NewBatcher(100, time.Minute, writer,
+			batching.WithSizeFunc(strLen),
+			batching.WithMemoryLimiter(limiter, 0, batching.FlushOnPressure),
+		)
+
+		b.Write("aaaaaa")
+		b.Write("bbbbbb")
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"aaaaaa", "bbbbbb"}}))
+		Expect(limiter.Used()).To(BeZero())
+	})
+
+	It("drops the pending batch if the policy says so", func() {
+		writer := &spyWriter{}
+		var dropped []interface{}
+		var reason batching.DropReason
+		b := batching.NewBatcher(100, time.Minute, writer,
+			batching.WithSizeFunc(strLen),
+			batching.WithMemoryLimiter(limiter, 0, batching.DropOnPressure),
+			batching.WithOnDropReason(func(d []interface{}, r batching.DropReason) {
+				dropped = d
+				reason = r
+			}),
+		)
+
+		b.Write("aaaaaa")
+		b.Write("bbbbbb")
+
+		Expect(writer.called).To(BeZero())
+		Expect(b.Len()).To(BeZero())
+		Expect(dropped).To(Equal([]interface{}{"aaaaaa", "bbbbbb"}))
+		Expect(reason).To(Equal(batching.DropMemoryLimit))
+		Expect(limiter.Used()).To(BeZero())
+	})
+
+	It("ignores batchers without a size function", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(100, time.Minute, writer,
+			batching.WithMemoryLimiter(limiter, 0, batching.FlushOnPressure),
+		)
+
+		b.Write("aaaaaaaaaaaa")
+
+		Expect(writer.called).To(BeZero())
+		Expect(limiter.Used()).To(BeZero())
+	})
+})
@@ -0,0 +1,77 @@
+package batching
+
+import "time"
+
+// Names of the metrics reported to Metrics.
+const (
+	// MetricFlushes counts the invocations of the writer, labeled with the
+	// reason and the outcome, which is "success" or "error".
+	MetricFlushes = "batching_flushes_total"
+
+	// MetricBatchSize is a histogram of the number of elements submitted
+	// per invocation of the writer, labeled with the reason.
+	MetricBatchSize = "batching_batch_size"
+
+	// MetricFlushDuration is a histogram of the time the writer took to
+	// write a batch in seconds, labeled with the reason.
+	MetricFlushDuration = "batching_flush_duration_seconds"
+
+	// MetricDropped counts the elements dropped without writing them,
+	// labeled with the reason.
+	MetricDropped = "batching_dropped_total"
+
+	// MetricPending is a gauge of the number of pending elements.
+	MetricPending = "batching_pending"
+)
+
+// Metrics is implemented by metrics backends that the Batcher reports its
+// activity to, see WithMetrics. The labels passed to the methods must not
+// be modified or retained. The methods are called while the Batcher is
+// locked, so they must not call back into the Batcher and should be fast.
+type Metrics interface {
+	// Counter adds delta to the counter name.
+	Counter(name string, delta float64, labels map[string]string)
+
+	// Gauge sets the gauge name to value.
+	Gauge(name string, value float64, labels map[string]string)
+
+	// Histogram records value in the histogram name.
+	Histogram(name string, value float64, labels map[string]string)
+}
+
+// WithMetrics reports the activity of the Batcher to m, using the metric
+// names defined by the Metric constants.
+func WithMetrics(m Metrics) Option {
+	return func(b *Batcher) {
+		b.metrics = m
+	}
+}
+
+func (b *Batcher) observeFlush(reason FlushReason, items int, err error, d time.Duration) {
+	if b.metrics == nil {
+		return
+	}
+
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	b.metrics.Counter(MetricFlushes, 1, map[string]string{"reason": reason.String(), "outcome": outcome})
+
+	labels := map[string]string{"reason": reason.String()}
+	b.metrics.Histogram(MetricBatchSize, float64(items), labels)
+	b.metrics.Histogram(MetricFlushDuration, d.Seconds(), labels)
+}
+
+func (b *Batcher) observeDropped(n int, reason DropReason) {
+	if b.metrics != nil {
+		b.metrics.Counter(MetricDropped, float64(n), map[string]string{"reason": reason.String()})
+	}
+}
+
+func (b *Batcher) observePending() {
+	b.observeMemory()
+	if b.metrics != nil {
+		b.metrics.Gauge(MetricPending, float64(len(b.batch)), nil)
+	}
+}
@@ -0,0 +1,93 @@
+package batching_test
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Metrics", func() {
+	var m *fakeMetrics
+
+	BeforeEach(func() {
+		m = &fakeMetrics{
+			counters:   make(map[string]float64),
+			gauges:     make(map[string]float64),
+			histograms: make(map[string][]float64),
+		}
+	})
+
+	It("reports flushes by reason and outcome", func() {
+		writer := &recordingFallibleWriter{errs: []error{nil, errors.New("failed")}}
+		b := batching.NewFallibleBatcher(2, time.Minute, writer, batching.WithMetrics(m))
+		b.WriteAll("a", "b")
+		b.Write("c")
+		b.ForcedFlush()
+
+		Expect(m.counters).To(HaveKeyWithValue("batching_flushes_total{outcome=success,reason=size}", 1.0))
+		Expect(m.counters).To(HaveKeyWithValue("batching_flushes_total{outcome=error,reason=forced}", 1.0))
+		Expect(m.histograms).To(HaveKeyWithValue("batching_batch_size{reason=size}", []float64{2}))
+		Expect(m.histograms).To(HaveKeyWithValue("batching_batch_size{reason=forced}", []float64{1}))
+		Expect(m.histograms).To(HaveKey("batching_flush_duration_seconds{reason=size}"))
+	})
+
+	It("reports the pending elements", func() {
+		b := batching.NewBatcher(3, time.Minute, &spyWriter{}, batching.WithMetrics(m))
+		b.WriteAll("a", "b")
+		Expect(m.gauges).To(HaveKeyWithValue("batching_pending", 2.0))
+
+		b.ForcedFlush()
+		Expect(m.gauges).To(HaveKeyWithValue("batching_pending", 0.0))
+	})
+
+	It("reports dropped elements", func() {
+		b := batching.NewBatcher(10, time.Minute, &spyWriter{},
+			batching.WithMetrics(m),
+			batching.WithPendingLimit(1, batching.DropOldest),
+		)
+		b.WriteAll("a", "b", "c")
+
+		Expect(m.counters).To(HaveKeyWithValue("batching_dropped_total{reason=overflow}", 2.0))
+	})
+})
+
+type fakeMetrics struct {
+	counters   map[string]float64
+	gauges     map[string]float64
+	histograms map[string][]float64
+}
+
+func (m *fakeMetrics) Counter(name string, delta float64, labels map[string]string) {
+	m.counters[metricKey(name, labels)] += delta
+}
+
+func (m *fakeMetrics) Gauge(name string, value float64, labels map[string]string) {
+	m.gauges[metricKey(name, labels)] = value
+}
+
+func (m *fakeMetrics) Histogram(name string, value float64, labels map[string]string) {
+	key := metricKey(name, labels)
+	m.histograms[key] = append(m.histograms[key], value)
+}
+
+func metricKey(name string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return name
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + labels[k]
+	}
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,16 @@
+package batching
+
+// Middleware decorates a ContextWriter with cross-cutting behavior, such as
+// logging, metrics or compression, by returning a ContextWriter that calls
+// next.
+type Middleware func(next ContextWriter) ContextWriter
+
+// ChainWriters decorates w with middleware. The first middleware is the
+// outermost one, so it sees the batch first and the error of the write
+// last. The result can be passed to NewContextBatcher.
+func ChainWriters(w ContextWriter, middleware ...Middleware) ContextWriter {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		w = middleware[i](w)
+	}
+	return w
+}
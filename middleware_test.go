@@ -0,0 +1,55 @@
+package batching_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("ChainWriters", func() {
+	It("applies the middleware in order", func() {
+		var calls []string
+		trace := func(name string) batching.Middleware {
+			return func(next batching.ContextWriter) batching.ContextWriter {
+				return batching.ContextWriterFunc(func(ctx context.Context, batch []interface{}) error {
+					calls = append(calls, name+" before")
+					err := next.Write(ctx, batch)
+					calls = append(calls, name+" after")
+					return err
+				})
+			}
+		}
+		writer := batching.ContextWriterFunc(func(context.Context, []interface{}) error {
+			calls = append(calls, "write")
+			return nil
+		})
+
+		b := batching.NewContextBatcher(1, time.Minute,
+			batching.ChainWriters(writer, trace("outer"), trace("inner")),
+		)
+		b.Write("a")
+
+		Expect(calls).To(Equal([]string{
+			"outer before",
+			"inner before",
+			"write",
+			"inner after",
+			"outer after",
+		}))
+	})
+
+	It("returns the writer without middleware", func() {
+		var written []interface{}
+		writer := batching.ContextWriterFunc(func(_ context.Context, batch []interface{}) error {
+			written = batch
+			return nil
+		})
+
+		Expect(batching.ChainWriters(writer).Write(context.Background(), []interface{}{"a"})).To(Succeed())
+		Expect(written).To(Equal([]interface{}{"a"}))
+	})
+})
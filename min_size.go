@@ -0,0 +1,24 @@
+package batching
+
+import "time"
+
+// WithMinSize avoids writing batches with fewer than minSize elements when
+// the interval lapses, unless the oldest element has been pending for at
+// least maxWait. This reduces the number of tiny batches for downstreams
+// where each write is expensive. It does not affect writes caused by the
+// batch size, WithMaxItemAge or forced flushes.
+func WithMinSize(minSize int, maxWait time.Duration) Option {
+	return func(b *Batcher) {
+		b.minSize = minSize
+		b.minMaxWait = maxWait
+	}
+}
+
+// enoughForInterval reports whether the pending batch may be written because
+// the interval lapsed.
+func (b *Batcher) enoughForInterval() bool {
+	if len(b.batch) >= b.minSize {
+		return true
+	}
+	return b.clock.Since(b.firstItemAt) >= b.minMaxWait
+}
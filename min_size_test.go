@@ -0,0 +1,50 @@
+package batching_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Min size", func() {
+	var (
+		clock  *fakeClock
+		writer *spyWriter
+		b      *batching.Batcher
+	)
+
+	BeforeEach(func() {
+		clock = &fakeClock{now: time.Unix(0, 0)}
+		writer = &spyWriter{}
+		b = batching.NewBatcher(10, time.Minute, writer,
+			batching.WithClock(clock),
+			batching.WithMinSize(3, 5*time.Minute),
+		)
+	})
+
+	It("does not write a small batch when the interval lapses", func() {
+		b.Write("a")
+		clock.Advance(time.Minute)
+
+		Expect(b.Flush().Written).To(BeFalse())
+
+		b.WriteAll("b", "c")
+		Expect(writer.batch).To(Equal([]interface{}{"a", "b", "c"}))
+	})
+
+	It("writes a small batch once it waited long enough", func() {
+		b.Write("a")
+		clock.Advance(5 * time.Minute)
+
+		Expect(b.Flush().Written).To(BeTrue())
+	})
+
+	It("still honors forced flushes", func() {
+		b.Write("a")
+
+		Expect(b.ForcedFlush().Written).To(BeTrue())
+	})
+})
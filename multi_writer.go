@@ -0,0 +1,63 @@
+package batching
+
+import (
+	"context"
+	"errors"
+)
+
+// FanOutPolicy decides how the errors of the writers of a MultiWriter
+// combine into the error of the write.
+type FanOutPolicy int
+
+const (
+	// RequireAll fails the write if any writer fails, returning the errors
+	// of all writers that failed joined together.
+	RequireAll FanOutPolicy = iota
+
+	// RequireAny only fails the write if every writer fails.
+	RequireAny
+
+	// RequireFirst only fails the write if the first writer fails, for
+	// example a primary drain, ignoring the errors of the others, such as
+	// archival sinks.
+	RequireFirst
+)
+
+// MultiWriter returns a ContextWriter that writes every batch to all the
+// writers in turn, for example to both a primary drain and an archival sink.
+// Every writer is passed the batch even if a previous one failed. If the
+// write fails and the RetryPolicy retains the batch, it is written to all
+// writers again, including the ones that succeeded.
+func MultiWriter(policy FanOutPolicy, writers ...ContextWriter) ContextWriter {
+	return multiWriter{policy: policy, writers: writers}
+}
+
+type multiWriter struct {
+	policy  FanOutPolicy
+	writers []ContextWriter
+}
+
+func (m multiWriter) Write(ctx context.Context, batch []interface{}) error {
+	errs := make([]error, 0, len(m.writers))
+	failed := 0
+	for _, w := range m.writers {
+		err := w.Write(ctx, batch)
+		if err != nil {
+			failed++
+		}
+		errs = append(errs, err)
+	}
+	if failed == 0 {
+		return nil
+	}
+
+	switch m.policy {
+	case RequireAny:
+		if failed < len(m.writers) {
+			return nil
+		}
+	case RequireFirst:
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,75 @@
+package batching_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("MultiWriter", func() {
+	var (
+		errPrimary = errors.New("primary failed")
+		errArchive = errors.New("archive failed")
+	)
+
+	writer := func(err error, written *[]interface{}) batching.ContextWriter {
+		return batching.ContextWriterFunc(func(_ context.Context, batch []interface{}) error {
+			*written = append(*written, batch...)
+			return err
+		})
+	}
+
+	It("writes every batch to all writers", func() {
+		var primary, archive []interface{}
+		b := batching.NewContextBatcher(2, time.Minute, batching.MultiWriter(batching.RequireAll,
+			writer(nil, &primary),
+			writer(nil, &archive),
+		))
+		b.WriteAll("a", "b")
+
+		Expect(primary).To(Equal([]interface{}{"a", "b"}))
+		Expect(archive).To(Equal([]interface{}{"a", "b"}))
+	})
+
+	It("passes the batch to the remaining writers if one fails", func() {
+		var primary, archive []interface{}
+		w := batching.MultiWriter(batching.RequireAll,
+			writer(errPrimary, &primary),
+			writer(nil, &archive),
+		)
+
+		Expect(w.Write(context.Background(), []interface{}{"a"})).To(MatchError(errPrimary))
+		Expect(archive).To(Equal([]interface{}{"a"}))
+	})
+
+	DescribeTable("combines the errors according to the policy",
+		func(policy batching.FanOutPolicy, primaryErr, archiveErr error, matchers ...error) {
+			var primary, archive []interface{}
+			w := batching.MultiWriter(policy,
+				writer(primaryErr, &primary),
+				writer(archiveErr, &archive),
+			)
+
+			err := w.Write(context.Background(), []interface{}{"a"})
+
+			if len(matchers) == 0 {
+				Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			for _, m := range matchers {
+				Expect(err).To(MatchError(m))
+			}
+		},
+		Entry("RequireAll with one failure", batching.RequireAll, nil, errArchive, errArchive),
+		Entry("RequireAll with all failures", batching.RequireAll, errPrimary, errArchive, errPrimary, errArchive),
+		Entry("RequireAny with one failure", batching.RequireAny, errPrimary, nil),
+		Entry("RequireAny with all failures", batching.RequireAny, errPrimary, errArchive, errPrimary, errArchive),
+		Entry("RequireFirst with the others failing", batching.RequireFirst, nil, errArchive),
+		Entry("RequireFirst with the first failing", batching.RequireFirst, errPrimary, nil, errPrimary),
+	)
+})
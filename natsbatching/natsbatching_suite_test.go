@@ -0,0 +1,13 @@
+package natsbatching_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestNatsbatching(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Natsbatching Suite")
+}
@@ -0,0 +1,158 @@
+// Package natsbatching publishes batches to NATS JetStream through a
+// Publisher, which adapts the JetStream client in use.
+package natsbatching
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"text/template"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+// Publisher publishes messages to JetStream asynchronously, typically by
+// wrapping the PublishAsync method of a JetStream context.
+type Publisher interface {
+	// PublishAsync publishes data to subject and calls done with the
+	// result of the acknowledgement, nil once the message has been
+	// acknowledged by the stream. done is not called if PublishAsync
+	// returns an error.
+	PublishAsync(subject string, data []byte, done func(err error)) error
+}
+
+// PublisherFunc is an adapter to allow ordinary functions to be a
+// Publisher.
+type PublisherFunc func(subject string, data []byte, done func(err error)) error
+
+// PublishAsync implements Publisher.
+func (f PublisherFunc) PublishAsync(subject string, data []byte, done func(err error)) error {
+	return f(subject, data, done)
+}
+
+// Option configures the writer returned by NewWriter.
+type Option func(w *writer)
+
+// WithEncoder sets the function that encodes each element to the data of
+// its message. By default a []byte is used as is and anything else is
+// encoded as JSON. Since batches are written again until they are
+// acknowledged, the function should not fail for the elements written to
+// the Batcher.
+func WithEncoder(encodeFn func(data interface{}) ([]byte, error)) Option {
+	return func(w *writer) {
+		w.encodeFn = encodeFn
+	}
+}
+
+// NewWriter returns a batching.AckWriter, to be used with
+// batching.NewAckBatcher, that publishes every element of the batch as a
+// message and acknowledges the batch once every message has been
+// acknowledged by the stream, so that batches are delivered at least once.
+// If any message fails, the whole batch is written again.
+//
+// subject is a text/template that is executed once per batch with the
+// first element of the batch, for instance "logs.{{.SourceID}}". It is
+// meant for batches whose elements share the fields used by the template,
+// such as the batches of a KeyedBatcher. A subject without any actions is
+// used as is.
+func NewWriter(p Publisher, subject string, opts ...Option) (batching.AckWriter, error) {
+	w := &writer{
+		publisher: p,
+		subject:   subject,
+		encodeFn:  encodeData,
+	}
+	if strings.Contains(subject, "{{") {
+		tmpl, err := template.New("subject").Option("missingkey=error").Parse(subject)
+		if err != nil {
+			return nil, err
+		}
+		w.tmpl = tmpl
+	}
+	for _, o := range opts {
+		o(w)
+	}
+
+	return w, nil
+}
+
+type writer struct {
+	publisher Publisher
+	subject   string
+	tmpl      *template.Template
+	encodeFn  func(data interface{}) ([]byte, error)
+}
+
+func (w *writer) Write(batch []interface{}, ack func(err error)) {
+	if len(batch) == 0 {
+		ack(nil)
+		return
+	}
+
+	subject, err := w.subjectOf(batch)
+	if err != nil {
+		ack(err)
+		return
+	}
+	msgs := make([][]byte, 0, len(batch))
+	for _, data := range batch {
+		msg, err := w.encodeFn(data)
+		if err != nil {
+			ack(err)
+			return
+		}
+		msgs = append(msgs, msg)
+	}
+
+	// pending counts the messages that were not acknowledged yet, plus one
+	// until every message has been published.
+	var (
+		mu       sync.Mutex
+		pending  = 1
+		firstErr error
+	)
+	done := func(err error) {
+		mu.Lock()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		pending--
+		last := pending == 0
+		err = firstErr
+		mu.Unlock()
+
+		if last {
+			ack(err)
+		}
+	}
+
+	for _, msg := range msgs {
+		mu.Lock()
+		pending++
+		mu.Unlock()
+
+		if err := w.publisher.PublishAsync(subject, msg, done); err != nil {
+			done(err)
+			break
+		}
+	}
+	done(nil)
+}
+
+func (w *writer) subjectOf(batch []interface{}) (string, error) {
+	if w.tmpl == nil {
+		return w.subject, nil
+	}
+
+	var b strings.Builder
+	if err := w.tmpl.Execute(&b, batch[0]); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func encodeData(data interface{}) ([]byte, error) {
+	if b, ok := data.([]byte); ok {
+		return b, nil
+	}
+	return json.Marshal(data)
+}
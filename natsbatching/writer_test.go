@@ -0,0 +1,149 @@
+package natsbatching_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+	"code.cloudfoundry.org/go-batching/natsbatching"
+)
+
+type published struct {
+	subject string
+	data    string
+	done    func(err error)
+}
+
+type fakePublisher struct {
+	mu        sync.Mutex
+	published []published
+	err       error
+}
+
+func (p *fakePublisher) PublishAsync(subject string, data []byte, done func(err error)) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+	p.published = append(p.published, published{subject: subject, data: string(data), done: done})
+	return nil
+}
+
+func (p *fakePublisher) ackAll(err error) {
+	p.mu.Lock()
+	msgs := p.published
+	p.published = nil
+	p.mu.Unlock()
+
+	for _, msg := range msgs {
+		msg.done(err)
+	}
+}
+
+type logLine struct {
+	SourceID string
+	Message  string
+}
+
+var _ = Describe("Writer", func() {
+	var publisher *fakePublisher
+
+	BeforeEach(func() {
+		publisher = &fakePublisher{}
+	})
+
+	It("acknowledges batches once every message has been acknowledged", func() {
+		w, err := natsbatching.NewWriter(publisher, "logs")
+		Expect(err).ToNot(HaveOccurred())
+		b := batching.NewAckBatcher(2, time.Minute, w)
+
+		b.Write([]byte("item"))
+		b.Write(map[string]int{"a": 1})
+
+		Expect(publisher.published).To(HaveLen(2))
+		Expect(publisher.published[0].subject).To(Equal("logs"))
+		Expect(publisher.published[0].data).To(Equal("item"))
+		Expect(publisher.published[1].data).To(Equal(`{"a":1}`))
+		Expect(b.Unacked()).To(Equal(2))
+
+		publisher.ackAll(nil)
+		Expect(b.Unacked()).To(BeZero())
+		Expect(b.Len()).To(BeZero())
+	})
+
+	It("writes the batch again if any message fails", func() {
+		w, err := natsbatching.NewWriter(publisher, "logs")
+		Expect(err).ToNot(HaveOccurred())
+		b := batching.NewAckBatcher(2, time.Minute, w)
+
+		b.Write([]byte("a"))
+		b.Write([]byte("b"))
+		publisher.published[0].done(nil)
+		publisher.published[1].done(errors.New("no responders"))
+
+		Expect(b.Unacked()).To(BeZero())
+		Expect(b.Peek()).To(Equal([]interface{}{[]byte("a"), []byte("b")}))
+	})
+
+	It("fails the batch if publishing fails", func() {
+		var ackErr error
+		w, err := natsbatching.NewWriter(publisher, "logs")
+		Expect(err).ToNot(HaveOccurred())
+		publisher.err = errors.New("connection closed")
+
+		w.Write([]interface{}{[]byte("a")}, func(err error) {
+			ackErr = err
+		})
+
+		Expect(ackErr).To(MatchError("connection closed"))
+	})
+
+	It("only acknowledges once the messages published before a failure are acknowledged", func() {
+		acks := 0
+		var ackErr error
+		w, err := natsbatching.NewWriter(natsbatching.PublisherFunc(func(subject string, data []byte, done func(error)) error {
+			if string(data) == "b" {
+				return errors.New("connection closed")
+			}
+			return publisher.PublishAsync(subject, data, done)
+		}), "logs")
+		Expect(err).ToNot(HaveOccurred())
+
+		w.Write([]interface{}{[]byte("a"), []byte("b"), []byte("c")}, func(err error) {
+			acks++
+			ackErr = err
+		})
+		Expect(acks).To(BeZero())
+
+		publisher.ackAll(nil)
+		Expect(acks).To(Equal(1))
+		Expect(ackErr).To(MatchError("connection closed"))
+	})
+
+	It("executes the subject template with the first element of the batch", func() {
+		w, err := natsbatching.NewWriter(publisher, "logs.{{.SourceID}}",
+			natsbatching.WithEncoder(func(data interface{}) ([]byte, error) {
+				return []byte(data.(logLine).Message), nil
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		w.Write([]interface{}{logLine{SourceID: "app-1", Message: "hello"}}, func(error) {})
+
+		Expect(publisher.published).To(HaveLen(1))
+		Expect(publisher.published[0].subject).To(Equal("logs.app-1"))
+		Expect(publisher.published[0].data).To(Equal("hello"))
+	})
+
+	It("rejects invalid subject templates", func() {
+		_, err := natsbatching.NewWriter(publisher, "logs.{{.SourceID")
+
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,83 @@
+package batching_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("NextFlushTime", func() {
+	var clock *fakeClock
+
+	BeforeEach(func() {
+		clock = &fakeClock{now: time.Unix(0, 0)}
+	})
+
+	It("returns when the interval lapses", func() {
+		b := batching.NewBatcher(10, time.Second, &spyWriter{},
+			batching.WithClock(clock),
+		)
+		clock.Advance(300 * time.Millisecond)
+		b.Write("item")
+
+		next := b.NextFlushTime()
+		Expect(next).To(Equal(time.Unix(1, 0)))
+
+		clock.now = next
+		Expect(b.Flush().Written).To(BeTrue())
+		Expect(b.NextFlushTime()).To(Equal(time.Unix(2, 0)))
+	})
+
+	It("returns when the oldest data reaches the maximum age", func() {
+		clock := &fakeTimerClock{fakeClock: fakeClock{now: time.Unix(0, 0)}}
+		b := batching.NewBatcher(10, time.Second, &spyWriter{},
+			batching.WithClock(clock),
+			batching.WithMaxItemAge(200*time.Millisecond),
+		)
+		Expect(b.NextFlushTime()).To(Equal(time.Unix(1, 0)))
+
+		b.Write("item")
+
+		Expect(b.NextFlushTime()).To(Equal(time.Unix(0, int64(200*time.Millisecond))))
+	})
+
+	It("returns when the batch is debounced", func() {
+		b := batching.NewBatcher(10, time.Second, &spyWriter{},
+			batching.WithClock(clock),
+			batching.WithDebounce(100*time.Millisecond, 250*time.Millisecond),
+		)
+		b.Write("item")
+		Expect(b.NextFlushTime()).To(Equal(clock.now.Add(100 * time.Millisecond)))
+
+		clock.Advance(90 * time.Millisecond)
+		b.Write("item")
+		clock.Advance(90 * time.Millisecond)
+		b.Write("item")
+
+		Expect(b.NextFlushTime()).To(Equal(time.Unix(0, int64(250*time.Millisecond))))
+	})
+
+	It("returns the next scheduled time", func() {
+		b := batching.NewBatcher(10, time.Hour, &spyWriter{},
+			batching.WithClock(clock),
+			batching.WithSchedule(func(after time.Time) time.Time {
+				return after.Truncate(time.Minute).Add(time.Minute)
+			}),
+		)
+
+		Expect(b.NextFlushTime()).To(Equal(time.Unix(60, 0)))
+	})
+
+	It("returns a time in the past if the batch is due", func() {
+		b := batching.NewBatcher(10, time.Second, &spyWriter{},
+			batching.WithClock(clock),
+		)
+		b.Write("item")
+		clock.Advance(2 * time.Second)
+
+		Expect(b.NextFlushTime()).To(BeTemporally("<", clock.now))
+	})
+})
@@ -0,0 +1,21 @@
+package batching
+
+import (
+	"context"
+	"time"
+)
+
+// FlushIfOlderThan writes the batch if its oldest element has been pending
+// for at least d, regardless of the interval. Otherwise it is a NOP. This
+// allows a single loop to drive several batchers with different urgency.
+func (b *Batcher) FlushIfOlderThan(d time.Duration) FlushResult {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.batch) == 0 || b.clock.Since(b.firstItemAt) < d {
+		return FlushResult{}
+	}
+
+	res, _ := b.writeBatch(context.Background(), FlushAge)
+	return res
+}
@@ -0,0 +1,35 @@
+package batching_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("FlushIfOlderThan", func() {
+	It("writes the batch once the oldest element is old enough", func() {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		writer := &spyWriter{}
+		b := batching.NewBatcher(10, time.Hour, writer, batching.WithClock(clock))
+
+		b.Write("item")
+		clock.Advance(5 * time.Second)
+		b.Write("other-item")
+
+		Expect(b.FlushIfOlderThan(10 * time.Second).Written).To(BeFalse())
+
+		clock.Advance(5 * time.Second)
+		Expect(b.FlushIfOlderThan(10 * time.Second)).To(Equal(batching.FlushResult{Written: true, Items: 2, Reason: batching.FlushAge}))
+	})
+
+	It("does nothing without pending data", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(10, time.Hour, writer)
+
+		Expect(b.FlushIfOlderThan(0).Written).To(BeFalse())
+		Expect(writer.called).To(Equal(0))
+	})
+})
@@ -0,0 +1,73 @@
+package batching
+
+import (
+	"sync"
+	"time"
+)
+
+// Option configures optional behavior of a Batcher. Options are applied in
+// order after the Batcher has been created with its defaults.
+type Option func(b *Batcher)
+
+// WithLocking makes the Batcher's methods safe to call from multiple
+// goroutines. The Writer is invoked while the Batcher is locked, so it must
+// not call back into the Batcher.
+func WithLocking() Option {
+	return func(b *Batcher) {
+		b.mu = &sync.Mutex{}
+	}
+}
+
+// ensureLocking makes a Batcher that was not created WithLocking safe for
+// concurrent use. It must be called before the Batcher is shared between
+// goroutines.
+func (b *Batcher) ensureLocking() {
+	if _, ok := b.mu.(nopLocker); ok {
+		b.mu = &sync.Mutex{}
+	}
+}
+
+// WithSize overrides the batch size passed to the constructor. This allows
+// the size to be configured alongside the other options, for instance when
+// the options are built from configuration.
+func WithSize(size int) Option {
+	return func(b *Batcher) {
+		b.size = size
+	}
+}
+
+// WithInterval overrides the flush interval passed to the constructor. See
+// WithSize.
+func WithInterval(interval time.Duration) Option {
+	return func(b *Batcher) {
+		b.interval = interval
+	}
+}
+
+// WithMaxBytes submits the batch once adding to it would exceed maxBytes, as
+// measured by the size function configured with WithSizeFunc. A single
+// element larger than maxBytes is written in a batch of its own.
+func WithMaxBytes(maxBytes int) Option {
+	return func(b *Batcher) {
+		b.maxBytes = maxBytes
+	}
+}
+
+// WithSizeFunc sets the function used to measure the size of each element in
+// bytes. ByteBatchers default to the length of each slice.
+func WithSizeFunc(sizeFn func(data interface{}) int) Option {
+	return func(b *Batcher) {
+		b.sizeFn = sizeFn
+	}
+}
+
+// WithWeightFunc sets the function used to measure the weight of each
+// element. When set, the batch is submitted once the total weight of its
+// elements reaches the batch size instead of once the number of elements
+// does. This allows the size to be expressed in arbitrary cost units, such as
+// the number of rows an element represents.
+func WithWeightFunc(weightFn func(data interface{}) int) Option {
+	return func(b *Batcher) {
+		b.weightFn = weightFn
+	}
+}
@@ -0,0 +1,99 @@
+package batching_test
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Options", func() {
+	It("applies WithMaxBytes and WithSizeFunc", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(10, time.Minute, writer,
+			batching.WithMaxBytes(4),
+			batching.WithSizeFunc(strLen),
+		)
+
+		b.Write("12")
+		b.Write("34")
+
+		Expect(writer.called).To(Equal(1))
+		Expect(writer.batch).To(Equal([]interface{}{"12", "34"}))
+	})
+
+	It("defaults the size function of a ByteBatcher to the slice length", func() {
+		writer := &spyByteWriter{}
+		b := batching.NewByteBatcher(10, time.Minute, writer, batching.WithMaxBytes(4))
+
+		b.Write([]byte("12"))
+		b.Write([]byte("34"))
+
+		Expect(writer.called).To(Equal(1))
+	})
+
+	It("applies WithLocking", func() {
+		writer := &countingWriter{}
+		b := batching.NewBatcher(10, time.Minute, writer, batching.WithLocking())
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					b.Write(j)
+				}
+			}()
+		}
+		wg.Wait()
+
+		Expect(writer.items()).To(Equal(1000))
+	})
+
+	It("applies WithWeightFunc", func() {
+		writer := &recordingWriter{}
+		b := batching.NewBatcher(5, time.Minute, writer, batching.WithWeightFunc(strLen))
+
+		b.Write("12")
+		b.Write("34")
+		Expect(writer.batches).To(BeEmpty())
+
+		b.Write("5")
+		b.WriteAll("123", "45", "6")
+
+		Expect(writer.batches).To(Equal([][]interface{}{
+			{"12", "34", "5"},
+			{"123", "45"},
+		}))
+		Expect(b.Len()).To(Equal(1))
+	})
+
+	It("applies WithSize", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(10, time.Minute, writer, batching.WithSize(2))
+
+		b.Write("a")
+		b.Write("b")
+
+		Expect(writer.batch).To(Equal([]interface{}{"a", "b"}))
+	})
+
+	It("applies WithInterval", func() {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		writer := &spyWriter{}
+		b := batching.NewBatcher(10, time.Hour, writer,
+			batching.WithClock(clock),
+			batching.WithInterval(time.Second),
+		)
+
+		b.Write("a")
+		clock.Advance(time.Second)
+		b.Flush()
+
+		Expect(writer.batch).To(Equal([]interface{}{"a"}))
+	})
+})
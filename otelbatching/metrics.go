@@ -0,0 +1,97 @@
+package otelbatching
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+// Metrics is a batching.Metrics that records the metrics of a Batcher with
+// OpenTelemetry instruments. Metrics should be created with NewMetrics().
+type Metrics struct {
+	attrs []attribute.KeyValue
+
+	flushes       metric.Float64Counter
+	dropped       metric.Float64Counter
+	pending       metric.Float64Gauge
+	batchSize     metric.Float64Histogram
+	flushDuration metric.Float64Histogram
+}
+
+// NewMetrics creates new Metrics that record with instruments created by
+// meter. attrs are added to every measurement, for example to tell several
+// batchers apart.
+func NewMetrics(meter metric.Meter, attrs ...attribute.KeyValue) (*Metrics, error) {
+	m := &Metrics{attrs: attrs}
+
+	var err error
+	if m.flushes, err = meter.Float64Counter(batching.MetricFlushes,
+		metric.WithDescription("Number of times the writer was invoked, by reason and outcome."),
+	); err != nil {
+		return nil, err
+	}
+	if m.dropped, err = meter.Float64Counter(batching.MetricDropped,
+		metric.WithDescription("Number of elements dropped without writing them."),
+	); err != nil {
+		return nil, err
+	}
+	if m.pending, err = meter.Float64Gauge(batching.MetricPending,
+		metric.WithDescription("Number of elements waiting to be written."),
+	); err != nil {
+		return nil, err
+	}
+	if m.batchSize, err = meter.Float64Histogram(batching.MetricBatchSize,
+		metric.WithDescription("Number of elements submitted per invocation of the writer."),
+	); err != nil {
+		return nil, err
+	}
+	if m.flushDuration, err = meter.Float64Histogram(batching.MetricFlushDuration,
+		metric.WithDescription("Time the writer took to write a batch."),
+		metric.WithUnit("s"),
+	); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Counter implements batching.Metrics.
+func (m *Metrics) Counter(name string, delta float64, labels map[string]string) {
+	opt := m.attributes(labels)
+	switch name {
+	case batching.MetricFlushes:
+		m.flushes.Add(context.Background(), delta, opt)
+	case batching.MetricDropped:
+		m.dropped.Add(context.Background(), delta, opt)
+	}
+}
+
+// Gauge implements batching.Metrics.
+func (m *Metrics) Gauge(name string, value float64, labels map[string]string) {
+	if name == batching.MetricPending {
+		m.pending.Record(context.Background(), value, m.attributes(labels))
+	}
+}
+
+// Histogram implements batching.Metrics.
+func (m *Metrics) Histogram(name string, value float64, labels map[string]string) {
+	opt := m.attributes(labels)
+	switch name {
+	case batching.MetricBatchSize:
+		m.batchSize.Record(context.Background(), value, opt)
+	case batching.MetricFlushDuration:
+		m.flushDuration.Record(context.Background(), value, opt)
+	}
+}
+
+func (m *Metrics) attributes(labels map[string]string) metric.MeasurementOption {
+	attrs := make([]attribute.KeyValue, 0, len(m.attrs)+len(labels))
+	attrs = append(attrs, m.attrs...)
+	for k, v := range labels {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+	return metric.WithAttributes(attrs...)
+}
@@ -0,0 +1,59 @@
+package otelbatching_test
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+	"code.cloudfoundry.org/go-batching/otelbatching"
+)
+
+var _ = Describe("Metrics", func() {
+	It("records the metrics of a Batcher", func() {
+		reader := sdkmetric.NewManualReader()
+		meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+		m, err := otelbatching.NewMetrics(meter, attribute.String("batcher", "logs"))
+		Expect(err).NotTo(HaveOccurred())
+
+		b := batching.NewBatcher(2, time.Minute, batching.WriterFunc(func([]interface{}) {}),
+			batching.WithMetrics(m),
+		)
+		b.WriteAll("a", "b", "c")
+
+		var rm metricdata.ResourceMetrics
+		Expect(reader.Collect(context.Background(), &rm)).To(Succeed())
+		byName := make(map[string]metricdata.Aggregation)
+		for _, sm := range rm.ScopeMetrics {
+			for _, metric := range sm.Metrics {
+				byName[metric.Name] = metric.Data
+			}
+		}
+
+		Expect(byName).To(HaveKey(batching.MetricFlushes))
+		flushes := byName[batching.MetricFlushes].(metricdata.Sum[float64])
+		Expect(flushes.DataPoints).To(HaveLen(1))
+		Expect(flushes.DataPoints[0].Value).To(Equal(1.0))
+		attrs := flushes.DataPoints[0].Attributes
+		Expect(attrs.Equals(ptr(attribute.NewSet(
+			attribute.String("batcher", "logs"),
+			attribute.String("reason", "size"),
+			attribute.String("outcome", "success"),
+		)))).To(BeTrue())
+
+		pending := byName[batching.MetricPending].(metricdata.Gauge[float64])
+		Expect(pending.DataPoints[0].Value).To(Equal(1.0))
+		Expect(byName).To(HaveKey(batching.MetricBatchSize))
+		Expect(byName).To(HaveKey(batching.MetricFlushDuration))
+	})
+})
+
+func ptr[T any](v T) *T {
+	return &v
+}
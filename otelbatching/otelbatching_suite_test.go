@@ -0,0 +1,13 @@
+package otelbatching_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestOtelbatching(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Otelbatching Suite")
+}
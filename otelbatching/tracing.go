@@ -0,0 +1,116 @@
+// Package otelbatching instruments batchers with OpenTelemetry, recording
+// their metrics and wrapping every write in a span.
+package otelbatching
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+// SpanName is the name of the span wrapping every write.
+const SpanName = "batching.write"
+
+// Attributes recorded on the span wrapping every write.
+const (
+	// AttributeBatchSize is the number of elements in the batch.
+	AttributeBatchSize = attribute.Key("batching.batch.size")
+
+	// AttributeBatchSequence is the sequence number of the batch, if the
+	// Batcher stamps batches. See batching.WithBatchInfo.
+	AttributeBatchSequence = attribute.Key("batching.batch.sequence")
+
+	// AttributeOutcome is "success" or "error".
+	AttributeOutcome = attribute.Key("batching.outcome")
+)
+
+// Option configures the Middleware returned by Tracing.
+type Option func(t *tracing)
+
+// WithItemSpanContext links the span of every write to the span context
+// spanContext returns for each element of the batch, so that the traces of
+// the requests that produced the elements lead to the write. Elements
+// without a valid span context are not linked. Without it, the span is
+// linked to the spans of the item contexts collected by a Batcher created
+// with batching.WithItemContexts.
+func WithItemSpanContext(spanContext func(data interface{}) trace.SpanContext) Option {
+	return func(t *tracing) {
+		t.spanContext = spanContext
+	}
+}
+
+// Tracing returns a batching.Middleware that wraps every write in a span
+// started with tracer, recording the size of the batch and the outcome of
+// the write.
+func Tracing(tracer trace.Tracer, opts ...Option) batching.Middleware {
+	t := &tracing{tracer: tracer}
+	for _, o := range opts {
+		o(t)
+	}
+
+	return func(next batching.ContextWriter) batching.ContextWriter {
+		return batching.ContextWriterFunc(func(ctx context.Context, batch []interface{}) error {
+			return t.write(ctx, next, batch)
+		})
+	}
+}
+
+type tracing struct {
+	tracer      trace.Tracer
+	spanContext func(data interface{}) trace.SpanContext
+}
+
+func (t *tracing) write(ctx context.Context, next batching.ContextWriter, batch []interface{}) error {
+	attrs := []attribute.KeyValue{AttributeBatchSize.Int(len(batch))}
+	if info, ok := batching.BatchInfoFromContext(ctx); ok {
+		attrs = append(attrs, AttributeBatchSequence.Int64(int64(info.Sequence)))
+	}
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attrs...),
+	}
+	if links := t.links(ctx, batch); len(links) > 0 {
+		opts = append(opts, trace.WithLinks(links...))
+	}
+
+	ctx, span := t.tracer.Start(ctx, SpanName, opts...)
+	defer span.End()
+
+	err := next.Write(ctx, batch)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(AttributeOutcome.String("error"))
+		return err
+	}
+	span.SetAttributes(AttributeOutcome.String("success"))
+	return nil
+}
+
+// links returns the links to the spans of the elements of the batch. Every
+// span is only linked once, even if it produced several elements.
+func (t *tracing) links(ctx context.Context, batch []interface{}) []trace.Link {
+	var links []trace.Link
+	seen := make(map[trace.SpanID]bool)
+	link := func(sc trace.SpanContext) {
+		if sc.IsValid() && !seen[sc.SpanID()] {
+			seen[sc.SpanID()] = true
+			links = append(links, trace.Link{SpanContext: sc})
+		}
+	}
+
+	if t.spanContext != nil {
+		for _, data := range batch {
+			link(t.spanContext(data))
+		}
+		return links
+	}
+	for _, itemCtx := range batching.ItemContextsFromContext(ctx) {
+		link(trace.SpanContextFromContext(itemCtx))
+	}
+	return links
+}
@@ -0,0 +1,110 @@
+package otelbatching_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+	"code.cloudfoundry.org/go-batching/otelbatching"
+)
+
+var _ = Describe("Tracing", func() {
+	var (
+		recorder *tracetest.SpanRecorder
+		tracer   trace.Tracer
+	)
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	})
+
+	ok := batching.ContextWriterFunc(func(context.Context, []interface{}) error { return nil })
+
+	It("wraps every write in a span", func() {
+		b := batching.NewContextBatcher(2, time.Minute,
+			batching.ChainWriters(ok, otelbatching.Tracing(tracer)),
+			batching.WithBatchInfo(),
+		)
+		b.WriteAll("a", "b")
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name()).To(Equal(otelbatching.SpanName))
+		Expect(spans[0].Attributes()).To(ConsistOf(
+			otelbatching.AttributeBatchSize.Int(2),
+			otelbatching.AttributeBatchSequence.Int64(1),
+			otelbatching.AttributeOutcome.String("success"),
+		))
+	})
+
+	It("records the error of the write", func() {
+		writeErr := errors.New("failed")
+		w := batching.ChainWriters(
+			batching.ContextWriterFunc(func(context.Context, []interface{}) error { return writeErr }),
+			otelbatching.Tracing(tracer),
+		)
+
+		Expect(w.Write(context.Background(), []interface{}{"a"})).To(MatchError(writeErr))
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Status().Code).To(Equal(codes.Error))
+		Expect(spans[0].Attributes()).To(ContainElement(otelbatching.AttributeOutcome.String("error")))
+	})
+
+	It("links the spans of the elements", func() {
+		_, item := tracer.Start(context.Background(), "item")
+		item.End()
+		w := batching.ChainWriters(ok, otelbatching.Tracing(tracer,
+			otelbatching.WithItemSpanContext(func(data interface{}) trace.SpanContext {
+				if data == "traced" {
+					return item.SpanContext()
+				}
+				return trace.SpanContext{}
+			}),
+		))
+
+		Expect(w.Write(context.Background(), []interface{}{"traced", "untraced"})).To(Succeed())
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(2))
+		Expect(spans[1].Links()).To(HaveLen(1))
+		Expect(spans[1].Links()[0].SpanContext).To(Equal(item.SpanContext()))
+	})
+
+	It("links the spans of the item contexts", func() {
+		itemCtx, item := tracer.Start(context.Background(), "item")
+		item.End()
+		b := batching.NewContextBatcher(3, time.Minute,
+			batching.ChainWriters(ok, otelbatching.Tracing(tracer)),
+			batching.WithItemContexts(),
+		)
+
+		b.Write(carrier{ctx: itemCtx})
+		b.Write(carrier{ctx: itemCtx})
+		b.Write(carrier{ctx: context.Background()})
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(2))
+		Expect(spans[1].Links()).To(HaveLen(1))
+		Expect(spans[1].Links()[0].SpanContext).To(Equal(item.SpanContext()))
+	})
+})
+
+type carrier struct {
+	ctx context.Context
+}
+
+func (c carrier) Context() context.Context {
+	return c.ctx
+}
@@ -0,0 +1,128 @@
+package batching
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrOverflow is returned when data is rejected because the pending limit
+// has been reached.
+var ErrOverflow = errors.New("batching: pending limit reached")
+
+// OverflowPolicy decides what happens when data is written to a Batcher that
+// already holds as many pending elements as its pending limit allows.
+type OverflowPolicy int
+
+const (
+	// Reject refuses the new element.
+	Reject OverflowPolicy = iota
+
+	// DropOldest drops the element that has been pending the longest to
+	// make room for the new element.
+	DropOldest
+
+	// DropNewest drops the element that was most recently stored to make
+	// room for the new element.
+	DropNewest
+)
+
+// WithPendingLimit bounds the number of pending elements to limit. This only
+// matters when the pending batch can grow beyond the batch size, for
+// instance when a RetryPolicy retains batches the writer fails to write. The
+// policy decides what happens to data written once the limit is reached.
+func WithPendingLimit(limit int, policy OverflowPolicy) Option {
+	return func(b *Batcher) {
+		b.pendingLimit = limit
+		b.overflowPolicy = policy
+	}
+}
+
+// WithMaxPending bounds the number of pending elements to limit like
+// WithPendingLimit, without changing the overflow policy, and calls onDrop
+// with every element that is dropped because the limit has been reached.
+// With the default Reject policy the dropped element is the one being
+// written. onDrop is called in addition to the drop callback set by
+// WithOnDrop, which keeps being called for every dropped element.
+func WithMaxPending(limit int, onDrop func(dropped []interface{})) Option {
+	return func(b *Batcher) {
+		b.pendingLimit = limit
+		b.onOverflow = onDrop
+	}
+}
+
+// TryWrite is like Write but reports whether data was stored. It returns
+// false if the pending limit has been reached and the overflow policy is
+// Reject, or if the Batcher is closed.
+func (b *Batcher) TryWrite(data interface{}) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	err := b.write(context.Background(), data)
+	return !errors.Is(err, ErrOverflow) && !errors.Is(err, ErrClosed)
+}
+
+// makeRoom applies the overflow policy if the pending limit has been
+// reached. It returns ErrOverflow if the new element must be rejected.
+func (b *Batcher) makeRoom(data interface{}) error {
+	if b.pendingLimit <= 0 || len(b.batch) < b.pendingLimit {
+		return nil
+	}
+
+	switch b.overflowPolicy {
+	case DropOldest:
+		data = b.evict(0)
+	case DropNewest:
+		data = b.evict(len(b.batch) - 1)
+	default:
+		b.overflowed(data)
+		return ErrOverflow
+	}
+	b.overflowed(data)
+
+	return nil
+}
+
+// overflowed reports an element dropped because of the pending limit to the
+// overflow and drop callbacks.
+func (b *Batcher) overflowed(data interface{}) {
+	dropped := []interface{}{data}
+	if b.onOverflow != nil {
+		b.onOverflow(dropped)
+	}
+	b.dropped(dropped, DropOverflow)
+}
+
+// evict removes the element at index i from the pending batch and returns
+// it.
+func (b *Batcher) evict(i int) interface{} {
+	if i < b.retained {
+		b.retained = 0
+	}
+	data := b.batch[i]
+	b.pendingBytes -= b.sizeOf(data)
+	if b.weightFn != nil {
+		b.pendingWeight -= b.weightFn(data)
+	}
+
+	if i == 0 {
+		b.batch[0] = nil
+		b.batch = b.batch[1:]
+		return data
+	}
+	copy(b.batch[i:], b.batch[i+1:])
+	b.batch[len(b.batch)-1] = nil
+	b.batch = b.batch[:len(b.batch)-1]
+
+	return data
+}
+
+// writeEach writes every element on its own, returning the first error.
+func (b *Batcher) writeEach(ctx context.Context, data []interface{}) error {
+	var firstErr error
+	for _, d := range data {
+		if err := b.write(ctx, d); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,123 @@
+package batching_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Pending limit", func() {
+	var (
+		writer *spyFallibleWriter
+		retain batching.Option
+	)
+
+	BeforeEach(func() {
+		writer = &spyFallibleWriter{err: errors.New("failed")}
+		retain = batching.WithRetryPolicy(batching.RetainOnError(0))
+	})
+
+	It("rejects new elements", func() {
+		b := batching.NewFallibleBatcher(2, time.Minute, writer, retain,
+			batching.WithPendingLimit(3, batching.Reject),
+		)
+
+		Expect(b.TryWrite("a")).To(BeTrue())
+		Expect(b.TryWrite("b")).To(BeTrue())
+		Expect(b.TryWrite("c")).To(BeTrue())
+		Expect(b.TryWrite("d")).To(BeFalse())
+		Expect(b.WriteContext(context.Background(), "d")).To(MatchError(batching.ErrOverflow))
+
+		Expect(b.Discard()).To(Equal([]interface{}{"a", "b", "c"}))
+	})
+
+	It("drops the oldest element", func() {
+		b := batching.NewFallibleBatcher(2, time.Minute, writer, retain,
+			batching.WithPendingLimit(3, batching.DropOldest),
+		)
+
+		b.WriteAll("a", "b", "c", "d")
+
+		Expect(b.Discard()).To(Equal([]interface{}{"b", "c", "d"}))
+	})
+
+	It("drops the newest element", func() {
+		b := batching.NewFallibleBatcher(2, time.Minute, writer, retain,
+			batching.WithPendingLimit(3, batching.DropNewest),
+		)
+
+		b.WriteAll("a", "b", "c")
+		Expect(b.TryWrite("d")).To(BeTrue())
+
+		Expect(b.Discard()).To(Equal([]interface{}{"a", "b", "d"}))
+	})
+
+	It("keeps track of the size of dropped elements", func() {
+		b := batching.NewFallibleBatcher(2, time.Minute, writer, retain,
+			batching.WithPendingLimit(2, batching.DropOldest),
+			batching.WithSizeFunc(strLen),
+		)
+
+		b.WriteAll("aaa", "b", "cc")
+
+		Expect(b.PendingBytes()).To(Equal(3))
+	})
+
+	It("reports elements dropped because of the limit", func() {
+		var dropped []interface{}
+		b := batching.NewFallibleBatcher(2, time.Minute, writer, retain,
+			batching.WithMaxPending(2, func(d []interface{}) {
+				dropped = append(dropped, d...)
+			}),
+		)
+
+		b.WriteAll("a", "b", "c", "d")
+
+		Expect(dropped).To(Equal([]interface{}{"c", "d"}))
+		Expect(b.Len()).To(Equal(2))
+	})
+
+	It("reports elements dropped by the overflow policy", func() {
+		var dropped []interface{}
+		b := batching.NewFallibleBatcher(2, time.Minute, writer, retain,
+			batching.WithPendingLimit(2, batching.DropOldest),
+			batching.WithMaxPending(2, func(d []interface{}) {
+				dropped = append(dropped, d...)
+			}),
+		)
+
+		b.WriteAll("a", "b", "c", "d")
+
+		Expect(dropped).To(Equal([]interface{}{"a", "b"}))
+		Expect(b.Peek()).To(Equal([]interface{}{"c", "d"}))
+	})
+
+	It("keeps calling the drop callback", func() {
+		var overflowed, dropped []interface{}
+		b := batching.NewFallibleBatcher(2, time.Minute, writer, retain,
+			batching.WithOnDrop(func(d []interface{}) {
+				dropped = append(dropped, d...)
+			}),
+			batching.WithMaxPending(2, func(d []interface{}) {
+				overflowed = append(overflowed, d...)
+			}),
+		)
+
+		b.WriteAll("a", "b", "c")
+
+		Expect(overflowed).To(Equal([]interface{}{"c"}))
+		Expect(dropped).To(Equal([]interface{}{"c"}))
+	})
+
+	It("does not write when closed", func() {
+		b := batching.NewBatcher(2, time.Minute, &spyWriter{})
+		Expect(b.Close()).To(Succeed())
+
+		Expect(b.TryWrite("a")).To(BeFalse())
+	})
+})
@@ -0,0 +1,78 @@
+package batching
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// PartialWriter is used to submit the completed batch when the downstream
+// may accept only part of it, such as a Kafka or Kinesis producer reporting
+// failures for some records. Write returns the elements of the batch that
+// were not written, which are put back at the front of the pending batch to
+// be written with the next batch. If no elements are returned, what happens
+// to a batch that failed to write is decided by the Batcher's RetryPolicy.
+type PartialWriter interface {
+	// Write submits the batch and returns the elements that were not
+	// written.
+	Write(batch []interface{}) (unwritten []interface{}, err error)
+}
+
+// PartialWriterFunc is an adapter to allow ordinary functions to be a
+// PartialWriter.
+type PartialWriterFunc func(batch []interface{}) (unwritten []interface{}, err error)
+
+// Write implements PartialWriter.
+func (f PartialWriterFunc) Write(batch []interface{}) ([]interface{}, error) {
+	return f(batch)
+}
+
+// NewPartialBatcher creates a new Batcher that submits batches to a
+// PartialWriter. The unwritten elements are put back without applying the
+// RetryPolicy, and the flush stops so that they are only written again by
+// the next flush.
+func NewPartialBatcher(size int, interval time.Duration, writer PartialWriter, opts ...Option) *Batcher {
+	return newBatcher(size, interval, partialContextWriter{w: writer}, opts)
+}
+
+// partialContextWriter adapts a PartialWriter to a ContextWriter.
+type partialContextWriter struct {
+	w PartialWriter
+}
+
+func (w partialContextWriter) Write(_ context.Context, batch []interface{}) error {
+	unwritten, err := w.w.Write(batch)
+	if len(unwritten) > 0 {
+		return partialWriteError{unwritten: unwritten, err: err}
+	}
+	return err
+}
+
+// partialWriteError is returned by a ContextWriter that wrote only part of
+// the batch. err is the error of the write, if any.
+type partialWriteError struct {
+	unwritten []interface{}
+	err       error
+}
+
+func (e partialWriteError) Error() string {
+	if e.err == nil {
+		return "batching: batch partially written"
+	}
+	return e.err.Error()
+}
+
+func (e partialWriteError) Unwrap() error {
+	return e.err
+}
+
+// asPartialWrite is like errors.As for a partialWriteError, but does not
+// allocate if err is nil.
+func asPartialWrite(err error) (partialWriteError, bool) {
+	if err == nil {
+		return partialWriteError{}, false
+	}
+	var partial partialWriteError
+	ok := errors.As(err, &partial)
+	return partial, ok
+}
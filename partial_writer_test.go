@@ -0,0 +1,87 @@
+package batching_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("PartialWriter", func() {
+	var (
+		batches   [][]interface{}
+		unwritten [][]interface{}
+		errs      []error
+		writer    batching.PartialWriter
+	)
+
+	BeforeEach(func() {
+		batches, unwritten, errs = nil, nil, nil
+		writer = batching.PartialWriterFunc(func(batch []interface{}) ([]interface{}, error) {
+			batches = append(batches, batch)
+			var u []interface{}
+			var err error
+			if len(unwritten) > 0 {
+				u, unwritten = unwritten[0], unwritten[1:]
+			}
+			if len(errs) > 0 {
+				err, errs = errs[0], errs[1:]
+			}
+			return u, err
+		})
+	})
+
+	It("puts the unwritten elements at the front of the next batch", func() {
+		unwritten = [][]interface{}{{"b"}}
+		b := batching.NewPartialBatcher(2, time.Minute, writer)
+
+		b.WriteAll("a", "b")
+		Expect(b.Peek()).To(Equal([]interface{}{"b"}))
+
+		b.Write("c")
+
+		Expect(batches).To(Equal([][]interface{}{{"a", "b"}, {"b", "c"}}))
+		Expect(b.Stats().ItemsFlushed).To(Equal(uint64(3)))
+	})
+
+	It("returns the error of a partial write", func() {
+		writeErr := errors.New("partially failed")
+		unwritten = [][]interface{}{{"a"}}
+		errs = []error{writeErr}
+		b := batching.NewPartialBatcher(1, time.Minute, writer)
+		ctx := context.Background()
+
+		Expect(b.WriteContext(ctx, "a")).To(MatchError(writeErr))
+		Expect(b.Peek()).To(Equal([]interface{}{"a"}))
+	})
+
+	It("stops the flush after a partial write", func() {
+		b := batching.NewPartialBatcher(2, time.Minute, writer,
+			batching.WithRetryPolicy(batching.RetainOnError(0)),
+		)
+		errs = []error{errors.New("failed"), errors.New("failed")}
+		b.WriteAll("a", "b")
+		b.WriteAll("c", "d")
+		Expect(b.Len()).To(Equal(4))
+		batches = nil
+		unwritten = [][]interface{}{{"b"}}
+
+		b.ForcedFlush()
+
+		Expect(batches).To(Equal([][]interface{}{{"a", "b"}}))
+		Expect(b.Peek()).To(Equal([]interface{}{"b", "c", "d"}))
+	})
+
+	It("applies the RetryPolicy if no elements are returned", func() {
+		errs = []error{errors.New("failed")}
+		b := batching.NewPartialBatcher(1, time.Minute, writer)
+
+		b.Write("a")
+
+		Expect(b.Len()).To(Equal(0))
+	})
+})
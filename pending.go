@@ -0,0 +1,62 @@
+package batching
+
+import "slices"
+
+// Len returns the number of elements waiting to be written.
+func (b *Batcher) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.batch)
+}
+
+// PendingBytes returns the size of the elements waiting to be written, as
+// measured by the size function configured with WithSizeFunc. It is always
+// zero if no size function is configured.
+func (b *Batcher) PendingBytes() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.pendingBytes
+}
+
+// Peek returns a copy of the elements waiting to be written. The elements
+// themselves are not copied.
+func (b *Batcher) Peek() []interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]interface{}(nil), b.batch...)
+}
+
+// Discard drops the elements waiting to be written without writing them and
+// returns them.
+func (b *Batcher) Discard() []interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	dropped := b.batch
+	b.reset()
+	b.truncateWAL()
+
+	return dropped
+}
+
+// requeue puts batch back at the front of the pending batch.
+func (b *Batcher) requeue(batch []interface{}) {
+	if len(batch) == 0 {
+		return
+	}
+	if len(b.batch) == 0 {
+		b.firstItemAt = b.clock.Now()
+		b.startAgeTimer()
+	}
+
+	b.batch = append(slices.Clone(batch), b.batch...)
+	for _, data := range batch {
+		b.pendingBytes += b.sizeOf(data)
+		if b.weightFn != nil {
+			b.pendingWeight += b.weightFn(data)
+		}
+	}
+}
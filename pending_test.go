@@ -0,0 +1,90 @@
+package batching_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Pending data", func() {
+	It("reports the number of elements waiting to be written", func() {
+		b := batching.NewBatcher(3, time.Minute, &spyWriter{})
+		Expect(b.Len()).To(Equal(0))
+
+		b.Write("item")
+		b.Write("other-item")
+		Expect(b.Len()).To(Equal(2))
+
+		b.Write("another-item")
+		Expect(b.Len()).To(Equal(0))
+	})
+
+	It("reports the size of the elements waiting to be written", func() {
+		b := batching.NewByteBatcher(3, time.Minute, &spyByteWriter{})
+		Expect(b.PendingBytes()).To(Equal(0))
+
+		b.Write([]byte("item"))
+		b.Write([]byte("other-item"))
+		Expect(b.PendingBytes()).To(Equal(14))
+
+		b.ForcedFlush()
+		Expect(b.PendingBytes()).To(Equal(0))
+	})
+
+	It("reports no size without a size function", func() {
+		b := batching.NewBatcher(3, time.Minute, &spyWriter{})
+
+		b.Write("item")
+
+		Expect(b.PendingBytes()).To(Equal(0))
+	})
+
+	It("returns a copy of the elements waiting to be written", func() {
+		b := batching.NewBatcher(3, time.Minute, &spyWriter{})
+		Expect(b.Peek()).To(BeEmpty())
+
+		b.Write("item")
+		b.Write("other-item")
+
+		peeked := b.Peek()
+		Expect(peeked).To(Equal([]interface{}{"item", "other-item"}))
+
+		peeked[0] = "changed"
+		Expect(b.Peek()).To(Equal([]interface{}{"item", "other-item"}))
+		Expect(b.Len()).To(Equal(2))
+	})
+
+	It("returns the slices waiting to be written by a ByteBatcher", func() {
+		b := batching.NewByteBatcher(3, time.Minute, &spyByteWriter{})
+
+		b.Write([]byte("item"))
+
+		Expect(b.Peek()).To(Equal([][]byte{[]byte("item")}))
+	})
+
+	It("discards the elements waiting to be written", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(3, time.Minute, writer)
+
+		b.Write("item")
+		b.Write("other-item")
+
+		Expect(b.Discard()).To(Equal([]interface{}{"item", "other-item"}))
+		Expect(b.Len()).To(Equal(0))
+
+		b.ForcedFlush()
+		Expect(writer.called).To(Equal(0))
+	})
+
+	It("discards the slices waiting to be written by a ByteBatcher", func() {
+		b := batching.NewByteBatcher(3, time.Minute, &spyByteWriter{})
+
+		b.Write([]byte("item"))
+
+		Expect(b.Discard()).To(Equal([][]byte{[]byte("item")}))
+		Expect(b.PendingBytes()).To(Equal(0))
+	})
+})
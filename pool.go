@@ -0,0 +1,93 @@
+package batching
+
+import "sync"
+
+// WithBatchPool makes the Batcher reuse the backing arrays of batches that
+// are handed back with Release instead of allocating a new one for every
+// batch. Release is optional, batches that are never released are simply
+// garbage collected.
+func WithBatchPool() Option {
+	return func(b *Batcher) {
+		b.batchPool = &sync.Pool{}
+	}
+}
+
+// Release hands a batch that was submitted to the writer, or returned by
+// Discard, back to the Batcher so that its backing array can be reused. The
+// batch must not be used after it has been released. Release is a NOP unless
+// the Batcher was created WithBatchPool. It is safe to call Release from any
+// goroutine, including from within the writer.
+func (b *Batcher) Release(batch []interface{}) {
+	if b.batchPool == nil || cap(batch) == 0 {
+		return
+	}
+
+	batch = batch[:cap(batch)]
+	clear(batch)
+	batch = batch[:0]
+	b.batchPool.Put(&batch)
+}
+
+// WithBatchReuse makes the Batcher reuse the backing array of every batch
+// that was written successfully for the next batch, so that a new one is
+// only allocated when the batch outgrows it. The writer then no longer owns
+// the batches it is passed and must not retain or modify them once Write
+// returns, copying any data it needs later. Batches the writer failed to
+// write are never reused. WithBatchReuse is ignored by Batchers created
+// WithWriterConcurrency or with NewAckBatcher, since their writers keep
+// using the batch after Write returns.
+func WithBatchReuse() Option {
+	return func(b *Batcher) {
+		b.reuseBatches = true
+	}
+}
+
+// reuse makes buf, the backing array of the batch that was just written,
+// the pending batch if the Batcher reuses batches.
+func (b *Batcher) reuse(buf []interface{}) {
+	if !b.reusesBatches() || cap(buf) == 0 {
+		return
+	}
+
+	buf = buf[:cap(buf)]
+	clear(buf)
+	b.batch = buf[:0]
+}
+
+// reusesBatches reports whether the Batcher keeps ownership of the batches it
+// submits, see WithBatchReuse.
+func (b *Batcher) reusesBatches() bool {
+	return b.reuseBatches && b.pool == nil
+}
+
+// WithPreallocation allocates every new batch with room for capacity
+// elements up front, so that the batch is not grown and copied by append as
+// elements are stored to it. A capacity of 0 uses the batch size, which
+// should only be done if the batch size is reasonably small. Combine it with
+// WithBatchReuse or WithBatchPool to also avoid allocating a batch for every
+// flush.
+func WithPreallocation(capacity int) Option {
+	return func(b *Batcher) {
+		b.preallocate = true
+		b.capacity = capacity
+	}
+}
+
+// newBatch returns an empty batch, reusing a released backing array if one
+// is available.
+func (b *Batcher) newBatch() []interface{} {
+	if b.batchPool != nil {
+		if batch, ok := b.batchPool.Get().(*[]interface{}); ok {
+			return *batch
+		}
+	}
+	if !b.preallocate {
+		return nil
+	}
+
+	capacity := b.capacity
+	if capacity <= 0 {
+		capacity = b.size
+	}
+	return make([]interface{}, 0, capacity)
+}
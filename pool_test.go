@@ -0,0 +1,231 @@
+package batching_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Batch pool", func() {
+	It("reuses released batches", func() {
+		var b *batching.Batcher
+		var batches [][]interface{}
+		writer := batching.WriterFunc(func(batch []interface{}) {
+			batches = append(batches, batch)
+			b.Release(batch)
+		})
+		b = batching.NewBatcher(2, time.Minute, writer, batching.WithBatchPool())
+
+		reused := 0
+		for i := 0; i < 100; i++ {
+			b.Write("item")
+			b.Write("other-item")
+			if i > 0 && &batches[i][0] == &batches[i-1][0] {
+				reused++
+			}
+		}
+
+		Expect(reused).To(BeNumerically(">", 0))
+	})
+
+	It("clears released batches", func() {
+		b := batching.NewBatcher(2, time.Minute, &spyWriter{}, batching.WithBatchPool())
+
+		b.Write("item")
+		batch := b.Discard()
+		b.Release(batch)
+
+		Expect(batch[:1]).To(Equal([]interface{}{nil}))
+	})
+
+	It("ignores released batches without a pool", func() {
+		b := batching.NewBatcher(1, time.Minute, &spyWriter{})
+
+		b.Release([]interface{}{"item"})
+		b.Write("item")
+
+		Expect(b.Len()).To(Equal(0))
+	})
+
+	Context("WithBatchReuse", func() {
+		It("reuses the backing array of written batches", func() {
+			var firsts []*interface{}
+			writer := batching.WriterFunc(func(batch []interface{}) {
+				firsts = append(firsts, &batch[0])
+			})
+			b := batching.NewBatcher(2, time.Minute, writer, batching.WithBatchReuse())
+
+			for i := 0; i < 3; i++ {
+				b.Write("item")
+				b.Write("other-item")
+			}
+
+			Expect(firsts).To(HaveLen(3))
+			Expect(firsts[1]).To(BeIdenticalTo(firsts[0]))
+			Expect(firsts[2]).To(BeIdenticalTo(firsts[0]))
+		})
+
+		It("does not reuse batches the writer failed to write", func() {
+			var firsts []*interface{}
+			fail := true
+			writer := batching.FallibleWriterFunc(func(batch []interface{}) error {
+				firsts = append(firsts, &batch[0])
+				if fail {
+					return errors.New("write failed")
+				}
+				return nil
+			})
+			b := batching.NewFallibleBatcher(2, time.Minute, writer,
+				batching.WithBatchReuse(),
+				batching.WithRetryPolicy(batching.RetainOnError(0)),
+			)
+
+			b.Write("item")
+			b.Write("other-item")
+			fail = false
+			b.ForcedFlush()
+			b.Write("next-item")
+			b.Write("last-item")
+
+			Expect(firsts).To(HaveLen(3))
+			Expect(firsts[1]).ToNot(BeIdenticalTo(firsts[0]))
+			Expect(firsts[2]).To(BeIdenticalTo(firsts[1]))
+		})
+	})
+})
+
+var _ = Describe("Batch ownership", func() {
+	It("does not modify batches after submitting them", func() {
+		var batches [][]interface{}
+		writer := batching.WriterFunc(func(batch []interface{}) {
+			batches = append(batches, batch)
+		})
+		b := batching.NewBatcher(2, time.Minute, writer)
+
+		b.Write("item")
+		b.Write("other-item")
+		b.Write("next-item")
+		b.Write("last-item")
+
+		Expect(batches).To(Equal([][]interface{}{
+			{"item", "other-item"},
+			{"next-item", "last-item"},
+		}))
+	})
+
+	It("copies batches that are retained after a failed write", func() {
+		var failed []interface{}
+		writer := batching.FallibleWriterFunc(func(batch []interface{}) error {
+			if failed == nil {
+				failed = batch
+				return errors.New("write failed")
+			}
+			return nil
+		})
+		b := batching.NewFallibleBatcher(2, time.Minute, writer,
+			batching.WithRetryPolicy(batching.RetainOnError(0)),
+		)
+
+		b.Write("item")
+		b.Write("other-item")
+		b.Write("next-item")
+		failed[0] = "modified"
+		b.ForcedFlush()
+
+		Expect(b.Len()).To(Equal(0))
+		Expect(failed).To(Equal([]interface{}{"modified", "other-item"}))
+	})
+
+	It("retains the data of a batch the writer released before failing", func() {
+		var b *batching.Batcher
+		var batches [][]interface{}
+		writer := batching.FallibleWriterFunc(func(batch []interface{}) error {
+			batches = append(batches, slices.Clone(batch))
+			if len(batches) == 1 {
+				b.Release(batch)
+				return errors.New("write failed")
+			}
+			return nil
+		})
+		b = batching.NewFallibleBatcher(2, time.Minute, writer,
+			batching.WithBatchPool(),
+			batching.WithRetryPolicy(batching.RetainOnError(0)),
+		)
+
+		b.Write("item")
+		b.Write("other-item")
+		b.ForcedFlush()
+
+		Expect(batches).To(Equal([][]interface{}{
+			{"item", "other-item"},
+			{"item", "other-item"},
+		}))
+	})
+
+	It("hands the data of a batch the writer released before failing to the dead letter writer", func() {
+		var b *batching.Batcher
+		writer := batching.FallibleWriterFunc(func(batch []interface{}) error {
+			b.Release(batch)
+			return errors.New("write failed")
+		})
+		deadLetter := &recordingWriter{}
+		b = batching.NewFallibleBatcher(2, time.Minute, writer,
+			batching.WithBatchPool(),
+			batching.WithDeadLetterWriter(deadLetter),
+		)
+
+		b.Write("item")
+		b.Write("other-item")
+
+		Expect(deadLetter.batches).To(Equal([][]interface{}{{"item", "other-item"}}))
+	})
+})
+
+var _ = Describe("Preallocation", func() {
+	var item interface{} = "item"
+
+	fill := func(b *batching.Batcher, n int) func() {
+		return func() {
+			b.Discard()
+			for i := 0; i < n; i++ {
+				b.Write(item)
+			}
+		}
+	}
+
+	It("allocates batches with the batch size as capacity", func() {
+		b := batching.NewBatcher(100, time.Minute, &spyWriter{}, batching.WithPreallocation(0))
+
+		Expect(testing.AllocsPerRun(10, fill(b, 99))).To(Equal(1.0))
+	})
+
+	It("allocates batches with the given capacity", func() {
+		b := batching.NewBatcher(100, time.Minute, &spyWriter{}, batching.WithPreallocation(50))
+
+		Expect(testing.AllocsPerRun(10, fill(b, 50))).To(Equal(1.0))
+		Expect(testing.AllocsPerRun(10, fill(b, 51))).To(Equal(2.0))
+	})
+
+	It("does not allocate batches that are reused", func() {
+		b := batching.NewBatcher(100, time.Minute, batching.WriterFunc(func([]interface{}) {}),
+			batching.WithPreallocation(0),
+			batching.WithBatchReuse(),
+		)
+		b.Write(item)
+		b.ForcedFlush()
+
+		allocs := testing.AllocsPerRun(10, func() {
+			for i := 0; i < 100; i++ {
+				b.Write(item)
+			}
+		})
+
+		Expect(allocs).To(BeZero())
+	})
+})
@@ -0,0 +1,120 @@
+// Package prombatching exports the activity of batchers as Prometheus
+// metrics.
+package prombatching
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+// Collector is a prometheus.Collector for one or more batchers. Each
+// Batcher reports to the Collector through the batching.Metrics returned by
+// Metrics, which labels its metrics with the name of the Batcher. Collector
+// should be created with NewCollector().
+type Collector struct {
+	flushes       *prometheus.CounterVec
+	writeErrors   *prometheus.CounterVec
+	dropped       *prometheus.CounterVec
+	pending       *prometheus.GaugeVec
+	batchSize     *prometheus.HistogramVec
+	flushDuration *prometheus.HistogramVec
+}
+
+// NewCollector creates a new Collector whose metrics are prefixed with
+// namespace.
+func NewCollector(namespace string) *Collector {
+	return &Collector{
+		flushes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "batcher_flushes_total",
+			Help:      "Number of times the writer was invoked, by reason and outcome.",
+		}, []string{"batcher", "reason", "outcome"}),
+		writeErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "batcher_write_errors_total",
+			Help:      "Number of batches the writer failed to write.",
+		}, []string{"batcher"}),
+		dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "batcher_dropped_total",
+			Help:      "Number of elements dropped without writing them, by reason.",
+		}, []string{"batcher", "reason"}),
+		pending: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "batcher_pending",
+			Help:      "Number of elements waiting to be written.",
+		}, []string{"batcher"}),
+		batchSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "batcher_batch_size",
+			Help:      "Number of elements submitted per invocation of the writer.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 16),
+		}, []string{"batcher", "reason"}),
+		flushDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "batcher_flush_duration_seconds",
+			Help:      "Time the writer took to write a batch.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"batcher", "reason"}),
+	}
+}
+
+// Metrics returns the batching.Metrics for the Batcher with the given name,
+// which is passed to it with batching.WithMetrics.
+func (c *Collector) Metrics(name string) batching.Metrics {
+	return metrics{c: c, name: name}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.flushes.Describe(ch)
+	c.writeErrors.Describe(ch)
+	c.dropped.Describe(ch)
+	c.pending.Describe(ch)
+	c.batchSize.Describe(ch)
+	c.flushDuration.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.flushes.Collect(ch)
+	c.writeErrors.Collect(ch)
+	c.dropped.Collect(ch)
+	c.pending.Collect(ch)
+	c.batchSize.Collect(ch)
+	c.flushDuration.Collect(ch)
+}
+
+// metrics records the metrics of a single Batcher in a Collector.
+type metrics struct {
+	c    *Collector
+	name string
+}
+
+func (m metrics) Counter(name string, delta float64, labels map[string]string) {
+	switch name {
+	case batching.MetricFlushes:
+		m.c.flushes.WithLabelValues(m.name, labels["reason"], labels["outcome"]).Add(delta)
+		if labels["outcome"] == "error" {
+			m.c.writeErrors.WithLabelValues(m.name).Add(delta)
+		}
+	case batching.MetricDropped:
+		m.c.dropped.WithLabelValues(m.name, labels["reason"]).Add(delta)
+	}
+}
+
+func (m metrics) Gauge(name string, value float64, _ map[string]string) {
+	if name == batching.MetricPending {
+		m.c.pending.WithLabelValues(m.name).Set(value)
+	}
+}
+
+func (m metrics) Histogram(name string, value float64, labels map[string]string) {
+	switch name {
+	case batching.MetricBatchSize:
+		m.c.batchSize.WithLabelValues(m.name, labels["reason"]).Observe(value)
+	case batching.MetricFlushDuration:
+		m.c.flushDuration.WithLabelValues(m.name, labels["reason"]).Observe(value)
+	}
+}
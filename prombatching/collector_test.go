@@ -0,0 +1,64 @@
+package prombatching_test
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+	"code.cloudfoundry.org/go-batching/prombatching"
+)
+
+var _ = Describe("Collector", func() {
+	var (
+		c   *prombatching.Collector
+		reg *prometheus.Registry
+	)
+
+	BeforeEach(func() {
+		c = prombatching.NewCollector("test")
+		reg = prometheus.NewRegistry()
+		reg.MustRegister(c)
+	})
+
+	It("exports flush counters, write errors and pending gauges per batcher", func() {
+		writer := batching.FallibleWriterFunc(func([]interface{}) error {
+			return errors.New("failed")
+		})
+		logs := batching.NewFallibleBatcher(2, time.Minute, writer, batching.WithMetrics(c.Metrics("logs")))
+		metrics := batching.NewBatcher(10, time.Minute, batching.WriterFunc(func([]interface{}) {}),
+			batching.WithMetrics(c.Metrics("metrics")),
+		)
+		logs.WriteAll("a", "b")
+		metrics.Write("c")
+
+		Expect(testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP test_batcher_flushes_total Number of times the writer was invoked, by reason and outcome.
+# TYPE test_batcher_flushes_total counter
+test_batcher_flushes_total{batcher="logs",outcome="error",reason="size"} 1
+# HELP test_batcher_write_errors_total Number of batches the writer failed to write.
+# TYPE test_batcher_write_errors_total counter
+test_batcher_write_errors_total{batcher="logs"} 1
+# HELP test_batcher_pending Number of elements waiting to be written.
+# TYPE test_batcher_pending gauge
+test_batcher_pending{batcher="logs"} 0
+test_batcher_pending{batcher="metrics"} 1
+`), "test_batcher_flushes_total", "test_batcher_write_errors_total", "test_batcher_pending")).To(Succeed())
+	})
+
+	It("exports batch size histograms", func() {
+		b := batching.NewBatcher(3, time.Minute, batching.WriterFunc(func([]interface{}) {}),
+			batching.WithMetrics(c.Metrics("logs")),
+		)
+		b.WriteAll("a", "b", "c")
+
+		Expect(testutil.CollectAndCount(c, "test_batcher_batch_size")).To(Equal(1))
+		Expect(testutil.CollectAndCount(c, "test_batcher_flush_duration_seconds")).To(Equal(1))
+	})
+})
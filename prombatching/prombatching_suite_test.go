@@ -0,0 +1,13 @@
+package prombatching_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestPrombatching(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Prombatching Suite")
+}
@@ -0,0 +1,13 @@
+package protobatching_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestProtobatching(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Protobatching Suite")
+}
@@ -0,0 +1,61 @@
+// Package protobatching writes batches of protocol buffer messages.
+package protobatching
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+// ContainerFunc packs a batch of messages into a single message, typically
+// one with a repeated field holding the messages.
+type ContainerFunc func(batch []proto.Message) proto.Message
+
+// NewWriter returns a batching.ContextWriter that packs every batch into the
+// message returned by container and writes the marshaled message to sink
+// with a single call to Write. Every element of the batch must be a
+// proto.Message. The buffers used to marshal the messages are reused, so
+// sink must not retain the data written to it, as required of any
+// io.Writer.
+func NewWriter(container ContainerFunc, sink io.Writer) batching.ContextWriter {
+	return &writer{container: container, sink: sink}
+}
+
+type writer struct {
+	container ContainerFunc
+	sink      io.Writer
+	bufs      sync.Pool
+}
+
+func (w *writer) Write(ctx context.Context, batch []interface{}) error {
+	msgs := make([]proto.Message, 0, len(batch))
+	for _, data := range batch {
+		msg, ok := data.(proto.Message)
+		if !ok {
+			return fmt.Errorf("protobatching: %T is not a proto.Message", data)
+		}
+		msgs = append(msgs, msg)
+	}
+
+	buf, ok := w.bufs.Get().(*[]byte)
+	if !ok {
+		buf = new([]byte)
+	}
+	defer w.bufs.Put(buf)
+
+	var err error
+	*buf, err = proto.MarshalOptions{}.MarshalAppend((*buf)[:0], w.container(msgs))
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err = w.sink.Write(*buf)
+	return err
+}
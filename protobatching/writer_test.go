@@ -0,0 +1,89 @@
+package protobatching_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"code.cloudfoundry.org/go-batching"
+	"code.cloudfoundry.org/go-batching/protobatching"
+)
+
+var _ = Describe("Writer", func() {
+	container := func(batch []proto.Message) proto.Message {
+		list := &structpb.ListValue{}
+		for _, msg := range batch {
+			list.Values = append(list.Values, msg.(*structpb.Value))
+		}
+		return list
+	}
+
+	It("writes every batch packed into the container", func() {
+		sink := &recordingSink{}
+		b := batching.NewContextBatcher(2, time.Minute, protobatching.NewWriter(container, sink))
+
+		b.Write(structpb.NewStringValue("item"))
+		b.Write(structpb.NewNumberValue(2))
+		b.Write(structpb.NewBoolValue(true))
+		b.ForcedFlush()
+
+		Expect(sink.writes).To(HaveLen(2))
+		Expect(unmarshal(sink.writes[0]).AsSlice()).To(Equal([]interface{}{"item", 2.0}))
+		Expect(unmarshal(sink.writes[1]).AsSlice()).To(Equal([]interface{}{true}))
+	})
+
+	It("fails batches with elements that are not messages", func() {
+		sink := &recordingSink{}
+		w := protobatching.NewWriter(container, sink)
+
+		err := w.Write(context.Background(), []interface{}{structpb.NewNullValue(), "item"})
+
+		Expect(err).To(MatchError(ContainSubstring("string is not a proto.Message")))
+		Expect(sink.writes).To(BeEmpty())
+	})
+
+	It("returns the error of the sink", func() {
+		w := protobatching.NewWriter(container, &recordingSink{err: errors.New("write failed")})
+
+		err := w.Write(context.Background(), []interface{}{structpb.NewNullValue()})
+
+		Expect(err).To(MatchError("write failed"))
+	})
+
+	It("does not write if ctx is done", func() {
+		sink := &recordingSink{}
+		w := protobatching.NewWriter(container, sink)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := w.Write(ctx, []interface{}{structpb.NewNullValue()})
+
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(sink.writes).To(BeEmpty())
+	})
+})
+
+func unmarshal(data []byte) *structpb.ListValue {
+	list := &structpb.ListValue{}
+	Expect(proto.Unmarshal(data, list)).To(Succeed())
+	return list
+}
+
+type recordingSink struct {
+	writes [][]byte
+	err    error
+}
+
+func (s *recordingSink) Write(p []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	s.writes = append(s.writes, bytes.Clone(p))
+	return len(p), nil
+}
@@ -0,0 +1,30 @@
+package batching
+
+import "context"
+
+// RebatchWriter is a ContextWriter that stores the elements of every batch
+// in another Batcher, so that batchers can be chained in stages. For
+// example, per-source micro-batches written by a fine-grained Batcher can
+// feed a coarser Batcher building per-connection payloads. To keep the
+// micro-batches intact rather than regrouping their elements, write each
+// batch to the coarser Batcher as a single element instead. RebatchWriter
+// should be created with NewRebatchWriter().
+type RebatchWriter struct {
+	next *Batcher
+}
+
+// NewRebatchWriter creates a new RebatchWriter that stores batches in next.
+// Since next is written to whenever an earlier stage writes a batch, it is
+// made safe for concurrent use as if created WithLocking, so
+// NewRebatchWriter must be called before next is shared between goroutines.
+func NewRebatchWriter(next *Batcher) *RebatchWriter {
+	next.ensureLocking()
+	return &RebatchWriter{next: next}
+}
+
+// Write stores the elements of batch in the next Batcher like
+// WriteAllContext, returning the error of any write of the next Batcher
+// this triggers.
+func (w *RebatchWriter) Write(ctx context.Context, batch []interface{}) error {
+	return w.next.WriteAllContext(ctx, batch...)
+}
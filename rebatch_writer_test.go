@@ -0,0 +1,35 @@
+package batching_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("RebatchWriter", func() {
+	It("feeds the batches of one Batcher into another", func() {
+		writer := &recordingWriter{}
+		coarse := batching.NewBatcher(4, time.Minute, writer)
+		fine := batching.NewContextBatcher(2, time.Minute, batching.NewRebatchWriter(coarse))
+
+		fine.WriteAll("a", "b", "c")
+		Expect(writer.batches).To(BeEmpty())
+		Expect(coarse.Len()).To(Equal(2))
+
+		fine.Write("d")
+		Expect(writer.batches).To(Equal([][]interface{}{{"a", "b", "c", "d"}}))
+	})
+
+	It("returns the error of the next Batcher", func() {
+		writeErr := errors.New("failed")
+		coarse := batching.NewFallibleBatcher(1, time.Minute, &recordingFallibleWriter{errs: []error{writeErr}})
+		fine := batching.NewContextBatcher(1, time.Minute, batching.NewRebatchWriter(coarse))
+
+		Expect(fine.WriteContext(context.Background(), "a")).To(MatchError(writeErr))
+	})
+})
@@ -0,0 +1,60 @@
+package batching
+
+import "errors"
+
+// RetryPolicy decides whether a batch that a FallibleWriter failed to write
+// is retained by the Batcher. A retained batch stays at the front of the
+// pending batch and is written again on the next flush, together with any
+// data written in the meantime.
+type RetryPolicy interface {
+	// Retain reports whether the batch should be retained. attempts is the
+	// number of consecutive failed writes of the batch, including this one.
+	Retain(attempts int, err error) bool
+}
+
+// RetryPolicyFunc is an adapter to allow ordinary functions to be a
+// RetryPolicy.
+type RetryPolicyFunc func(attempts int, err error) bool
+
+// Retain implements RetryPolicy.
+func (f RetryPolicyFunc) Retain(attempts int, err error) bool {
+	return f(attempts, err)
+}
+
+// DropOnError is the default RetryPolicy. It drops every batch that failed
+// to write.
+var DropOnError RetryPolicy = RetryPolicyFunc(func(int, error) bool {
+	return false
+})
+
+// RetainOnError returns a RetryPolicy that retains a batch until it has
+// failed to write maxAttempts times. A maxAttempts of zero or less retains the
+// batch until it is written.
+func RetainOnError(maxAttempts int) RetryPolicy {
+	return RetryPolicyFunc(func(attempts int, _ error) bool {
+		return maxAttempts <= 0 || attempts < maxAttempts
+	})
+}
+
+// RetainRetryable returns a RetryPolicy like RetainOnError that drops a batch
+// straight away if the error reports that retrying will not help, by
+// implementing a Retryable method that returns false. Errors that do not
+// implement Retryable are considered retryable.
+func RetainRetryable(maxAttempts int) RetryPolicy {
+	return RetryPolicyFunc(func(attempts int, err error) bool {
+		var r interface{ Retryable() bool }
+		if errors.As(err, &r) && !r.Retryable() {
+			return false
+		}
+		return maxAttempts <= 0 || attempts < maxAttempts
+	})
+}
+
+// WithRetryPolicy sets the RetryPolicy used when a FallibleWriter fails to
+// write a batch.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(b *Batcher) {
+		b.retryPolicy = p
+		b.retains = true
+	}
+}
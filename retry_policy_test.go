@@ -0,0 +1,117 @@
+package batching_test
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("RetryPolicy", func() {
+	It("drops a batch that failed to write by default", func() {
+		writer := &spyFallibleWriter{err: errors.New("failed")}
+		b := batching.NewFallibleBatcher(1, time.Minute, writer)
+
+		b.Write("item")
+		writer.err = nil
+		b.ForcedFlush()
+
+		Expect(writer.called).To(Equal(1))
+	})
+
+	It("retains a batch that failed to write", func() {
+		writer := &spyFallibleWriter{err: errors.New("failed")}
+		b := batching.NewFallibleBatcher(3, time.Minute, writer,
+			batching.WithRetryPolicy(batching.RetainOnError(0)),
+		)
+
+		b.Write("item")
+		b.Write("other-item")
+		b.ForcedFlush()
+		Expect(writer.called).To(Equal(1))
+
+		writer.err = nil
+		b.Write("another-item")
+
+		Expect(writer.called).To(Equal(2))
+		Expect(writer.batch).To(Equal([]interface{}{"item", "other-item", "another-item"}))
+
+		b.ForcedFlush()
+		Expect(writer.called).To(Equal(2))
+	})
+
+	It("drops a retained batch after the maximum attempts", func() {
+		writer := &spyFallibleWriter{err: errors.New("failed")}
+		b := batching.NewFallibleBatcher(1, time.Minute, writer,
+			batching.WithRetryPolicy(batching.RetainOnError(2)),
+		)
+
+		b.Write("item")
+		b.ForcedFlush()
+		b.ForcedFlush()
+
+		Expect(writer.called).To(Equal(2))
+	})
+
+	It("passes the number of attempts and the error to the policy", func() {
+		writeErr := errors.New("failed")
+		writer := &spyFallibleWriter{err: writeErr}
+		var attempts []int
+		var errs []error
+		policy := batching.RetryPolicyFunc(func(n int, err error) bool {
+			attempts = append(attempts, n)
+			errs = append(errs, err)
+			return true
+		})
+		b := batching.NewFallibleBatcher(1, time.Minute, writer, batching.WithRetryPolicy(policy))
+
+		b.Write("item")
+		b.ForcedFlush()
+
+		Expect(attempts).To(Equal([]int{1, 2}))
+		Expect(errs).To(Equal([]error{writeErr, writeErr}))
+	})
+})
+
+type spyFallibleWriter struct {
+	batch  []interface{}
+	called int
+	err    error
+}
+
+func (w *spyFallibleWriter) Write(batch []interface{}) error {
+	w.batch = batch
+	w.called++
+	return w.err
+}
+
+var _ = Describe("RetainRetryable", func() {
+	It("retains batches that failed with a retryable error", func() {
+		policy := batching.RetainRetryable(2)
+
+		Expect(policy.Retain(1, errors.New("failed"))).To(BeTrue())
+		Expect(policy.Retain(1, retryableError(true))).To(BeTrue())
+		Expect(policy.Retain(2, retryableError(true))).To(BeFalse())
+	})
+
+	It("drops batches that failed with an error that is not retryable", func() {
+		policy := batching.RetainRetryable(0)
+
+		Expect(policy.Retain(1, retryableError(false))).To(BeFalse())
+		Expect(policy.Retain(1, fmt.Errorf("wrapped: %w", retryableError(false)))).To(BeFalse())
+	})
+})
+
+type retryableError bool
+
+func (e retryableError) Error() string {
+	return "failed"
+}
+
+func (e retryableError) Retryable() bool {
+	return bool(e)
+}
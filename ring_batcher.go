@@ -0,0 +1,116 @@
+package batching
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// RingBatcher is a high throughput batcher for a single producer and a single
+// consumer. Data is stored in a fixed size ring buffer that is indexed
+// atomically, so neither side takes a lock and storing data does not
+// allocate. Write must only be called from the producing goroutine and
+// Flush, ForcedFlush and Len from the consuming goroutine. RingBatcher should
+// be created with NewRingBatcher().
+//
+// The batch passed to the writer is reused for the next write, so the writer
+// must not retain it.
+type RingBatcher struct {
+	buf  []interface{}
+	mask uint64
+
+	// head is the index of the next element to read and is only advanced by
+	// the consumer, tail is the index of the next element to write and is
+	// only advanced by the producer.
+	head atomic.Uint64
+	tail atomic.Uint64
+
+	size     int
+	interval time.Duration
+	clock    Clock
+	lastSent time.Time
+	w        Writer
+	batch    []interface{}
+}
+
+// NewRingBatcher creates a new RingBatcher that holds up to capacity pending
+// elements. capacity is rounded up to the next power of two.
+func NewRingBatcher(capacity, size int, interval time.Duration, writer Writer) *RingBatcher {
+	capacity = 1 << bits.Len(uint(max(capacity, 1)-1))
+
+	return &RingBatcher{
+		buf:      make([]interface{}, capacity),
+		mask:     uint64(capacity - 1),
+		size:     max(size, 1),
+		interval: interval,
+		clock:    systemClock{},
+		lastSent: time.Now(),
+		w:        writer,
+		batch:    make([]interface{}, 0, max(size, 1)),
+	}
+}
+
+// SetClock sets the Clock used to decide when the interval has lapsed, as
+// WithClock does for a Batcher. It defaults to the system clock. The interval
+// starts over at the time of c. SetClock must be called from the consuming
+// goroutine.
+func (r *RingBatcher) SetClock(c Clock) {
+	r.clock = c
+	r.lastSent = c.Now()
+}
+
+// Write stores data in the ring buffer. It reports whether data was stored,
+// which is not the case if the ring buffer is full. Write never invokes the
+// writer.
+func (r *RingBatcher) Write(data interface{}) bool {
+	tail := r.tail.Load()
+	if tail-r.head.Load() == uint64(len(r.buf)) {
+		return false
+	}
+
+	r.buf[tail&r.mask] = data
+	r.tail.Store(tail + 1)
+	return true
+}
+
+// Flush writes the pending data if it makes up at least one full batch or
+// the interval has lapsed. It should be called regularly by the consumer.
+func (r *RingBatcher) Flush() {
+	n := r.Len()
+	if n >= r.size || (n > 0 && r.clock.Since(r.lastSent) >= r.interval) {
+		r.flush(n)
+	}
+}
+
+// ForcedFlush writes all pending data, regardless of the batch size or
+// interval.
+func (r *RingBatcher) ForcedFlush() {
+	r.flush(r.Len())
+}
+
+// Len returns the number of pending elements.
+func (r *RingBatcher) Len() int {
+	return int(r.tail.Load() - r.head.Load())
+}
+
+// flush writes the first n pending elements in batches of at most the batch
+// size.
+func (r *RingBatcher) flush(n int) {
+	head := r.head.Load()
+	for n > 0 {
+		k := min(n, r.size)
+		for i := 0; i < k; i++ {
+			slot := &r.buf[(head+uint64(i))&r.mask]
+			r.batch = append(r.batch, *slot)
+			*slot = nil
+		}
+		head += uint64(k)
+		r.head.Store(head)
+		n -= k
+
+		r.w.Write(r.batch)
+		clear(r.batch)
+		r.batch = r.batch[:0]
+	}
+	r.lastSent = r.clock.Now()
+}
@@ -0,0 +1,111 @@
+package batching_test
+
+import (
+	"runtime"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("RingBatcher", func() {
+	var (
+		writer *recordingWriter
+		r      *batching.RingBatcher
+	)
+
+	BeforeEach(func() {
+		writer = &recordingWriter{}
+		r = batching.NewRingBatcher(4, 2, time.Hour, batching.WriterFunc(func(batch []interface{}) {
+			writer.Write(append([]interface{}(nil), batch...))
+		}))
+	})
+
+	It("writes full batches on flush", func() {
+		Expect(r.Write("a")).To(BeTrue())
+		r.Flush()
+		Expect(writer.batches).To(BeEmpty())
+
+		Expect(r.Write("b")).To(BeTrue())
+		Expect(r.Write("c")).To(BeTrue())
+		r.Flush()
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"a", "b"}, {"c"}}))
+		Expect(r.Len()).To(Equal(0))
+	})
+
+	It("writes partial batches once the interval has lapsed", func() {
+		r := batching.NewRingBatcher(4, 2, time.Nanosecond, writer)
+		r.Write("a")
+		time.Sleep(time.Millisecond)
+
+		r.Flush()
+
+		Expect(writer.batches).To(HaveLen(1))
+	})
+
+	It("measures the interval with its clock", func() {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		r.SetClock(clock)
+		r.Write("a")
+
+		clock.Advance(59 * time.Minute)
+		r.Flush()
+		Expect(writer.batches).To(BeEmpty())
+
+		clock.Advance(time.Minute)
+		r.Flush()
+		Expect(writer.batches).To(Equal([][]interface{}{{"a"}}))
+	})
+
+	It("rejects data while the ring buffer is full", func() {
+		for _, d := range []string{"a", "b", "c", "d"} {
+			Expect(r.Write(d)).To(BeTrue())
+		}
+		Expect(r.Write("e")).To(BeFalse())
+
+		r.ForcedFlush()
+
+		Expect(r.Write("e")).To(BeTrue())
+		Expect(writer.batches).To(Equal([][]interface{}{{"a", "b"}, {"c", "d"}}))
+	})
+
+	It("rounds the capacity up to a power of two", func() {
+		r := batching.NewRingBatcher(3, 2, time.Hour, writer)
+		for i := 0; i < 4; i++ {
+			Expect(r.Write(i)).To(BeTrue())
+		}
+		Expect(r.Write(4)).To(BeFalse())
+	})
+
+	It("hands data from a producer to a consumer goroutine", func() {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 1000; {
+				if r.Write(i) {
+					i++
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+
+		var items []interface{}
+		for len(items) < 1000 {
+			writer.batches = nil
+			r.ForcedFlush()
+			for _, batch := range writer.batches {
+				items = append(items, batch...)
+			}
+			runtime.Gosched()
+		}
+		<-done
+
+		for i, data := range items {
+			Expect(data).To(Equal(i))
+		}
+	})
+})
@@ -0,0 +1,163 @@
+package batching
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// RotatingFile is an io.Writer that appends to a file and rotates it once it
+// grows too large or too old, for instance to archive batches or to keep
+// dead letters with NewEncodingWriter. Rotation happens between writes, so
+// a batch written with a single call to Write is never split across files.
+// A rotated file is renamed by appending the time of its rotation to its
+// name, and optionally compressed with gzip. RotatingFile should be created
+// with OpenRotatingFile().
+type RotatingFile struct {
+	path     string
+	maxBytes int64
+	maxAge   time.Duration
+	compress bool
+	clock    Clock
+
+	mu       sync.Mutex
+	f        *os.File
+	size     int64
+	openedAt time.Time
+}
+
+const rotatedTimeFormat = "20060102T150405.000000000"
+
+// OpenRotatingFile opens the file at path for appending, creating it if it
+// does not exist. The file is rotated before a write that would make it
+// larger than maxBytes, or once it has been open for maxAge. A limit of zero
+// or less means no limit. If compress is true, rotated files are compressed
+// and get the extension ".gz".
+func OpenRotatingFile(path string, maxBytes int64, maxAge time.Duration, compress bool) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, maxBytes: maxBytes, maxAge: maxAge, compress: compress, clock: systemClock{}}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// SetClock sets the Clock used to measure the age of the file and to name
+// rotated files, as WithClock does for a Batcher. It defaults to the system
+// clock. The age of the current file starts over at the time of c.
+func (r *RotatingFile) SetClock(c Clock) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.clock = c
+	r.openedAt = c.Now()
+}
+
+// Write implements io.Writer.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+	if r.due(len(p)) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file without rotating it.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return os.ErrClosed
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	r.f = f
+	r.size = info.Size()
+	r.openedAt = r.clock.Now()
+	return nil
+}
+
+func (r *RotatingFile) due(n int) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.maxBytes > 0 && r.size+int64(n) > r.maxBytes {
+		return true
+	}
+	return r.maxAge > 0 && r.clock.Since(r.openedAt) >= r.maxAge
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	r.f = nil
+
+	rotated := r.path + "." + r.clock.Now().UTC().Format(rotatedTimeFormat)
+	if err := os.Rename(r.path, rotated); err != nil {
+		return err
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+	if r.compress {
+		return compressFile(rotated)
+	}
+	return nil
+}
+
+// compressFile replaces the file at path by a gzip compressed copy with the
+// extension ".gz".
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path + ".gz")
+		return err
+	}
+
+	return os.Remove(path)
+}
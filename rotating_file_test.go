@@ -0,0 +1,137 @@
+package batching_test
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("RotatingFile", func() {
+	var (
+		dir  string
+		path string
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		path = filepath.Join(dir, "batches.jsonl")
+	})
+
+	// files returns the contents of the rotated files, oldest first,
+	// followed by the contents of the current file.
+	files := func() []string {
+		matches, err := filepath.Glob(path + ".*")
+		Expect(err).ToNot(HaveOccurred())
+		sort.Strings(matches)
+
+		var contents []string
+		for _, name := range append(matches, path) {
+			f, err := os.Open(name)
+			Expect(err).ToNot(HaveOccurred())
+			var r io.Reader = f
+			if strings.HasSuffix(name, ".gz") {
+				r, err = gzip.NewReader(f)
+				Expect(err).ToNot(HaveOccurred())
+			}
+			data, err := io.ReadAll(r)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Close()).To(Succeed())
+			contents = append(contents, string(data))
+		}
+		return contents
+	}
+
+	It("appends batches to the file", func() {
+		Expect(os.WriteFile(path, []byte("\"old\"\n"), 0o600)).To(Succeed())
+		f, err := batching.OpenRotatingFile(path, 0, 0, false)
+		Expect(err).ToNot(HaveOccurred())
+		b := batching.NewContextBatcher(2, time.Minute, batching.NewEncodingWriter(batching.JSONLines, f))
+
+		b.Write("item")
+		b.Write("other-item")
+		Expect(f.Close()).To(Succeed())
+
+		Expect(files()).To(Equal([]string{"\"old\"\n\"item\"\n\"other-item\"\n"}))
+	})
+
+	It("rotates the file before it grows too large", func() {
+		f, err := batching.OpenRotatingFile(path, 10, 0, false)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(f.Close)
+
+		for _, batch := range []string{"aaaa\n", "bbbb\n", "cccccccccccc\n", "d\n"} {
+			_, err := f.Write([]byte(batch))
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		Expect(files()).To(Equal([]string{"aaaa\nbbbb\n", "cccccccccccc\n", "d\n"}))
+	})
+
+	It("rotates the file once it is too old", func() {
+		f, err := batching.OpenRotatingFile(path, 0, 20*time.Millisecond, false)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(f.Close)
+
+		_, err = f.Write([]byte("a\n"))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = f.Write([]byte("b\n"))
+		Expect(err).ToNot(HaveOccurred())
+		time.Sleep(30 * time.Millisecond)
+		_, err = f.Write([]byte("c\n"))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(files()).To(Equal([]string{"a\nb\n", "c\n"}))
+	})
+
+	It("measures the age of the file with its clock", func() {
+		clock := &fakeClock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+		f, err := batching.OpenRotatingFile(path, 0, time.Hour, false)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(f.Close)
+		f.SetClock(clock)
+
+		_, err = f.Write([]byte("a\n"))
+		Expect(err).ToNot(HaveOccurred())
+		clock.Advance(time.Hour)
+		_, err = f.Write([]byte("b\n"))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(path + ".20240102T040405.000000000").To(BeAnExistingFile())
+		Expect(files()).To(Equal([]string{"a\n", "b\n"}))
+	})
+
+	It("compresses rotated files", func() {
+		f, err := batching.OpenRotatingFile(path, 5, 0, true)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(f.Close)
+
+		_, err = f.Write([]byte("aaaa\n"))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = f.Write([]byte("bbbb\n"))
+		Expect(err).ToNot(HaveOccurred())
+
+		matches, err := filepath.Glob(path + ".*.gz")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matches).To(HaveLen(1))
+		Expect(files()).To(Equal([]string{"aaaa\n", "bbbb\n"}))
+	})
+
+	It("fails writes once closed", func() {
+		f, err := batching.OpenRotatingFile(path, 0, 0, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f.Close()).To(Succeed())
+
+		_, err = f.Write([]byte("a\n"))
+
+		Expect(err).To(MatchError(os.ErrClosed))
+	})
+})
@@ -0,0 +1,109 @@
+package batching
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// RoundRobinWriter is a ContextWriter that distributes successive batches
+// across several writers, such as connections to different endpoints, to
+// spread the load. A writer that fails is skipped for a cooldown and the
+// batch is written to the next writer instead. If every writer is cooling
+// down they are all tried in turn, so that writers that recovered are found
+// again. The methods of a RoundRobinWriter are safe to call from multiple
+// goroutines. RoundRobinWriter should be created with NewRoundRobinWriter().
+type RoundRobinWriter struct {
+	writers  []ContextWriter
+	cooldown time.Duration
+	clock    Clock
+
+	mu          sync.Mutex
+	next        int
+	failedUntil []time.Time
+}
+
+// NewRoundRobinWriter creates a new RoundRobinWriter that skips a failing
+// writer for cooldown.
+func NewRoundRobinWriter(cooldown time.Duration, writers ...ContextWriter) *RoundRobinWriter {
+	return &RoundRobinWriter{
+		writers:     writers,
+		cooldown:    cooldown,
+		clock:       systemClock{},
+		failedUntil: make([]time.Time, len(writers)),
+	}
+}
+
+// SetClock sets the Clock used to measure the cooldown of failed writers, as
+// WithClock does for a Batcher. It defaults to the system clock.
+func (r *RoundRobinWriter) SetClock(c Clock) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.clock = c
+}
+
+// Write writes the batch to the next writer that is not cooling down. It
+// returns the errors of all writers tried joined together if none of them
+// wrote the batch.
+func (r *RoundRobinWriter) Write(ctx context.Context, batch []interface{}) error {
+	if len(r.writers) == 0 {
+		return errors.New("batching: no writers")
+	}
+
+	order := r.order()
+	errs := make([]error, 0, len(order))
+	for _, i := range order {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := r.writers[i].Write(ctx, batch)
+		r.report(i, err)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// order returns the indexes of the writers to try, starting with the next
+// one and skipping the ones cooling down unless all of them are.
+func (r *RoundRobinWriter) order() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	start := r.next
+	r.next = (r.next + 1) % len(r.writers)
+
+	now := r.clock.Now()
+	order := make([]int, 0, len(r.writers))
+	for k := range r.writers {
+		i := (start + k) % len(r.writers)
+		if now.Before(r.failedUntil[i]) {
+			continue
+		}
+		order = append(order, i)
+	}
+	if len(order) > 0 {
+		return order
+	}
+
+	for k := range r.writers {
+		order = append(order, (start+k)%len(r.writers))
+	}
+	return order
+}
+
+func (r *RoundRobinWriter) report(i int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err != nil {
+		r.failedUntil[i] = r.clock.Now().Add(r.cooldown)
+		return
+	}
+	r.failedUntil[i] = time.Time{}
+}
@@ -0,0 +1,97 @@
+package batching_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("RoundRobinWriter", func() {
+	type endpoint struct {
+		err     error
+		batches [][]interface{}
+	}
+
+	writer := func(e *endpoint) batching.ContextWriter {
+		return batching.ContextWriterFunc(func(_ context.Context, batch []interface{}) error {
+			e.batches = append(e.batches, batch)
+			return e.err
+		})
+	}
+
+	It("distributes successive batches across the writers", func() {
+		a, b := &endpoint{}, &endpoint{}
+		batcher := batching.NewContextBatcher(1, time.Minute,
+			batching.NewRoundRobinWriter(time.Minute, writer(a), writer(b)),
+		)
+		batcher.WriteAll("1", "2", "3")
+
+		Expect(a.batches).To(Equal([][]interface{}{{"1"}, {"3"}}))
+		Expect(b.batches).To(Equal([][]interface{}{{"2"}}))
+	})
+
+	It("writes the batch to the next writer if one fails", func() {
+		a, b := &endpoint{err: errors.New("failed")}, &endpoint{}
+		w := batching.NewRoundRobinWriter(time.Minute, writer(a), writer(b))
+
+		Expect(w.Write(context.Background(), []interface{}{"1"})).To(Succeed())
+		Expect(b.batches).To(Equal([][]interface{}{{"1"}}))
+	})
+
+	It("skips failing writers for the cooldown", func() {
+		a, b := &endpoint{err: errors.New("failed")}, &endpoint{}
+		w := batching.NewRoundRobinWriter(time.Minute, writer(a), writer(b))
+
+		for i := 0; i < 4; i++ {
+			Expect(w.Write(context.Background(), []interface{}{i})).To(Succeed())
+		}
+
+		Expect(a.batches).To(HaveLen(1))
+		Expect(b.batches).To(HaveLen(4))
+	})
+
+	It("tries failing writers again once the cooldown passed", func() {
+		a, b := &endpoint{err: errors.New("failed")}, &endpoint{}
+		w := batching.NewRoundRobinWriter(time.Millisecond, writer(a), writer(b))
+		Expect(w.Write(context.Background(), []interface{}{"1"})).To(Succeed())
+		a.err = nil
+
+		time.Sleep(time.Millisecond)
+		Expect(w.Write(context.Background(), []interface{}{"2"})).To(Succeed())
+		Expect(w.Write(context.Background(), []interface{}{"3"})).To(Succeed())
+
+		Expect(a.batches).To(Equal([][]interface{}{{"1"}, {"3"}}))
+	})
+
+	It("measures the cooldown with its clock", func() {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		a, b := &endpoint{err: errors.New("failed")}, &endpoint{}
+		w := batching.NewRoundRobinWriter(time.Minute, writer(a), writer(b))
+		w.SetClock(clock)
+		Expect(w.Write(context.Background(), []interface{}{"1"})).To(Succeed())
+		a.err = nil
+
+		clock.Advance(59 * time.Second)
+		Expect(w.Write(context.Background(), []interface{}{"2"})).To(Succeed())
+		clock.Advance(time.Second)
+		Expect(w.Write(context.Background(), []interface{}{"3"})).To(Succeed())
+
+		Expect(a.batches).To(Equal([][]interface{}{{"1"}, {"3"}}))
+	})
+
+	It("returns the errors of all writers if none wrote the batch", func() {
+		errA, errB := errors.New("a failed"), errors.New("b failed")
+		w := batching.NewRoundRobinWriter(time.Minute, writer(&endpoint{err: errA}), writer(&endpoint{err: errB}))
+
+		err := w.Write(context.Background(), []interface{}{"1"})
+
+		Expect(err).To(MatchError(errA))
+		Expect(err).To(MatchError(errB))
+		Expect(w.Write(context.Background(), []interface{}{"2"})).To(MatchError(errA))
+	})
+})
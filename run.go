@@ -0,0 +1,112 @@
+package batching
+
+import (
+	"context"
+	"time"
+)
+
+// Run writes every element received from in to the Batcher and flushes the
+// batch whenever it is due, until in is closed or ctx is done. This replaces
+// the loop of non-blocking reads and calls to Flush otherwise needed to drive
+// a Batcher. Any pending data is written with a forced flush before Run
+// returns, which is passed ctx once in is closed, or a context that keeps
+// the values of ctx but is not canceled once ctx is done. Run returns nil
+// once in is closed or the error of ctx.
+//
+// The writer is invoked from the goroutine calling Run. Other goroutines may
+// only use the Batcher at the same time if it was created WithLocking.
+func (b *Batcher) Run(ctx context.Context, in <-chan interface{}) error {
+	return run(ctx, b, in, b.Write)
+}
+
+// Run writes every slice received from in to the ByteBatcher. See
+// Batcher.Run.
+func (b *ByteBatcher) Run(ctx context.Context, in <-chan []byte) error {
+	return run(ctx, b.Batcher, in, b.Write)
+}
+
+func run[T any](ctx context.Context, b *Batcher, in <-chan T, write func(T)) error {
+	fire := make(chan struct{}, 1)
+	arm := func() Timer {
+		return b.afterFunc(b.untilDue(), func() {
+			select {
+			case fire <- struct{}{}:
+			default:
+			}
+		})
+	}
+	reconfigured := b.reconfiguredChan()
+	timer := arm()
+	defer func() {
+		timer.Stop()
+	}()
+
+	for {
+		// Stop reading once ctx is done, even if more data is ready.
+		if err := ctx.Err(); err != nil {
+			_, _ = b.ForcedFlushContext(context.WithoutCancel(ctx))
+			return err
+		}
+
+		select {
+		case data, ok := <-in:
+			if !ok {
+				_, _ = b.ForcedFlushContext(ctx)
+				return nil
+			}
+			write(data)
+		case <-fire:
+			b.Flush()
+			timer = arm()
+		case <-reconfigured:
+			timer.Stop()
+			reconfigured = b.reconfiguredChan()
+			timer = arm()
+		case <-ctx.Done():
+			_, _ = b.ForcedFlushContext(context.WithoutCancel(ctx))
+			return ctx.Err()
+		}
+	}
+}
+
+// NextFlushTime returns when the pending batch may next be due to be written
+// because of the interval, the maximum item age, debouncing or the schedule,
+// so that callers driving the Batcher from their own loop can wait until
+// then before calling Flush instead of polling it. It is in the past if the
+// batch is already due. The batch is not necessarily written at that time,
+// for instance if it is below the minimum size, so callers should ask again
+// after calling Flush.
+func (b *Batcher) NextFlushTime() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.nextFlushTime()
+}
+
+func (b *Batcher) nextFlushTime() time.Time {
+	next := b.lastSent.Add(b.currentInterval)
+	if b.quietPeriod > 0 && len(b.batch) > 0 {
+		next = b.debounceDeadline()
+	}
+	if b.maxItemAge > 0 && len(b.batch) > 0 && b.ageDeadline.Before(next) {
+		next = b.ageDeadline
+	}
+	if b.schedule != nil && !b.nextScheduled.IsZero() && b.nextScheduled.Before(next) {
+		next = b.nextScheduled
+	}
+	return next
+}
+
+// untilDue returns how long to wait before the pending batch may be due to
+// be written. It never returns a duration of zero or less so that callers
+// waiting for it do not spin while there is nothing to write.
+func (b *Batcher) untilDue() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	d := b.nextFlushTime().Sub(b.clock.Now())
+	if d <= 0 {
+		d = b.currentInterval
+	}
+	return max(d, time.Millisecond)
+}
@@ -0,0 +1,93 @@
+package batching_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Run", func() {
+	It("writes the received data in batches", func() {
+		writer := &syncRecordingWriter{}
+		b := batching.NewBatcher(2, time.Hour, writer)
+		in := make(chan interface{})
+		done := make(chan error)
+		go func() {
+			done <- b.Run(context.Background(), in)
+		}()
+
+		in <- "a"
+		in <- "b"
+		in <- "c"
+		close(in)
+
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(writer.all()).To(Equal([][]interface{}{{"a", "b"}, {"c"}}))
+	})
+
+	It("flushes when the interval lapses", func() {
+		writer := &syncRecordingWriter{}
+		b := batching.NewBatcher(10, 10*time.Millisecond, writer)
+		in := make(chan interface{})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			_ = b.Run(ctx, in)
+		}()
+
+		in <- "a"
+
+		Eventually(writer.all).Should(Equal([][]interface{}{{"a"}}))
+	})
+
+	It("writes pending data and returns once the context is done", func() {
+		writer := &syncRecordingWriter{}
+		b := batching.NewBatcher(10, time.Hour, writer)
+		in := make(chan interface{})
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- b.Run(ctx, in)
+		}()
+
+		in <- "a"
+		cancel()
+
+		Eventually(done).Should(Receive(MatchError(context.Canceled)))
+		Expect(writer.all()).To(Equal([][]interface{}{{"a"}}))
+	})
+
+	It("runs a ByteBatcher", func() {
+		writer := &spyByteWriter{}
+		b := batching.NewByteBatcher(10, time.Hour, writer)
+		in := make(chan []byte, 1)
+
+		in <- []byte("a")
+		close(in)
+
+		Expect(b.Run(context.Background(), in)).To(Succeed())
+		Expect(writer.batch).To(Equal([][]byte{[]byte("a")}))
+	})
+})
+
+type syncRecordingWriter struct {
+	mu      sync.Mutex
+	batches [][]interface{}
+}
+
+func (w *syncRecordingWriter) Write(batch []interface{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.batches = append(w.batches, batch)
+}
+
+func (w *syncRecordingWriter) all() [][]interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([][]interface{}(nil), w.batches...)
+}
@@ -0,0 +1,59 @@
+package batching
+
+import (
+	"context"
+	"os"
+)
+
+// Runner runs a Batcher as a component of a process, flushing it in the
+// background until it is told to stop and closing it on the way out. Its Run
+// method fits supervisors such as errgroup, and SignalRunner adapts it to
+// supervisors that stop components with a signal, such as ifrit. Runner
+// should be created with NewRunner().
+type Runner struct {
+	b *Batcher
+}
+
+// NewRunner creates a new Runner for b. Since b is flushed from another
+// goroutine once the Runner runs, it is made safe for concurrent use as if
+// created WithLocking, so NewRunner must be called before b is shared
+// between goroutines.
+func NewRunner(b *Batcher) *Runner {
+	b.ensureLocking()
+	return &Runner{b: b}
+}
+
+// Run flushes the Batcher whenever the batch is due until ctx is done, then
+// closes the Batcher, writing any pending data. It returns the error of the
+// final write, or ErrClosed if the Batcher was already closed.
+func (r *Runner) Run(ctx context.Context) error {
+	r.b.StartAutoFlush(ctx)
+	<-ctx.Done()
+
+	return r.b.Close()
+}
+
+// SignalRunner returns a runner that runs r until it receives a signal.
+func (r *Runner) SignalRunner() SignalRunner {
+	return SignalRunner{r: r}
+}
+
+// SignalRunner runs a Runner until it receives a signal. It implements the
+// ifrit.Runner interface without depending on ifrit.
+type SignalRunner struct {
+	r *Runner
+}
+
+// Run runs the Runner, closing ready once the Batcher is being flushed, and
+// stops it once a signal is received on signals.
+func (s SignalRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.r.b.StartAutoFlush(ctx)
+	close(ready)
+	<-signals
+	cancel()
+
+	return s.r.b.Close()
+}
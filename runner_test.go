@@ -0,0 +1,58 @@
+package batching_test
+
+import (
+	"context"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Runner", func() {
+	var (
+		writer *countingWriter
+		b      *batching.Batcher
+		r      *batching.Runner
+	)
+
+	BeforeEach(func() {
+		writer = &countingWriter{}
+		b = batching.NewBatcher(10, time.Millisecond, writer)
+		r = batching.NewRunner(b)
+	})
+
+	It("flushes the Batcher until ctx is done and closes it", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		errs := make(chan error, 1)
+		go func() {
+			errs <- r.Run(ctx)
+		}()
+
+		b.Write("a")
+		Eventually(writer.items).Should(Equal(1))
+
+		cancel()
+		Eventually(errs).Should(Receive(BeNil()))
+		Expect(b.Close()).To(MatchError(batching.ErrClosed))
+	})
+
+	It("runs until a signal is received", func() {
+		signals := make(chan os.Signal, 1)
+		ready := make(chan struct{})
+		errs := make(chan error, 1)
+		go func() {
+			errs <- r.SignalRunner().Run(signals, ready)
+		}()
+		Eventually(ready).Should(BeClosed())
+
+		b.Write("a")
+		Eventually(writer.items).Should(Equal(1))
+
+		signals <- os.Interrupt
+		Eventually(errs).Should(Receive(BeNil()))
+		Expect(b.Close()).To(MatchError(batching.ErrClosed))
+	})
+})
@@ -0,0 +1,190 @@
+// Package s3batching uploads batches as objects to S3 compatible object
+// stores through a Client, which adapts the S3 client in use.
+package s3batching
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+// Part is a part of a multipart upload that was uploaded.
+type Part struct {
+	Number int
+	ETag   string
+}
+
+// Client uploads objects to an S3 compatible object store. The bodies passed
+// to it are reused once the methods return.
+type Client interface {
+	// PutObject uploads an object in a single request.
+	PutObject(ctx context.Context, bucket, key string, body []byte) error
+
+	// CreateMultipartUpload starts a multipart upload and returns its ID.
+	CreateMultipartUpload(ctx context.Context, bucket, key string) (uploadID string, err error)
+
+	// UploadPart uploads a part of a multipart upload and returns its
+	// ETag. Parts are numbered from 1.
+	UploadPart(ctx context.Context, bucket, key, uploadID string, number int, body []byte) (etag string, err error)
+
+	// CompleteMultipartUpload assembles the uploaded parts into the
+	// object.
+	CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []Part) error
+
+	// AbortMultipartUpload discards a multipart upload and its parts.
+	AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error
+}
+
+// KeyData is the data the key template is executed with for every batch.
+type KeyData struct {
+	// Time is when the batch is uploaded, in UTC.
+	Time time.Time
+
+	// Sequence is the sequence number of the batch. It is the one of the
+	// batching.BatchInfo if the Batcher was created WithBatchInfo, and
+	// otherwise counts the batches uploaded by the writer, starting at 1.
+	Sequence uint64
+
+	// ID is the ID of the batch if the Batcher was created WithBatchIDs.
+	ID string
+}
+
+// DefaultPartSize is the default size of the parts of multipart uploads.
+const DefaultPartSize = 8 << 20
+
+// Option configures the writer returned by NewWriter.
+type Option func(w *writer)
+
+// WithEncoding sets the encoding of the objects. It defaults to
+// batching.JSONLines.
+func WithEncoding(enc batching.Encoding) Option {
+	return func(w *writer) {
+		w.enc = enc
+	}
+}
+
+// WithPartSize sets the size of the parts of multipart uploads. Objects
+// larger than partSize are uploaded in parts of partSize bytes, the last
+// part being smaller. It defaults to DefaultPartSize and must be at least
+// the minimum part size of the object store, 5 MiB for S3.
+func WithPartSize(partSize int) Option {
+	return func(w *writer) {
+		w.partSize = partSize
+	}
+}
+
+// NewWriter returns a batching.ContextWriter that uploads every batch as an
+// object to bucket. key is a text/template executed with KeyData for every
+// batch, for instance
+//
+//	logs/{{.Time.Format "2006/01/02/15"}}/{{.Sequence}}.jsonl
+//
+// so that the keys of the batches differ.
+func NewWriter(c Client, bucket, key string, opts ...Option) (batching.ContextWriter, error) {
+	tmpl, err := template.New("key").Parse(key)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &writer{
+		client:   c,
+		bucket:   bucket,
+		key:      tmpl,
+		enc:      batching.JSONLines,
+		partSize: DefaultPartSize,
+		now:      time.Now,
+	}
+	for _, o := range opts {
+		o(w)
+	}
+
+	return w, nil
+}
+
+type writer struct {
+	client   Client
+	bucket   string
+	key      *template.Template
+	enc      batching.Encoding
+	partSize int
+	now      func() time.Time
+	sequence atomic.Uint64
+	bufs     sync.Pool
+}
+
+func (w *writer) Write(ctx context.Context, batch []interface{}) error {
+	key, err := w.keyOf(ctx)
+	if err != nil {
+		return err
+	}
+
+	buf, ok := w.bufs.Get().(*bytes.Buffer)
+	if !ok {
+		buf = &bytes.Buffer{}
+	}
+	defer func() {
+		buf.Reset()
+		w.bufs.Put(buf)
+	}()
+	if err := w.enc(buf, batch); err != nil {
+		return err
+	}
+
+	body := buf.Bytes()
+	if w.partSize <= 0 || len(body) <= w.partSize {
+		return w.client.PutObject(ctx, w.bucket, key, body)
+	}
+	return w.uploadParts(ctx, key, body)
+}
+
+func (w *writer) uploadParts(ctx context.Context, key string, body []byte) error {
+	uploadID, err := w.client.CreateMultipartUpload(ctx, w.bucket, key)
+	if err != nil {
+		return err
+	}
+
+	var parts []Part
+	for n := 1; len(body) > 0; n++ {
+		size := min(w.partSize, len(body))
+		etag, err := w.client.UploadPart(ctx, w.bucket, key, uploadID, n, body[:size])
+		if err != nil {
+			return w.abort(ctx, key, uploadID, err)
+		}
+		parts = append(parts, Part{Number: n, ETag: etag})
+		body = body[size:]
+	}
+
+	if err := w.client.CompleteMultipartUpload(ctx, w.bucket, key, uploadID, parts); err != nil {
+		return w.abort(ctx, key, uploadID, err)
+	}
+	return nil
+}
+
+// abort discards the multipart upload after err, even if ctx is done.
+func (w *writer) abort(ctx context.Context, key, uploadID string, err error) error {
+	abortErr := w.client.AbortMultipartUpload(context.WithoutCancel(ctx), w.bucket, key, uploadID)
+	return errors.Join(err, abortErr)
+}
+
+func (w *writer) keyOf(ctx context.Context) (string, error) {
+	data := KeyData{Time: w.now().UTC()}
+	if info, ok := batching.BatchInfoFromContext(ctx); ok {
+		data.Sequence = info.Sequence
+		data.ID = info.ID
+	} else {
+		data.Sequence = w.sequence.Add(1)
+	}
+
+	var b strings.Builder
+	if err := w.key.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
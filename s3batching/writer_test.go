@@ -0,0 +1,131 @@
+package s3batching_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+	"code.cloudfoundry.org/go-batching/s3batching"
+)
+
+type fakeClient struct {
+	objects  map[string]string
+	uploads  map[string][]string
+	aborted  []string
+	partErr  error
+	calls    []string
+	uploadID int
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{objects: map[string]string{}, uploads: map[string][]string{}}
+}
+
+func (c *fakeClient) PutObject(_ context.Context, bucket, key string, body []byte) error {
+	c.calls = append(c.calls, "put")
+	c.objects[bucket+"/"+key] = string(body)
+	return nil
+}
+
+func (c *fakeClient) CreateMultipartUpload(_ context.Context, bucket, key string) (string, error) {
+	c.calls = append(c.calls, "create")
+	c.uploadID++
+	return fmt.Sprint(c.uploadID), nil
+}
+
+func (c *fakeClient) UploadPart(_ context.Context, _, _, uploadID string, number int, body []byte) (string, error) {
+	c.calls = append(c.calls, fmt.Sprintf("part %d", number))
+	if c.partErr != nil {
+		return "", c.partErr
+	}
+	c.uploads[uploadID] = append(c.uploads[uploadID], string(body))
+	return fmt.Sprintf("etag-%d", number), nil
+}
+
+func (c *fakeClient) CompleteMultipartUpload(_ context.Context, bucket, key, uploadID string, parts []s3batching.Part) error {
+	c.calls = append(c.calls, "complete")
+	var body strings.Builder
+	for i, part := range parts {
+		Expect(part).To(Equal(s3batching.Part{Number: i + 1, ETag: fmt.Sprintf("etag-%d", i+1)}))
+		body.WriteString(c.uploads[uploadID][i])
+	}
+	c.objects[bucket+"/"+key] = body.String()
+	return nil
+}
+
+func (c *fakeClient) AbortMultipartUpload(_ context.Context, _, _, uploadID string) error {
+	c.calls = append(c.calls, "abort")
+	c.aborted = append(c.aborted, uploadID)
+	return nil
+}
+
+var _ = Describe("Writer", func() {
+	var client *fakeClient
+
+	BeforeEach(func() {
+		client = newFakeClient()
+	})
+
+	It("uploads every batch as an object", func() {
+		w, err := s3batching.NewWriter(client, "bucket", "logs/{{.Sequence}}.jsonl")
+		Expect(err).ToNot(HaveOccurred())
+		b := batching.NewContextBatcher(2, time.Minute, w)
+
+		b.WriteAll("a", "b", "c", "d")
+
+		Expect(client.objects).To(Equal(map[string]string{
+			"bucket/logs/1.jsonl": "\"a\"\n\"b\"\n",
+			"bucket/logs/2.jsonl": "\"c\"\n\"d\"\n",
+		}))
+		Expect(client.calls).To(Equal([]string{"put", "put"}))
+	})
+
+	It("uses the batch info of the Batcher and the time in the key", func() {
+		w, err := s3batching.NewWriter(client, "bucket", `{{.Time.Format "2006"}}/{{.Sequence}}-{{.ID}}`)
+		Expect(err).ToNot(HaveOccurred())
+		b := batching.NewContextBatcher(1, time.Minute, w, batching.WithBatchInfo(), batching.WithBatchIDs())
+
+		b.Write("a")
+
+		Expect(client.objects).To(HaveLen(1))
+		for key := range client.objects {
+			Expect(key).To(MatchRegexp(`^bucket/%d/1-[0-9a-f-]{36}$`, time.Now().UTC().Year()))
+		}
+	})
+
+	It("uploads large batches in parts", func() {
+		w, err := s3batching.NewWriter(client, "bucket", "batch", s3batching.WithPartSize(5))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(w.Write(context.Background(), []interface{}{"abc", "def"})).To(Succeed())
+
+		Expect(client.calls).To(Equal([]string{"create", "part 1", "part 2", "part 3", "complete"}))
+		Expect(client.uploads["1"]).To(Equal([]string{"\"abc\"", "\n\"def", "\"\n"}))
+		Expect(client.objects["bucket/batch"]).To(Equal("\"abc\"\n\"def\"\n"))
+	})
+
+	It("aborts multipart uploads that fail", func() {
+		client.partErr = errors.New("part failed")
+		w, err := s3batching.NewWriter(client, "bucket", "batch", s3batching.WithPartSize(5))
+		Expect(err).ToNot(HaveOccurred())
+
+		err = w.Write(context.Background(), []interface{}{"abc", "def"})
+
+		Expect(err).To(MatchError(ContainSubstring("part failed")))
+		Expect(client.calls).To(Equal([]string{"create", "part 1", "abort"}))
+		Expect(client.aborted).To(Equal([]string{"1"}))
+		Expect(client.objects).To(BeEmpty())
+	})
+
+	It("rejects invalid key templates", func() {
+		_, err := s3batching.NewWriter(client, "bucket", "{{.Sequence")
+
+		Expect(err).To(HaveOccurred())
+	})
+})
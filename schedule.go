@@ -0,0 +1,152 @@
+package batching
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithSchedule writes the pending batch at the times returned by next, in
+// addition to the usual batch size and interval triggers, for instance
+// right before a billing or rollup cutoff. next is passed the current time
+// and returns the next time at or after which the batch is to be written,
+// or the zero time if there is none.
+// Like the interval, the schedule is checked by Flush.
+func WithSchedule(next func(after time.Time) time.Time) Option {
+	return func(b *Batcher) {
+		b.schedule = next
+	}
+}
+
+// startSchedule computes the first scheduled flush.
+func (b *Batcher) startSchedule() {
+	if b.schedule != nil {
+		b.nextScheduled = b.schedule(b.clock.Now())
+	}
+}
+
+// scheduled reports whether a scheduled flush is due, in which case the
+// following one is computed.
+func (b *Batcher) scheduled() bool {
+	if b.schedule == nil || b.nextScheduled.IsZero() {
+		return false
+	}
+
+	now := b.clock.Now()
+	if now.Before(b.nextScheduled) {
+		return false
+	}
+	b.nextScheduled = b.schedule(now.Add(time.Nanosecond))
+	return true
+}
+
+// CronSchedule returns a schedule for WithSchedule from a cron expression
+// with the five fields minute, hour, day of month, month and day of week.
+// Each field is either *, or a comma separated list of values and ranges
+// such as 1-5, optionally followed by a step such as */15 or 0-30/10. Days
+// of week range from 0 (Sunday) to 6. As with cron, a time matches if
+// either the day of month or the day of week matches when both are
+// restricted. Times are matched in the location of the time passed to the
+// schedule.
+func CronSchedule(spec string) (func(after time.Time) time.Time, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("batching: cron expression %q must have 5 fields", spec)
+	}
+
+	var c cron
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+	sets := [5]*uint64{&c.minutes, &c.hours, &c.days, &c.months, &c.weekdays}
+	for i, field := range fields {
+		set, err := parseCronField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("batching: cron expression %q: %w", spec, err)
+		}
+		*sets[i] = set
+	}
+	c.anyDay = fields[2] == "*"
+	c.anyWeekday = fields[4] == "*"
+
+	return c.next, nil
+}
+
+type cron struct {
+	minutes, hours, days, months, weekdays uint64
+	anyDay, anyWeekday                     bool
+}
+
+// next returns the first time at or after t, at the start of a minute, that
+// matches the expression. It returns the zero time if there is none within
+// five years.
+func (c cron) next(t time.Time) time.Time {
+	if t.Truncate(time.Minute) != t {
+		t = t.Truncate(time.Minute).Add(time.Minute)
+	}
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case c.months&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hours&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case c.minutes&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c cron) dayMatches(t time.Time) bool {
+	day := c.days&(1<<uint(t.Day())) != 0
+	weekday := c.weekdays&(1<<uint(t.Weekday())) != 0
+	if c.anyDay || c.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+func parseCronField(field string, lo, hi int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			var err error
+			rng = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+		}
+
+		from, to := lo, hi
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if from, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid value in %q", part)
+			}
+			to = from
+			if len(bounds) == 2 {
+				if to, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("invalid value in %q", part)
+				}
+			} else if step > 1 {
+				to = hi
+			}
+		}
+		if from < lo || to > hi || from > to {
+			return 0, fmt.Errorf("%q is out of range %d-%d", part, lo, hi)
+		}
+
+		for v := from; v <= to; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
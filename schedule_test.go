@@ -0,0 +1,88 @@
+package batching_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Schedule", func() {
+	It("writes the pending batch at the scheduled times", func() {
+		clock := &fakeClock{now: time.Date(2026, 1, 2, 3, 58, 0, 0, time.UTC)}
+		writer := &spyWriter{}
+		hourly := func(after time.Time) time.Time {
+			return after.Truncate(time.Hour).Add(time.Hour)
+		}
+		b := batching.NewBatcher(10, time.Hour, writer,
+			batching.WithClock(clock),
+			batching.WithSchedule(hourly),
+		)
+
+		b.Write("item")
+		clock.Advance(time.Minute)
+		Expect(b.Flush().Written).To(BeFalse())
+
+		clock.Advance(time.Minute)
+		res := b.Flush()
+		Expect(res.Written).To(BeTrue())
+		Expect(res.Reason).To(Equal(batching.FlushScheduled))
+
+		b.Write("item")
+		Expect(b.Flush().Written).To(BeFalse())
+	})
+
+	It("does not write if the schedule has no next time", func() {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		writer := &spyWriter{}
+		b := batching.NewBatcher(10, time.Hour, writer,
+			batching.WithClock(clock),
+			batching.WithSchedule(func(time.Time) time.Time { return time.Time{} }),
+		)
+
+		b.Write("item")
+		clock.Advance(time.Minute)
+
+		Expect(b.Flush().Written).To(BeFalse())
+	})
+})
+
+var _ = Describe("CronSchedule", func() {
+	at := func(s string) time.Time {
+		t, err := time.Parse(time.RFC3339, s)
+		Expect(err).ToNot(HaveOccurred())
+		return t
+	}
+
+	DescribeTable("returns the next matching time",
+		func(spec, after, next string) {
+			schedule, err := batching.CronSchedule(spec)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(schedule(at(after))).To(Equal(at(next)))
+		},
+		Entry("every minute", "* * * * *", "2026-01-02T03:04:05Z", "2026-01-02T03:05:00Z"),
+		Entry("at the start of a minute", "* * * * *", "2026-01-02T03:04:00Z", "2026-01-02T03:04:00Z"),
+		Entry("steps", "*/15 * * * *", "2026-01-02T03:16:00Z", "2026-01-02T03:30:00Z"),
+		Entry("lists and ranges", "0 9-17/4,23 * * *", "2026-01-02T18:00:00Z", "2026-01-02T23:00:00Z"),
+		Entry("before the end of the month", "55 23 28-31 * *", "2026-02-01T00:00:00Z", "2026-02-28T23:55:00Z"),
+		Entry("on weekdays", "0 0 * * 1-5", "2026-01-03T00:00:00Z", "2026-01-05T00:00:00Z"),
+		Entry("either day of month or day of week", "0 0 15 * 0", "2026-01-05T00:00:00Z", "2026-01-11T00:00:00Z"),
+		Entry("in a later year", "0 0 1 1 *", "2026-01-01T00:01:00Z", "2027-01-01T00:00:00Z"),
+	)
+
+	DescribeTable("rejects invalid expressions",
+		func(spec string) {
+			_, err := batching.CronSchedule(spec)
+
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("too few fields", "* * * *"),
+		Entry("out of range", "60 * * * *"),
+		Entry("invalid step", "*/0 * * * *"),
+		Entry("invalid value", "a * * * *"),
+		Entry("reversed range", "5-1 * * * *"),
+	)
+})
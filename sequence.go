@@ -0,0 +1,138 @@
+package batching
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// BatchInfo identifies a batch submitted to the writer, allowing the
+// downstream to detect duplicate and missing batches.
+type BatchInfo struct {
+	// Sequence increases by one for each batch the Batcher hands to the
+	// writer, starting at 1. Batches the circuit breaker or the writer pool
+	// did not hand over do not use up a number. Retries by the Backoff, and
+	// batches retained by the RetryPolicy, are written again with the same
+	// BatchInfo and the same elements, while data added in the meantime is
+	// written in later batches.
+	Sequence uint64
+
+	// ID is a random UUID identifying the batch. It is only set WithBatchIDs.
+	ID string
+}
+
+// SequencedWriter is used to submit the completed batch along with the
+// BatchInfo stamped on it by the Batcher. What happens to a batch that
+// failed to write is decided by the Batcher's RetryPolicy.
+type SequencedWriter interface {
+	// Write submits the batch.
+	Write(batch []interface{}, info BatchInfo) error
+}
+
+// SequencedWriterFunc is an adapter to allow ordinary functions to be a
+// SequencedWriter.
+type SequencedWriterFunc func(batch []interface{}, info BatchInfo) error
+
+// Write implements SequencedWriter.
+func (f SequencedWriterFunc) Write(batch []interface{}, info BatchInfo) error {
+	return f(batch, info)
+}
+
+// NewSequencedBatcher creates a new Batcher that submits batches to a
+// SequencedWriter.
+func NewSequencedBatcher(size int, interval time.Duration, writer SequencedWriter, opts ...Option) *Batcher {
+	opts = append([]Option{WithBatchInfo()}, opts...)
+	return newBatcher(size, interval, sequencedContextWriter{w: writer}, opts)
+}
+
+// WithBatchInfo stamps each batch with a BatchInfo that a ContextWriter can
+// get from the context of the write with BatchInfoFromContext. Batchers
+// created with NewSequencedBatcher always stamp batches.
+func WithBatchInfo() Option {
+	return func(b *Batcher) {
+		b.stampBatches = true
+	}
+}
+
+// WithBatchIDs stamps each batch with a BatchInfo like WithBatchInfo, also
+// setting a random UUID, for downstreams that deduplicate on an idempotency
+// key rather than on the sequence number.
+func WithBatchIDs() Option {
+	return func(b *Batcher) {
+		b.stampBatches = true
+		b.batchIDs = true
+	}
+}
+
+// BatchInfoFromContext returns the BatchInfo of the batch being written by a
+// Batcher that stamps batches.
+func BatchInfoFromContext(ctx context.Context) (BatchInfo, bool) {
+	info, ok := ctx.Value(batchInfoKey{}).(BatchInfo)
+	return info, ok
+}
+
+type batchInfoKey struct{}
+
+// stamp returns ctx carrying the BatchInfo of the next batch, if the Batcher
+// stamps batches. A retained batch keeps its BatchInfo. The sequence number
+// is only used up once the batch was handed over, see handedOver.
+func (b *Batcher) stamp(ctx context.Context) context.Context {
+	if !b.stampBatches {
+		return ctx
+	}
+
+	b.stamped = b.retainedInfo
+	if b.retained == 0 {
+		b.stamped = BatchInfo{Sequence: b.sequence + 1}
+		if b.batchIDs {
+			b.stamped.ID = newUUID()
+		}
+	}
+	return context.WithValue(ctx, batchInfoKey{}, b.stamped)
+}
+
+// handedOver uses up the sequence number of the batch that was just
+// submitted, unless the circuit breaker or the writer pool did not hand it
+// to the writer.
+func (b *Batcher) handedOver(err error) {
+	if !b.stampBatches {
+		return
+	}
+	if err != nil {
+		if _, ok := asNotSubmitted(err); ok || errors.Is(err, ErrCircuitOpen) {
+			return
+		}
+	}
+	b.sequence = max(b.sequence, b.stamped.Sequence)
+}
+
+// retain keeps the first n pending elements together with the BatchInfo
+// they were stamped with, so that they are written again as the same batch.
+func (b *Batcher) retain(n int) {
+	if !b.stampBatches {
+		return
+	}
+	b.retained = n
+	b.retainedInfo = b.stamped
+}
+
+// newUUID returns a random version 4 UUID.
+func newUUID() string {
+	var u [16]byte
+	_, _ = rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+// sequencedContextWriter adapts a SequencedWriter to a ContextWriter.
+type sequencedContextWriter struct {
+	w SequencedWriter
+}
+
+func (w sequencedContextWriter) Write(ctx context.Context, batch []interface{}) error {
+	info, _ := BatchInfoFromContext(ctx)
+	return w.w.Write(batch, info)
+}
@@ -0,0 +1,186 @@
+package batching_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("SequencedBatcher", func() {
+	var infos []batching.BatchInfo
+
+	BeforeEach(func() {
+		infos = nil
+	})
+
+	record := func(err error) batching.SequencedWriter {
+		return batching.SequencedWriterFunc(func(_ []interface{}, info batching.BatchInfo) error {
+			infos = append(infos, info)
+			return err
+		})
+	}
+
+	It("stamps each batch with an increasing sequence number", func() {
+		b := batching.NewSequencedBatcher(2, time.Minute, record(nil))
+		b.WriteAll("a", "b", "c", "d", "e")
+		b.ForcedFlush()
+
+		Expect(infos).To(Equal([]batching.BatchInfo{
+			{Sequence: 1},
+			{Sequence: 2},
+			{Sequence: 3},
+		}))
+	})
+
+	It("stamps each batch with a random UUID WithBatchIDs", func() {
+		b := batching.NewSequencedBatcher(1, time.Minute, record(nil), batching.WithBatchIDs())
+		b.WriteAll("a", "b")
+
+		Expect(infos).To(HaveLen(2))
+		Expect(infos[0].ID).To(MatchRegexp(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`))
+		Expect(infos[1].ID).NotTo(Equal(infos[0].ID))
+	})
+
+	It("keeps the sequence number when the Backoff retries", func() {
+		b := batching.NewSequencedBatcher(1, time.Minute, record(errors.New("failed")),
+			batching.WithBackoff(batching.Backoff{InitialInterval: time.Nanosecond, MaxAttempts: 3}),
+		)
+		b.Write("a")
+		b.Write("b")
+
+		Expect(infos).To(Equal([]batching.BatchInfo{
+			{Sequence: 1}, {Sequence: 1}, {Sequence: 1},
+			{Sequence: 2}, {Sequence: 2}, {Sequence: 2},
+		}))
+	})
+
+	It("makes the BatchInfo available to ContextWriters", func() {
+		var info batching.BatchInfo
+		writer := batching.ContextWriterFunc(func(ctx context.Context, _ []interface{}) error {
+			info, _ = batching.BatchInfoFromContext(ctx)
+			return nil
+		})
+		b := batching.NewContextBatcher(1, time.Minute, writer, batching.WithBatchInfo())
+		b.Write("a")
+		b.Write("b")
+
+		Expect(info.Sequence).To(Equal(uint64(2)))
+	})
+
+	It("writes a retained batch again with the same BatchInfo and elements", func() {
+		var batches [][]interface{}
+		fail := true
+		writer := batching.SequencedWriterFunc(func(batch []interface{}, info batching.BatchInfo) error {
+			infos = append(infos, info)
+			batches = append(batches, batch)
+			if fail {
+				return errors.New("failed")
+			}
+			return nil
+		})
+		b := batching.NewSequencedBatcher(10, time.Minute, writer,
+			batching.WithBatchIDs(),
+			batching.WithRetryPolicy(batching.RetainOnError(0)),
+		)
+
+		b.WriteAll("a", "b")
+		b.ForcedFlush()
+		b.Write("c")
+		fail = false
+		b.ForcedFlush()
+
+		Expect(batches).To(Equal([][]interface{}{{"a", "b"}, {"a", "b"}, {"c"}}))
+		Expect(infos).To(HaveLen(3))
+		Expect(infos[1]).To(Equal(infos[0]))
+		Expect(infos[2].Sequence).To(Equal(uint64(2)))
+		Expect(infos[2].ID).NotTo(Equal(infos[0].ID))
+	})
+
+	It("does not use up sequence numbers for batches the circuit breaker sheds", func() {
+		fail := true
+		writer := batching.SequencedWriterFunc(func(_ []interface{}, info batching.BatchInfo) error {
+			infos = append(infos, info)
+			if fail {
+				return errors.New("failed")
+			}
+			return nil
+		})
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		b := batching.NewSequencedBatcher(1, time.Minute, writer,
+			batching.WithClock(clock),
+			batching.WithCircuitBreaker(batching.CircuitBreaker{
+				FailureThreshold: 1,
+				Cooldown:         time.Second,
+				Policy:           batching.Shed,
+			}),
+		)
+
+		b.Write("a")
+		b.Write("b")
+		b.Write("c")
+		clock.Advance(time.Second)
+		fail = false
+		b.Write("d")
+
+		Expect(infos).To(Equal([]batching.BatchInfo{{Sequence: 1}, {Sequence: 2}}))
+	})
+
+	It("does not use up sequence numbers for batches the circuit breaker buffers", func() {
+		fail := true
+		writer := batching.SequencedWriterFunc(func(_ []interface{}, info batching.BatchInfo) error {
+			infos = append(infos, info)
+			if fail {
+				return errors.New("failed")
+			}
+			return nil
+		})
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		b := batching.NewSequencedBatcher(1, time.Minute, writer,
+			batching.WithClock(clock),
+			batching.WithCircuitBreaker(batching.CircuitBreaker{
+				FailureThreshold: 1,
+				Cooldown:         time.Second,
+			}),
+		)
+
+		b.Write("a")
+		b.Write("b")
+		b.ForcedFlush()
+		clock.Advance(time.Second)
+		fail = false
+		b.ForcedFlush()
+
+		Expect(infos).To(Equal([]batching.BatchInfo{{Sequence: 1}, {Sequence: 2}}))
+	})
+
+	It("does not use up sequence numbers for batches the writer pool refuses", func() {
+		release := make(chan struct{})
+		written := make(chan batching.BatchInfo, 2)
+		writer := batching.SequencedWriterFunc(func(_ []interface{}, info batching.BatchInfo) error {
+			<-release
+			written <- info
+			return nil
+		})
+		b := batching.NewSequencedBatcher(1, time.Minute, writer,
+			batching.WithWriterConcurrency(1),
+			batching.WithMaxInFlight(1, batching.Fail),
+		)
+
+		b.Write("a")
+		Expect(b.WriteContext(context.Background(), "b")).To(MatchError(batching.ErrBackpressure))
+		close(release)
+		Eventually(written).Should(Receive(Equal(batching.BatchInfo{Sequence: 1})))
+		Eventually(func() error {
+			_, err := b.ForcedFlushContext(context.Background())
+			return err
+		}).Should(Succeed())
+
+		Eventually(written).Should(Receive(Equal(batching.BatchInfo{Sequence: 2})))
+		Expect(b.Close()).To(Succeed())
+	})
+})
@@ -0,0 +1,80 @@
+package batching
+
+import (
+	"errors"
+	"hash/maphash"
+	"sync/atomic"
+	"time"
+)
+
+// ShardedBatcher spreads data over several independently locked Batchers so
+// that many goroutines writing concurrently do not contend on a single lock.
+// Every shard batches and flushes its data on its own, so the writer is
+// invoked from multiple goroutines and must be safe for concurrent use. The
+// methods of a ShardedBatcher are safe to call from multiple goroutines.
+// ShardedBatcher should be created with NewShardedBatcher().
+type ShardedBatcher struct {
+	shards []*Batcher
+	next   atomic.Uint64
+	seed   maphash.Seed
+}
+
+// NewShardedBatcher creates a new ShardedBatcher with the given number of
+// shards. Every shard is a Batcher created with size, interval and opts.
+func NewShardedBatcher(shards, size int, interval time.Duration, writer Writer, opts ...Option) *ShardedBatcher {
+	s := &ShardedBatcher{
+		shards: make([]*Batcher, max(shards, 1)),
+		seed:   maphash.MakeSeed(),
+	}
+	for i := range s.shards {
+		s.shards[i] = NewBatcher(size, interval, writer, append(opts, WithLocking())...)
+	}
+
+	return s
+}
+
+// Write stores data in one of the shards, picking the shards in turn.
+func (s *ShardedBatcher) Write(data interface{}) {
+	i := (s.next.Add(1) - 1) % uint64(len(s.shards))
+	s.shards[i].Write(data)
+}
+
+// WriteKey stores data in the shard key hashes to, so that all data written
+// with the same key ends up in the same batches in the order it was written.
+func (s *ShardedBatcher) WriteKey(key string, data interface{}) {
+	i := maphash.String(s.seed, key) % uint64(len(s.shards))
+	s.shards[i].Write(data)
+}
+
+// Flush writes the pending data of every shard that is due to be written.
+func (s *ShardedBatcher) Flush() {
+	for _, b := range s.shards {
+		b.Flush()
+	}
+}
+
+// ForcedFlush writes the pending data of every shard.
+func (s *ShardedBatcher) ForcedFlush() {
+	for _, b := range s.shards {
+		b.ForcedFlush()
+	}
+}
+
+// Len returns the number of pending elements across all shards.
+func (s *ShardedBatcher) Len() int {
+	n := 0
+	for _, b := range s.shards {
+		n += b.Len()
+	}
+	return n
+}
+
+// Close closes every shard, writing any pending data. It returns the errors
+// of the shards that failed to close joined together.
+func (s *ShardedBatcher) Close() error {
+	errs := make([]error, 0, len(s.shards))
+	for _, b := range s.shards {
+		errs = append(errs, b.Close())
+	}
+	return errors.Join(errs...)
+}
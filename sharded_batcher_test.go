@@ -0,0 +1,73 @@
+package batching_test
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("ShardedBatcher", func() {
+	It("can be written to from multiple goroutines", func() {
+		writer := &countingWriter{}
+		b := batching.NewShardedBatcher(4, 10, time.Minute, writer)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					b.Write(j)
+				}
+			}()
+		}
+		wg.Wait()
+		Expect(b.Len()).To(BeNumerically("<", 40))
+
+		Expect(b.Close()).To(Succeed())
+		Expect(writer.items()).To(Equal(1000))
+	})
+
+	It("spreads data over the shards", func() {
+		writer := &recordingWriter{}
+		b := batching.NewShardedBatcher(2, 2, time.Minute, writer)
+
+		b.Write("a")
+		b.Write("b")
+		Expect(writer.batches).To(BeEmpty())
+
+		b.Write("c")
+		b.Write("d")
+		Expect(writer.batches).To(ConsistOf(
+			[]interface{}{"a", "c"},
+			[]interface{}{"b", "d"},
+		))
+	})
+
+	It("keeps data with the same key in the same shard", func() {
+		writer := &recordingWriter{}
+		b := batching.NewShardedBatcher(8, 3, time.Minute, writer)
+
+		b.WriteKey("key", "a")
+		b.WriteKey("key", "b")
+		b.WriteKey("key", "c")
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"a", "b", "c"}}))
+	})
+
+	It("flushes every shard", func() {
+		writer := &recordingWriter{}
+		b := batching.NewShardedBatcher(2, 10, time.Minute, writer)
+		b.Write("a")
+		b.Write("b")
+
+		b.ForcedFlush()
+
+		Expect(writer.batches).To(HaveLen(2))
+		Expect(b.Len()).To(Equal(0))
+	})
+})
@@ -0,0 +1,83 @@
+package batching
+
+import (
+	"slices"
+	"sync"
+)
+
+// SlidingWindowBatcher writes overlapping windows of the most recent
+// elements instead of disjoint batches, enabling rolling aggregations over
+// the same stream. Once length elements were written, the window of the
+// last length elements is written every slide elements, so consecutive
+// windows share length - slide elements. A slide larger than length skips
+// elements between windows. The methods of a SlidingWindowBatcher are safe
+// to call from multiple goroutines. SlidingWindowBatcher should be created
+// with NewSlidingWindowBatcher().
+type SlidingWindowBatcher struct {
+	mu     sync.Mutex
+	length int
+	slide  int
+	w      Writer
+	window []interface{}
+	since  int
+	closed bool
+}
+
+// NewSlidingWindowBatcher creates a new SlidingWindowBatcher. The writer
+// owns every window it is passed.
+func NewSlidingWindowBatcher(length, slide int, writer Writer) *SlidingWindowBatcher {
+	return &SlidingWindowBatcher{
+		length: max(length, 1),
+		slide:  max(slide, 1),
+		w:      writer,
+		window: make([]interface{}, 0, max(length, 1)),
+	}
+}
+
+// Write adds data to the window, evicting the oldest element once the
+// window is full, and writes the window if it is full and slide elements
+// were written since the last window. Data written after Close is dropped.
+func (s *SlidingWindowBatcher) Write(data interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	if len(s.window) == s.length {
+		copy(s.window, s.window[1:])
+		s.window[len(s.window)-1] = data
+	} else {
+		s.window = append(s.window, data)
+	}
+	s.since++
+
+	if len(s.window) == s.length && s.since >= s.slide {
+		s.since = 0
+		s.w.Write(slices.Clone(s.window))
+	}
+}
+
+// Len returns the number of elements in the window.
+func (s *SlidingWindowBatcher) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.window)
+}
+
+// Close closes the SlidingWindowBatcher. Unlike other batchers it does not
+// write the partial window, since an incomplete window would skew rolling
+// aggregations. Calling Close more than once returns ErrClosed.
+func (s *SlidingWindowBatcher) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+	s.closed = true
+	s.window = nil
+	return nil
+}
@@ -0,0 +1,70 @@
+package batching_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("SlidingWindowBatcher", func() {
+	var writer *recordingWriter
+
+	BeforeEach(func() {
+		writer = &recordingWriter{}
+	})
+
+	It("writes overlapping windows", func() {
+		b := batching.NewSlidingWindowBatcher(3, 1, writer)
+
+		for i := 1; i <= 5; i++ {
+			b.Write(i)
+		}
+
+		Expect(writer.batches).To(Equal([][]interface{}{
+			{1, 2, 3},
+			{2, 3, 4},
+			{3, 4, 5},
+		}))
+	})
+
+	It("slides the window by the given number of elements", func() {
+		b := batching.NewSlidingWindowBatcher(4, 2, writer)
+
+		for i := 1; i <= 9; i++ {
+			b.Write(i)
+		}
+
+		Expect(writer.batches).To(Equal([][]interface{}{
+			{1, 2, 3, 4},
+			{3, 4, 5, 6},
+			{5, 6, 7, 8},
+		}))
+		Expect(b.Len()).To(Equal(4))
+	})
+
+	It("skips elements if the slide is larger than the window", func() {
+		b := batching.NewSlidingWindowBatcher(2, 3, writer)
+
+		for i := 1; i <= 8; i++ {
+			b.Write(i)
+		}
+
+		Expect(writer.batches).To(Equal([][]interface{}{
+			{2, 3},
+			{5, 6},
+		}))
+	})
+
+	It("does not write partial windows on close", func() {
+		b := batching.NewSlidingWindowBatcher(3, 1, writer)
+
+		b.Write(1)
+		Expect(b.Close()).To(Succeed())
+		b.Write(2)
+		b.Write(3)
+
+		Expect(writer.batches).To(BeEmpty())
+		Expect(b.Close()).To(MatchError(batching.ErrClosed))
+	})
+})
@@ -0,0 +1,71 @@
+package batching
+
+import (
+	"fmt"
+	"time"
+)
+
+// Snapshot describes the internal state of a Batcher for admin and debug
+// endpoints. It can be serialized, for example with encoding/json.
+type Snapshot struct {
+	// Pending is the number of elements waiting to be written.
+	Pending int `json:"pending"`
+
+	// PendingBytes is the total size of the pending elements.
+	PendingBytes int `json:"pending_bytes"`
+
+	// OldestItemAge is how long the oldest pending element has been
+	// waiting. It is zero if there is no pending data.
+	OldestItemAge time.Duration `json:"oldest_item_age"`
+
+	// LastFlush is when the writer was last invoked and LastFlushReason
+	// why. LastFlushReason is empty if the writer was never invoked.
+	LastFlush       time.Time `json:"last_flush"`
+	LastFlushReason string    `json:"last_flush_reason,omitempty"`
+
+	// Failures is the number of times the pending batch failed to write
+	// and was retained by the RetryPolicy.
+	Failures int `json:"failures"`
+
+	// Closed reports whether the Batcher is closed.
+	Closed bool `json:"closed"`
+
+	// Stats are the counters of the Batcher.
+	Stats Stats `json:"stats"`
+}
+
+// DebugSnapshot returns a snapshot of the internal state of the Batcher.
+func (b *Batcher) DebugSnapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := b.snapshotStats()
+	s := Snapshot{
+		Pending:      len(b.batch),
+		PendingBytes: b.pendingBytes,
+		LastFlush:    stats.LastFlush,
+		Failures:     b.failures,
+		Closed:       b.closed,
+		Stats:        stats,
+	}
+	if len(b.batch) > 0 {
+		s.OldestItemAge = b.clock.Since(b.firstItemAt)
+	}
+	if !stats.LastFlush.IsZero() {
+		s.LastFlushReason = b.lastReason.String()
+	}
+	return s
+}
+
+// DebugSnapshot returns a snapshot of the internal state of the batch of
+// every key, keyed by the key formatted with fmt.Sprint.
+func (k *KeyedBatcher) DebugSnapshot() map[string]Snapshot {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	snapshots := make(map[string]Snapshot, len(k.keys))
+	for key, e := range k.keys {
+		snapshots[fmt.Sprint(key)] = e.b.DebugSnapshot()
+	}
+	return snapshots
+}
@@ -0,0 +1,69 @@
+package batching_test
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("DebugSnapshot", func() {
+	var clock *fakeClock
+
+	BeforeEach(func() {
+		clock = &fakeClock{now: time.Unix(0, 0)}
+	})
+
+	It("describes the state of the Batcher", func() {
+		writer := &recordingFallibleWriter{errs: []error{nil, errors.New("failed")}}
+		b := batching.NewFallibleBatcher(2, time.Minute, writer,
+			batching.WithClock(clock),
+			batching.WithRetryPolicy(batching.RetainOnError(0)),
+		)
+		b.WriteAll("a", "b")
+		clock.Advance(time.Second)
+		b.WriteAll("c", "d")
+		clock.Advance(time.Second)
+
+		s := b.DebugSnapshot()
+
+		Expect(s.Pending).To(Equal(2))
+		Expect(s.OldestItemAge).To(Equal(time.Second))
+		Expect(s.LastFlush).To(Equal(time.Unix(1, 0)))
+		Expect(s.LastFlushReason).To(Equal("size"))
+		Expect(s.Failures).To(Equal(1))
+		Expect(s.Closed).To(BeFalse())
+		Expect(s.Stats.WriteErrors).To(Equal(uint64(1)))
+	})
+
+	It("can be serialized", func() {
+		b := batching.NewBatcher(1, time.Minute, &spyWriter{}, batching.WithClock(clock))
+		b.Write("a")
+
+		data, err := json.Marshal(b.DebugSnapshot())
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"last_flush_reason":"size"`))
+		Expect(string(data)).To(ContainSubstring(`"Flushes":{"size":1}`))
+	})
+
+	It("describes every key of a KeyedBatcher", func() {
+		b := batching.NewKeyedBatcher(10, time.Minute,
+			func(data interface{}) interface{} { return len(data.(string)) },
+			batching.KeyedWriterFunc(func(interface{}, []interface{}) {}),
+		)
+		b.Write("a")
+		b.Write("bb")
+		b.Write("cc")
+
+		snapshots := b.DebugSnapshot()
+
+		Expect(snapshots).To(HaveLen(2))
+		Expect(snapshots["1"].Pending).To(Equal(1))
+		Expect(snapshots["2"].Pending).To(Equal(2))
+	})
+})
@@ -0,0 +1,24 @@
+package batching
+
+import "sort"
+
+// WithSort sorts the pending batch with less right before it is written, for
+// downstream bulk APIs that require or benefit from ordered data, such as
+// data ordered by timestamp or source. Elements that are equal keep the
+// order they were written in.
+func WithSort(less func(a, b interface{}) bool) Option {
+	return func(b *Batcher) {
+		b.less = less
+	}
+}
+
+func (b *Batcher) sort() {
+	if b.less == nil {
+		return
+	}
+	// A retained batch is written again as it was.
+	unsorted := b.batch[b.retained:]
+	sort.SliceStable(unsorted, func(i, j int) bool {
+		return b.less(unsorted[i], unsorted[j])
+	})
+}
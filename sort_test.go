@@ -0,0 +1,37 @@
+package batching_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Sort", func() {
+	byLength := func(a, b interface{}) bool {
+		return len(a.(string)) < len(b.(string))
+	}
+
+	It("sorts the batch before writing it", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(10, time.Minute, writer, batching.WithSort(byLength))
+		b.WriteAll("ccc", "a", "bb")
+
+		b.ForcedFlush()
+
+		Expect(writer.batch).To(Equal([]interface{}{"a", "bb", "ccc"}))
+	})
+
+	It("keeps the order of equal elements", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(10, time.Minute, writer, batching.WithSort(byLength))
+		b.WriteAll("bb", "x", "aa", "y")
+
+		b.ForcedFlush()
+
+		Expect(writer.batch).To(Equal([]interface{}{"x", "y", "bb", "aa"}))
+	})
+
+})
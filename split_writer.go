@@ -0,0 +1,50 @@
+package batching
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// SplitWriter returns a ContextWriter that partitions every batch by the
+// value discriminator returns for each element and writes each partition to
+// the writer of that value, for example to separate logs, metrics and traces
+// written to a single Batcher. Partitions are written in the order their
+// first element appears in the batch and keep the order of their elements.
+// The write fails if any partition fails to write or has no writer, in
+// which case its elements are not written, returning all errors joined
+// together. If the RetryPolicy retains the batch, all partitions are written
+// again, including the ones that succeeded.
+func SplitWriter(discriminator func(data interface{}) interface{}, writers map[interface{}]ContextWriter) ContextWriter {
+	return splitWriter{discriminator: discriminator, writers: writers}
+}
+
+type splitWriter struct {
+	discriminator func(data interface{}) interface{}
+	writers       map[interface{}]ContextWriter
+}
+
+func (s splitWriter) Write(ctx context.Context, batch []interface{}) error {
+	var order []interface{}
+	partitions := make(map[interface{}][]interface{})
+	for _, data := range batch {
+		d := s.discriminator(data)
+		if _, ok := partitions[d]; !ok {
+			order = append(order, d)
+		}
+		partitions[d] = append(partitions[d], data)
+	}
+
+	var errs []error
+	for _, d := range order {
+		w, ok := s.writers[d]
+		if !ok {
+			errs = append(errs, fmt.Errorf("batching: no writer for %v", d))
+			continue
+		}
+		if err := w.Write(ctx, partitions[d]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,65 @@
+package batching_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("SplitWriter", func() {
+	var written map[string][][]interface{}
+
+	kind := func(data interface{}) interface{} {
+		k, _, _ := strings.Cut(data.(string), ":")
+		return k
+	}
+	named := func(name string, err error) batching.ContextWriter {
+		return batching.ContextWriterFunc(func(_ context.Context, batch []interface{}) error {
+			written[name] = append(written[name], batch)
+			return err
+		})
+	}
+
+	BeforeEach(func() {
+		written = make(map[string][][]interface{})
+	})
+
+	It("writes each partition to its writer", func() {
+		b := batching.NewContextBatcher(4, time.Minute, batching.SplitWriter(kind, map[interface{}]batching.ContextWriter{
+			"log":    named("log", nil),
+			"metric": named("metric", nil),
+		}))
+		b.WriteAll("log:a", "metric:b", "log:c", "metric:d")
+
+		Expect(written).To(Equal(map[string][][]interface{}{
+			"log":    {{"log:a", "log:c"}},
+			"metric": {{"metric:b", "metric:d"}},
+		}))
+	})
+
+	It("fails if a partition fails to write", func() {
+		writeErr := errors.New("failed")
+		w := batching.SplitWriter(kind, map[interface{}]batching.ContextWriter{
+			"log":    named("log", writeErr),
+			"metric": named("metric", nil),
+		})
+
+		Expect(w.Write(context.Background(), []interface{}{"log:a", "metric:b"})).To(MatchError(writeErr))
+		Expect(written["metric"]).To(HaveLen(1))
+	})
+
+	It("fails if a partition has no writer", func() {
+		w := batching.SplitWriter(kind, map[interface{}]batching.ContextWriter{
+			"log": named("log", nil),
+		})
+
+		Expect(w.Write(context.Background(), []interface{}{"log:a", "trace:b"})).To(MatchError(ContainSubstring("no writer for trace")))
+		Expect(written["log"]).To(HaveLen(1))
+	})
+})
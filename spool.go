@@ -0,0 +1,228 @@
+package batching
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrSpoolFull is returned when a batch cannot be spooled because the spool
+// has reached its limits.
+var ErrSpoolFull = errors.New("batching: spool is full")
+
+// Spool persists batches the writer failed to write so that they can be
+// written once the writer recovers.
+type Spool interface {
+	// Push persists the batch.
+	Push(batch []interface{}) error
+
+	// Peek returns the oldest persisted batch. ok is false if the spool is
+	// empty.
+	Peek() (batch []interface{}, ok bool, err error)
+
+	// Pop removes the oldest persisted batch.
+	Pop() error
+}
+
+// Codec encodes batches to and from bytes.
+type Codec interface {
+	// Marshal encodes the batch.
+	Marshal(batch []interface{}) ([]byte, error)
+
+	// Unmarshal decodes a batch encoded with Marshal.
+	Unmarshal(data []byte) ([]interface{}, error)
+}
+
+// WithSpool pushes every batch the writer failed to write and that is not
+// retained by the RetryPolicy to s instead of discarding it. Once a write
+// succeeds again the spooled batches are written, oldest first, until one of
+// them fails. Batches that cannot be spooled are handed to the dead letter
+// writer, if any. A Batcher created WithWriterConcurrency counts a spooled
+// batch as written once it is handed off, and spools it again if the pool
+// fails to write it.
+func WithSpool(s Spool) Option {
+	return func(b *Batcher) {
+		b.spool = s
+	}
+}
+
+// replaySpool writes the spooled batches until one fails to write. It
+// returns the number of elements written.
+func (b *Batcher) replaySpool(ctx context.Context, reason FlushReason) int {
+	if b.spool == nil {
+		return 0
+	}
+
+	items := 0
+	for ctx.Err() == nil {
+		batch, ok, err := b.spool.Peek()
+		if err != nil || !ok {
+			return items
+		}
+
+		n := len(batch)
+		err = b.submit(ctx, reason, batch)
+		b.stats.flushed(0, reason, err, b.lastSent)
+		if err != nil {
+			return items
+		}
+		b.stats.ItemsReplayed += uint64(n)
+		items += n
+
+		if b.spool.Pop() != nil {
+			return items
+		}
+	}
+	return items
+}
+
+// FileSpool is a Spool that persists every batch to a file of its own in a
+// directory. Batches already in the directory, for instance from before a
+// restart, are picked up. The methods of a FileSpool are safe to call from
+// multiple goroutines. FileSpool should be created with NewFileSpool().
+type FileSpool struct {
+	dir      string
+	maxBytes int64
+	maxFiles int
+	codec    Codec
+
+	mu    sync.Mutex
+	files []spoolFile
+	next  uint64
+	bytes int64
+}
+
+type spoolFile struct {
+	seq  uint64
+	size int64
+}
+
+const spoolExt = ".batch"
+
+// NewFileSpool creates a new FileSpool that stores batches encoded with codec
+// in dir, which is created if it does not exist. The spool holds at most
+// maxFiles batches taking up at most maxBytes. A limit of zero or less means
+// no limit.
+func NewFileSpool(dir string, maxBytes int64, maxFiles int, codec Codec) (*FileSpool, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FileSpool{dir: dir, maxBytes: maxBytes, maxFiles: maxFiles, codec: codec}
+	for _, e := range entries {
+		seq, err := strconv.ParseUint(strings.TrimSuffix(e.Name(), spoolExt), 10, 64)
+		if err != nil || !strings.HasSuffix(e.Name(), spoolExt) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		s.files = append(s.files, spoolFile{seq: seq, size: info.Size()})
+		s.bytes += info.Size()
+		s.next = max(s.next, seq+1)
+	}
+	sort.Slice(s.files, func(i, j int) bool {
+		return s.files[i].seq < s.files[j].seq
+	})
+
+	return s, nil
+}
+
+// Push implements Spool. It returns ErrSpoolFull if storing the batch would
+// exceed the limits of the spool.
+func (s *FileSpool) Push(batch []interface{}) error {
+	data, err := s.codec.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.maxFiles > 0 && len(s.files) >= s.maxFiles {
+		return ErrSpoolFull
+	}
+	if s.maxBytes > 0 && s.bytes+int64(len(data)) > s.maxBytes {
+		return ErrSpoolFull
+	}
+
+	// Write to a temporary file first so that a crash never leaves a
+	// partial batch behind.
+	seq := s.next
+	tmp := filepath.Join(s.dir, s.name(seq)+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, s.name(seq))); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	s.next++
+	s.files = append(s.files, spoolFile{seq: seq, size: int64(len(data))})
+	s.bytes += int64(len(data))
+	return nil
+}
+
+// Peek implements Spool.
+func (s *FileSpool) Peek() ([]interface{}, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.files) == 0 {
+		return nil, false, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.dir, s.name(s.files[0].seq)))
+	if err != nil {
+		return nil, false, err
+	}
+	batch, err := s.codec.Unmarshal(data)
+	if err != nil {
+		return nil, false, err
+	}
+	return batch, true, nil
+}
+
+// Pop implements Spool.
+func (s *FileSpool) Pop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.files) == 0 {
+		return nil
+	}
+
+	f := s.files[0]
+	if err := os.Remove(filepath.Join(s.dir, s.name(f.seq))); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	s.files = s.files[1:]
+	s.bytes -= f.size
+	return nil
+}
+
+// Len returns the number of spooled batches.
+func (s *FileSpool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.files)
+}
+
+func (s *FileSpool) name(seq uint64) string {
+	return fmt.Sprintf("%020d%s", seq, spoolExt)
+}
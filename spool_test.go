@@ -0,0 +1,124 @@
+package batching_test
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Spool", func() {
+	var (
+		dir      string
+		writeErr error
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		writeErr = errors.New("failed")
+	})
+
+	It("spools batches that failed to write and replays them once the writer recovers", func() {
+		spool, err := batching.NewFileSpool(dir, 0, 0, stringCodec{})
+		Expect(err).NotTo(HaveOccurred())
+		writer := &recordingFallibleWriter{errs: []error{writeErr, writeErr}}
+		b := batching.NewFallibleBatcher(2, time.Minute, writer, batching.WithSpool(spool))
+
+		b.WriteAll("a", "b")
+		b.WriteAll("c", "d")
+		Expect(spool.Len()).To(Equal(2))
+		writer.batches = nil
+
+		res := b.ForcedFlush()
+		Expect(res.Items).To(Equal(0))
+
+		b.WriteAll("e", "f")
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"e", "f"}, {"a", "b"}, {"c", "d"}}))
+		Expect(spool.Len()).To(Equal(0))
+		Expect(b.Stats()).To(And(
+			HaveField("ItemsFlushed", uint64(2)),
+			HaveField("ItemsReplayed", uint64(4)),
+		))
+	})
+
+	It("keeps spooled batches until they are written", func() {
+		spool, err := batching.NewFileSpool(dir, 0, 0, stringCodec{})
+		Expect(err).NotTo(HaveOccurred())
+		writer := &recordingFallibleWriter{errs: []error{writeErr, nil, writeErr}}
+		b := batching.NewFallibleBatcher(1, time.Minute, writer, batching.WithSpool(spool))
+
+		b.Write("a")
+		b.Write("b")
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"a"}, {"b"}, {"a"}}))
+		Expect(spool.Len()).To(Equal(1))
+	})
+
+	It("picks up batches spooled before a restart", func() {
+		spool, err := batching.NewFileSpool(dir, 0, 0, stringCodec{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spool.Push([]interface{}{"a"})).To(Succeed())
+		Expect(spool.Push([]interface{}{"b"})).To(Succeed())
+
+		spool, err = batching.NewFileSpool(dir, 0, 0, stringCodec{})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(spool.Len()).To(Equal(2))
+		batch, ok, err := spool.Peek()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(batch).To(Equal([]interface{}{"a"}))
+	})
+
+	It("bounds the number of spooled batches and bytes", func() {
+		spool, err := batching.NewFileSpool(dir, 0, 1, stringCodec{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spool.Push([]interface{}{"a"})).To(Succeed())
+		Expect(spool.Push([]interface{}{"b"})).To(MatchError(batching.ErrSpoolFull))
+
+		spool, err = batching.NewFileSpool(GinkgoT().TempDir(), 3, 0, stringCodec{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spool.Push([]interface{}{"a", "b"})).To(Succeed())
+		Expect(spool.Push([]interface{}{"c"})).To(MatchError(batching.ErrSpoolFull))
+	})
+
+	It("hands batches that cannot be spooled to the dead letter writer", func() {
+		spool, err := batching.NewFileSpool(dir, 0, 1, stringCodec{})
+		Expect(err).NotTo(HaveOccurred())
+		deadLetter := &recordingWriter{}
+		writer := &recordingFallibleWriter{errs: []error{writeErr, writeErr}}
+		b := batching.NewFallibleBatcher(1, time.Minute, writer,
+			batching.WithSpool(spool),
+			batching.WithDeadLetterWriter(deadLetter),
+		)
+
+		b.Write("a")
+		b.Write("b")
+
+		Expect(deadLetter.batches).To(Equal([][]interface{}{{"b"}}))
+	})
+})
+
+// stringCodec encodes batches of strings as lines.
+type stringCodec struct{}
+
+func (stringCodec) Marshal(batch []interface{}) ([]byte, error) {
+	lines := make([]string, len(batch))
+	for i, data := range batch {
+		lines[i] = data.(string)
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+func (stringCodec) Unmarshal(data []byte) ([]interface{}, error) {
+	var batch []interface{}
+	for _, line := range strings.Split(string(data), "\n") {
+		batch = append(batch, line)
+	}
+	return batch, nil
+}
@@ -0,0 +1,164 @@
+package batching
+
+import (
+	"fmt"
+	"time"
+)
+
+// FlushReason describes why a batch was written.
+type FlushReason int
+
+const (
+	// FlushSize means the batch reached the batch size or byte limit.
+	FlushSize FlushReason = iota
+
+	// FlushInterval means the interval lapsed.
+	FlushInterval
+
+	// FlushAge means the oldest pending element was too old.
+	FlushAge
+
+	// FlushForced means the batch was written by ForcedFlush or Close.
+	FlushForced
+
+	// FlushHeartbeat means an empty batch was written because the interval
+	// lapsed without any data. See WithHeartbeat.
+	FlushHeartbeat
+
+	// FlushUrgent means the batch was written by WriteUrgent.
+	FlushUrgent
+
+	// FlushMemory means the batch was written to free memory because the
+	// MemoryLimiter was over its budget.
+	FlushMemory
+
+	// FlushScheduled means the batch was written because of the schedule.
+	// See WithSchedule.
+	FlushScheduled
+
+	// FlushQuiet means no element was added for the quiet period. See
+	// WithDebounce.
+	FlushQuiet
+)
+
+// String implements fmt.Stringer.
+func (r FlushReason) String() string {
+	switch r {
+	case FlushSize:
+		return "size"
+	case FlushInterval:
+		return "interval"
+	case FlushAge:
+		return "age"
+	case FlushForced:
+		return "forced"
+	case FlushHeartbeat:
+		return "heartbeat"
+	case FlushUrgent:
+		return "urgent"
+	case FlushMemory:
+		return "memory"
+	case FlushScheduled:
+		return "scheduled"
+	case FlushQuiet:
+		return "quiet"
+	default:
+		return fmt.Sprintf("FlushReason(%d)", int(r))
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler, so that Stats.Flushes is
+// serialized with the names of the reasons.
+func (r FlushReason) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// Stats holds cumulative counters describing the activity of a Batcher.
+type Stats struct {
+	// ItemsWritten is the number of elements stored to the batch.
+	ItemsWritten uint64
+
+	// ItemsFiltered is the number of elements that were not stored to the
+	// batch because of the filter. See WithFilter.
+	ItemsFiltered uint64
+
+	// ItemsFlushed is the number of elements successfully submitted to
+	// the writer.
+	ItemsFlushed uint64
+
+	// ItemsReplayed is the number of spooled elements successfully
+	// submitted to the writer, which are not included in ItemsFlushed.
+	ItemsReplayed uint64
+
+	// ItemsDeduplicated is the number of elements that were collapsed
+	// into a later element with the same key. See WithDedupKey.
+	ItemsDeduplicated uint64
+
+	// Batches is the number of batches successfully submitted to the
+	// writer.
+	Batches uint64
+
+	// WriteErrors is the number of batches the writer failed to write.
+	WriteErrors uint64
+
+	// Flushes is the number of times the writer was invoked, by reason.
+	Flushes map[FlushReason]uint64
+
+	// Pending is the number of elements waiting to be written.
+	Pending int
+
+	// LastFlush is when the writer was last invoked. It is the zero time
+	// if the writer was never invoked.
+	LastFlush time.Time
+
+	// LastWriteDuration is how long the last invocation of the writer
+	// took.
+	LastWriteDuration time.Duration
+
+	// MaxWriteDuration is how long the slowest invocation of the writer
+	// took.
+	MaxWriteDuration time.Duration
+
+	// TotalWriteDuration is how long all invocations of the writer took
+	// together. Divided by the sum of Batches and WriteErrors it gives the
+	// average duration of a write.
+	TotalWriteDuration time.Duration
+
+	// SlowWrites is the number of invocations of the writer that took
+	// longer than the slow write threshold. See WithSlowWriteThreshold.
+	SlowWrites uint64
+}
+
+// Stats returns a snapshot of the counters of the Batcher.
+func (b *Batcher) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.snapshotStats()
+}
+
+func (b *Batcher) snapshotStats() Stats {
+	s := b.stats
+	s.Flushes = make(map[FlushReason]uint64, len(b.stats.Flushes))
+	for r, n := range b.stats.Flushes {
+		s.Flushes[r] = n
+	}
+	s.Pending = len(b.batch)
+
+	return s
+}
+
+func (s *Stats) flushed(items int, reason FlushReason, err error, at time.Time) {
+	if s.Flushes == nil {
+		s.Flushes = make(map[FlushReason]uint64)
+	}
+	s.Flushes[reason]++
+	s.LastFlush = at
+
+	if err != nil {
+		s.WriteErrors++
+		return
+	}
+	s.Batches++
+	s.ItemsFlushed += uint64(items)
+}
@@ -0,0 +1,65 @@
+package batching_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Stats", func() {
+	It("counts the activity of the batcher", func() {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		writer := &spyFallibleWriter{}
+		b := batching.NewFallibleBatcher(2, time.Minute, writer, batching.WithClock(clock))
+
+		Expect(b.Stats()).To(Equal(batching.Stats{Flushes: map[batching.FlushReason]uint64{}}))
+
+		b.WriteAll("a", "b", "c")
+		clock.Advance(time.Minute)
+		b.Flush()
+
+		b.Write("d")
+		writer.err = errors.New("failed")
+		b.ForcedFlush()
+
+		b.Write("e")
+
+		Expect(b.Stats()).To(Equal(batching.Stats{
+			ItemsWritten: 5,
+			ItemsFlushed: 3,
+			Batches:      2,
+			WriteErrors:  1,
+			Flushes: map[batching.FlushReason]uint64{
+				batching.FlushSize:     1,
+				batching.FlushInterval: 1,
+				batching.FlushForced:   1,
+			},
+			Pending:   1,
+			LastFlush: time.Unix(60, 0),
+		}))
+	})
+
+	It("returns a snapshot", func() {
+		b := batching.NewBatcher(1, time.Minute, &spyWriter{})
+		b.Write("a")
+
+		stats := b.Stats()
+		stats.Flushes[batching.FlushSize] = 10
+
+		Expect(b.Stats().Flushes).To(Equal(map[batching.FlushReason]uint64{batching.FlushSize: 1}))
+	})
+
+	It("describes flush reasons", func() {
+		Expect(batching.FlushSize.String()).To(Equal("size"))
+		Expect(batching.FlushInterval.String()).To(Equal("interval"))
+		Expect(batching.FlushAge.String()).To(Equal("age"))
+		Expect(batching.FlushForced.String()).To(Equal("forced"))
+		Expect(batching.FlushHeartbeat.String()).To(Equal("heartbeat"))
+		Expect(batching.FlushUrgent.String()).To(Equal("urgent"))
+		Expect(batching.FlushReason(-1).String()).To(Equal("FlushReason(-1)"))
+	})
+})
@@ -0,0 +1,77 @@
+package batching
+
+import "time"
+
+// StringBatcher batches strings.
+type StringBatcher struct {
+	*Batcher
+}
+
+// StringWriter is used to submit the completed batch of strings. The batch
+// may be partial if the interval lapsed instead of filling the batch.
+type StringWriter interface {
+	// Write submits the batch.
+	Write(batch []string)
+}
+
+// StringWriterFunc is an adapter to allow ordinary functions to be a
+// StringWriter.
+type StringWriterFunc func(batch []string)
+
+// Write implements StringWriter.
+func (f StringWriterFunc) Write(batch []string) {
+	f(batch)
+}
+
+// NewStringBatcher creates a new StringBatcher. The size of each string is
+// its length in bytes, so WithMaxBytes can be used to bound the batch by
+// its total length.
+func NewStringBatcher(size int, interval time.Duration, writer StringWriter, opts ...Option) *StringBatcher {
+	genWriter := WriterFunc(func(batch []interface{}) {
+		writer.Write(toStringBatch(batch))
+	})
+	return &StringBatcher{
+		Batcher: NewBatcher(size, interval, genWriter, append([]Option{WithSizeFunc(stringLen)}, opts...)...),
+	}
+}
+
+// Write stores data to the batch. It will not submit the batch to the writer
+// until either the batch has been filled, or the interval has lapsed. NOTE:
+// Write is *not* thread safe unless the StringBatcher was created
+// WithLocking and should otherwise be called by the same goroutine that
+// calls Flush.
+func (b *StringBatcher) Write(data string) {
+	b.Batcher.Write(data)
+}
+
+// WriteAll stores many strings to the batch at once. See Batcher.WriteAll.
+func (b *StringBatcher) WriteAll(data ...string) {
+	batch := make([]interface{}, 0, len(data))
+	for _, d := range data {
+		batch = append(batch, d)
+	}
+	b.Batcher.WriteAll(batch...)
+}
+
+// Peek returns a copy of the strings waiting to be written.
+func (b *StringBatcher) Peek() []string {
+	return toStringBatch(b.Batcher.Peek())
+}
+
+// Discard drops the strings waiting to be written without writing them and
+// returns them.
+func (b *StringBatcher) Discard() []string {
+	return toStringBatch(b.Batcher.Discard())
+}
+
+func toStringBatch(batch []interface{}) []string {
+	stringBatch := make([]string, 0, len(batch))
+	for _, element := range batch {
+		stringBatch = append(stringBatch, element.(string))
+	}
+	return stringBatch
+}
+
+func stringLen(data interface{}) int {
+	return len(data.(string))
+}
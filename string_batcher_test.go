@@ -0,0 +1,54 @@
+package batching_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("StringBatcher", func() {
+	It("works", func() {
+		var batch []string
+		writer := batching.StringWriterFunc(func(b []string) {
+			batch = b
+		})
+		b := batching.NewStringBatcher(2, time.Minute, writer)
+
+		b.Write("item")
+		b.Write("other-item")
+
+		Expect(batch).To(Equal([]string{"item", "other-item"}))
+	})
+
+	It("writes many strings at once", func() {
+		var batches [][]string
+		writer := batching.StringWriterFunc(func(b []string) {
+			batches = append(batches, b)
+		})
+		b := batching.NewStringBatcher(2, time.Minute, writer)
+
+		b.WriteAll("a", "b", "c")
+
+		Expect(batches).To(Equal([][]string{{"a", "b"}}))
+		Expect(b.Peek()).To(Equal([]string{"c"}))
+		Expect(b.Discard()).To(Equal([]string{"c"}))
+		Expect(b.Len()).To(BeZero())
+	})
+
+	It("measures strings by their length", func() {
+		var batches [][]string
+		writer := batching.StringWriterFunc(func(b []string) {
+			batches = append(batches, b)
+		})
+		b := batching.NewStringBatcher(100, time.Minute, writer, batching.WithMaxBytes(8))
+
+		b.Write("item")
+		b.Write("item")
+		b.Write("item")
+
+		Expect(batches).To(Equal([][]string{{"item", "item"}}))
+	})
+})
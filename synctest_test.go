@@ -0,0 +1,83 @@
+//go:build go1.25
+
+package batching_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"code.cloudfoundry.org/go-batching"
+	"code.cloudfoundry.org/go-batching/batchingtest"
+)
+
+// These are plain tests rather than specs, since synctest.Test needs the
+// *testing.T of the test it runs in.
+
+func TestSynctestAutoFlush(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		spy := batchingtest.NewSpyWriter()
+		b := batching.NewContextBatcher(10, time.Minute, spy)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		b.StartAutoFlush(ctx)
+
+		b.Write("a")
+		time.Sleep(time.Minute - time.Nanosecond)
+		synctest.Wait()
+		if n := spy.Calls(); n != 0 {
+			t.Fatalf("expected no write before the interval, got %d", n)
+		}
+
+		time.Sleep(time.Nanosecond)
+		synctest.Wait()
+		if items := spy.Items(); len(items) != 1 {
+			t.Fatalf("expected the batch to be written at the interval, got %v", items)
+		}
+
+		if err := b.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestSynctestMaxItemAge(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		spy := batchingtest.NewSpyWriter()
+		b := batching.NewContextBatcher(10, time.Hour, spy,
+			batching.WithMaxItemAge(time.Second),
+		)
+
+		b.Write("a")
+		time.Sleep(time.Second)
+		synctest.Wait()
+
+		if items := spy.Items(); len(items) != 1 {
+			t.Fatalf("expected the batch to be written at the maximum age, got %v", items)
+		}
+	})
+}
+
+func TestSynctestBackoff(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		spy := batchingtest.NewSpyWriter()
+		spy.FailNext(errors.New("unavailable"), errors.New("unavailable"))
+		b := batching.NewContextBatcher(1, time.Hour, spy,
+			batching.WithBackoff(batching.Backoff{InitialInterval: time.Minute}),
+		)
+
+		start := time.Now()
+		if err := b.WriteContext(context.Background(), "a"); err != nil {
+			t.Fatal(err)
+		}
+
+		if elapsed := time.Since(start); elapsed != 3*time.Minute {
+			t.Fatalf("expected the retries to take 3m, took %s", elapsed)
+		}
+		if n := spy.Calls(); n != 3 {
+			t.Fatalf("expected 3 writes, got %d", n)
+		}
+	})
+}
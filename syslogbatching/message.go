@@ -0,0 +1,83 @@
+package syslogbatching
+
+import (
+	"strconv"
+	"time"
+)
+
+// Severity is the severity of a syslog message.
+type Severity int
+
+// The severities defined by RFC 5424.
+const (
+	Emergency Severity = iota
+	Alert
+	Critical
+	Error
+	Warning
+	Notice
+	Info
+	Debug
+)
+
+// Facility is the facility of a syslog message.
+type Facility int
+
+// The facilities most commonly used for application logs.
+const (
+	User   Facility = 1
+	Local0 Facility = 16
+)
+
+// Message is a syslog message. Empty header fields are sent as the NILVALUE
+// "-".
+type Message struct {
+	Facility  Facility
+	Severity  Severity
+	Timestamp time.Time
+	Hostname  string
+	AppName   string
+	ProcID    string
+	MsgID     string
+
+	// StructuredData is the formatted structured data of the message, for
+	// instance `[tags@47450 source_type="APP"]`.
+	StructuredData string
+
+	Message []byte
+}
+
+const timestampFormat = "2006-01-02T15:04:05.999999Z07:00"
+
+// appendFramed appends the message in the RFC 5424 format to buf, framed
+// with octet counting as described by RFC 6587.
+func (m Message) appendFramed(buf []byte) []byte {
+	msg := make([]byte, 0, 128+len(m.Message))
+	msg = append(msg, '<')
+	msg = strconv.AppendInt(msg, int64(m.Facility)*8+int64(m.Severity), 10)
+	msg = append(msg, ">1 "...)
+	if m.Timestamp.IsZero() {
+		msg = append(msg, '-')
+	} else {
+		msg = m.Timestamp.AppendFormat(msg, timestampFormat)
+	}
+	for _, field := range []string{m.Hostname, m.AppName, m.ProcID, m.MsgID, m.StructuredData} {
+		msg = append(msg, ' ')
+		msg = appendField(msg, field)
+	}
+	if len(m.Message) > 0 {
+		msg = append(msg, ' ')
+		msg = append(msg, m.Message...)
+	}
+
+	buf = strconv.AppendInt(buf, int64(len(msg)), 10)
+	buf = append(buf, ' ')
+	return append(buf, msg...)
+}
+
+func appendField(buf []byte, field string) []byte {
+	if field == "" {
+		return append(buf, '-')
+	}
+	return append(buf, field...)
+}
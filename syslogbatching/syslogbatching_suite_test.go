@@ -0,0 +1,13 @@
+package syslogbatching_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestSyslogbatching(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Syslogbatching Suite")
+}
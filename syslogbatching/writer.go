@@ -0,0 +1,170 @@
+// Package syslogbatching writes batches to syslog drains as RFC 5424
+// messages over TCP.
+package syslogbatching
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// Option configures a Writer.
+type Option func(w *Writer)
+
+// WithTLSConfig connects to the drain with TLS.
+func WithTLSConfig(c *tls.Config) Option {
+	return func(w *Writer) {
+		w.tlsConfig = c
+	}
+}
+
+// WithTimeout bounds how long connecting to the drain and writing each
+// batch may take. By default only the context passed to Write bounds them.
+func WithTimeout(d time.Duration) Option {
+	return func(w *Writer) {
+		w.timeout = d
+	}
+}
+
+// WithDefaults sets the message that []byte and string elements are sent
+// with, the element being the message text. It defaults to a message with
+// the User facility and the Info severity, timestamped when it is written.
+func WithDefaults(m Message) Option {
+	return func(w *Writer) {
+		w.defaults = m
+	}
+}
+
+// Writer is a batching.ContextWriter that sends every batch to a syslog
+// drain over a single TCP connection, each element as an RFC 5424 message
+// framed with octet counting. Elements must be a Message, a *Message, a
+// []byte or a string. The connection is established on the first write and
+// re-established on the write that follows a failure. Writer should be
+// created with NewWriter().
+type Writer struct {
+	addr      string
+	tlsConfig *tls.Config
+	timeout   time.Duration
+	defaults  Message
+
+	mu   sync.Mutex
+	conn net.Conn
+	buf  []byte
+	now  func() time.Time
+}
+
+// NewWriter creates a new Writer for the drain at addr, given as host:port.
+func NewWriter(addr string, opts ...Option) *Writer {
+	w := &Writer{
+		addr:     addr,
+		defaults: Message{Facility: User, Severity: Info},
+		now:      time.Now,
+	}
+	for _, o := range opts {
+		o(w)
+	}
+
+	return w
+}
+
+// Write implements batching.ContextWriter.
+func (w *Writer) Write(ctx context.Context, batch []interface{}) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf := w.buf[:0]
+	for _, data := range batch {
+		m, err := w.message(data)
+		if err != nil {
+			return err
+		}
+		buf = m.appendFramed(buf)
+	}
+	w.buf = buf
+
+	if w.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.timeout)
+		defer cancel()
+	}
+	if err := w.connect(ctx); err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := w.conn.SetWriteDeadline(deadline); err != nil {
+		w.disconnect()
+		return err
+	}
+	if _, err := w.conn.Write(buf); err != nil {
+		w.disconnect()
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection to the drain, if any. The next write
+// connects again.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func (w *Writer) message(data interface{}) (Message, error) {
+	var m Message
+	switch d := data.(type) {
+	case Message:
+		return d, nil
+	case *Message:
+		return *d, nil
+	case []byte:
+		m = w.defaults
+		m.Message = d
+	case string:
+		m = w.defaults
+		m.Message = []byte(d)
+	default:
+		return Message{}, fmt.Errorf("syslogbatching: cannot send %T", data)
+	}
+	if m.Timestamp.IsZero() {
+		m.Timestamp = w.now()
+	}
+	return m, nil
+}
+
+func (w *Writer) connect(ctx context.Context) error {
+	if w.conn != nil {
+		return nil
+	}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if w.tlsConfig != nil {
+		d := &tls.Dialer{Config: w.tlsConfig}
+		conn, err = d.DialContext(ctx, "tcp", w.addr)
+	} else {
+		var d net.Dialer
+		conn, err = d.DialContext(ctx, "tcp", w.addr)
+	}
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+func (w *Writer) disconnect() {
+	_ = w.conn.Close()
+	w.conn = nil
+}
@@ -0,0 +1,144 @@
+package syslogbatching_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+	"code.cloudfoundry.org/go-batching/syslogbatching"
+)
+
+var _ = Describe("Writer", func() {
+	var (
+		listener net.Listener
+		messages chan string
+		conns    chan net.Conn
+	)
+
+	BeforeEach(func() {
+		var err error
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(func() {
+			_ = listener.Close()
+		})
+
+		messages = make(chan string, 100)
+		conns = make(chan net.Conn, 10)
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				conns <- conn
+				go readFrames(conn, messages)
+			}
+		}()
+	})
+
+	It("sends every element as an octet-counted RFC 5424 message", func() {
+		w := syslogbatching.NewWriter(listener.Addr().String())
+		DeferCleanup(w.Close)
+		b := batching.NewContextBatcher(2, time.Minute, w)
+		ts := time.Date(2026, 1, 2, 3, 4, 5, 123456789, time.UTC)
+
+		b.Write(syslogbatching.Message{
+			Facility:       syslogbatching.User,
+			Severity:       syslogbatching.Error,
+			Timestamp:      ts,
+			Hostname:       "org.space.app",
+			AppName:        "app-guid",
+			ProcID:         "[APP/PROC/WEB/0]",
+			StructuredData: `[tags@47450 source_type="APP"]`,
+			Message:        []byte("hello"),
+		})
+		b.Write(&syslogbatching.Message{Severity: syslogbatching.Debug})
+
+		Eventually(messages).Should(Receive(Equal(
+			`<11>1 2026-01-02T03:04:05.123456Z org.space.app app-guid [APP/PROC/WEB/0] - [tags@47450 source_type="APP"] hello`,
+		)))
+		Eventually(messages).Should(Receive(Equal("<7>1 - - - - - -")))
+	})
+
+	It("sends strings and slices of bytes with the defaults", func() {
+		w := syslogbatching.NewWriter(listener.Addr().String(), syslogbatching.WithDefaults(syslogbatching.Message{
+			Facility:  syslogbatching.Local0,
+			Severity:  syslogbatching.Warning,
+			Timestamp: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			AppName:   "app",
+		}))
+		DeferCleanup(w.Close)
+
+		Expect(w.Write(context.Background(), []interface{}{"line", []byte("other line")})).To(Succeed())
+
+		Eventually(messages).Should(Receive(Equal("<132>1 2026-01-02T03:04:05Z - app - - - line")))
+		Eventually(messages).Should(Receive(Equal("<132>1 2026-01-02T03:04:05Z - app - - - other line")))
+	})
+
+	It("reuses the connection", func() {
+		w := syslogbatching.NewWriter(listener.Addr().String())
+		DeferCleanup(w.Close)
+
+		Expect(w.Write(context.Background(), []interface{}{"a"})).To(Succeed())
+		Expect(w.Write(context.Background(), []interface{}{"b"})).To(Succeed())
+
+		Eventually(messages).Should(HaveLen(2))
+		Expect(conns).To(HaveLen(1))
+	})
+
+	It("reconnects after the connection is closed", func() {
+		w := syslogbatching.NewWriter(listener.Addr().String())
+		DeferCleanup(w.Close)
+
+		Expect(w.Write(context.Background(), []interface{}{"a"})).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+		Expect(w.Write(context.Background(), []interface{}{"b"})).To(Succeed())
+
+		Eventually(messages).Should(HaveLen(2))
+		Expect(conns).To(HaveLen(2))
+	})
+
+	It("fails elements of other types", func() {
+		w := syslogbatching.NewWriter(listener.Addr().String())
+
+		err := w.Write(context.Background(), []interface{}{42})
+
+		Expect(err).To(MatchError(ContainSubstring("cannot send int")))
+	})
+
+	It("fails if the drain cannot be reached", func() {
+		addr := listener.Addr().String()
+		Expect(listener.Close()).To(Succeed())
+		w := syslogbatching.NewWriter(addr, syslogbatching.WithTimeout(time.Second))
+
+		Expect(w.Write(context.Background(), []interface{}{"a"})).To(HaveOccurred())
+	})
+})
+
+func readFrames(conn net.Conn, messages chan<- string) {
+	r := bufio.NewReader(conn)
+	for {
+		length, err := r.ReadString(' ')
+		if err != nil {
+			return
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+		if err != nil {
+			return
+		}
+		msg := make([]byte, n)
+		if _, err := io.ReadFull(r, msg); err != nil {
+			return
+		}
+		messages <- string(msg)
+	}
+}
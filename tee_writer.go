@@ -0,0 +1,81 @@
+package batching
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// TeeWriter is a ContextWriter that writes every batch to a primary writer
+// and mirrors a copy of it to a second writer in the background, for
+// example to ship a copy of production traffic to a test sink. Mirrored
+// batches are queued in a bounded queue and dropped once it is full, so the
+// mirror never blocks or fails the primary write. TeeWriter should be
+// created with NewTeeWriter().
+type TeeWriter struct {
+	primary ContextWriter
+	mirror  ContextWriter
+	queue   chan []interface{}
+	done    chan struct{}
+	once    sync.Once
+
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+}
+
+// NewTeeWriter creates a new TeeWriter that queues up to queueSize batches
+// for the mirror. Close must be called to stop mirroring.
+func NewTeeWriter(primary, mirror ContextWriter, queueSize int) *TeeWriter {
+	t := &TeeWriter{
+		primary: primary,
+		mirror:  mirror,
+		queue:   make(chan []interface{}, max(queueSize, 1)),
+		done:    make(chan struct{}),
+	}
+	go t.run()
+
+	return t
+}
+
+// Write writes the batch to the primary writer and returns its error. A copy
+// of the batch is queued for the mirror regardless of the error.
+func (t *TeeWriter) Write(ctx context.Context, batch []interface{}) error {
+	select {
+	case t.queue <- slices.Clone(batch):
+	default:
+		t.dropped.Add(1)
+	}
+
+	return t.primary.Write(ctx, batch)
+}
+
+// Dropped returns the number of batches that were not mirrored because the
+// queue was full.
+func (t *TeeWriter) Dropped() uint64 {
+	return t.dropped.Load()
+}
+
+// Failed returns the number of batches the mirror failed to write.
+func (t *TeeWriter) Failed() uint64 {
+	return t.failed.Load()
+}
+
+// Close waits for the queued batches to be mirrored and stops mirroring. It
+// must not be called while batches are written.
+func (t *TeeWriter) Close() {
+	t.once.Do(func() {
+		close(t.queue)
+	})
+	<-t.done
+}
+
+func (t *TeeWriter) run() {
+	defer close(t.done)
+
+	for batch := range t.queue {
+		if err := t.mirror.Write(context.Background(), batch); err != nil {
+			t.failed.Add(1)
+		}
+	}
+}
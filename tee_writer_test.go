@@ -0,0 +1,95 @@
+package batching_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("TeeWriter", func() {
+	var (
+		mu       sync.Mutex
+		mirrored [][]interface{}
+		mirror   batching.ContextWriter
+	)
+
+	BeforeEach(func() {
+		mirrored = nil
+		mirror = batching.ContextWriterFunc(func(_ context.Context, batch []interface{}) error {
+			mu.Lock()
+			defer mu.Unlock()
+			mirrored = append(mirrored, batch)
+			return nil
+		})
+	})
+
+	It("writes every batch to the primary and the mirror", func() {
+		primary := &recordingFallibleWriter{}
+		t := batching.NewTeeWriter(batching.ContextWriterFunc(func(_ context.Context, batch []interface{}) error {
+			return primary.Write(batch)
+		}), mirror, 10)
+		b := batching.NewContextBatcher(1, time.Minute, t)
+		b.Write("a")
+		b.Write("b")
+
+		t.Close()
+
+		Expect(primary.batches).To(Equal([][]interface{}{{"a"}, {"b"}}))
+		Expect(mirrored).To(Equal([][]interface{}{{"a"}, {"b"}}))
+	})
+
+	It("returns the error of the primary", func() {
+		primaryErr := errors.New("failed")
+		t := batching.NewTeeWriter(batching.ContextWriterFunc(func(context.Context, []interface{}) error {
+			return primaryErr
+		}), mirror, 10)
+		defer t.Close()
+
+		Expect(t.Write(context.Background(), []interface{}{"a"})).To(MatchError(primaryErr))
+	})
+
+	It("does not fail the primary if the mirror fails", func() {
+		t := batching.NewTeeWriter(
+			batching.ContextWriterFunc(func(context.Context, []interface{}) error { return nil }),
+			batching.ContextWriterFunc(func(context.Context, []interface{}) error { return errors.New("failed") }),
+			10,
+		)
+
+		Expect(t.Write(context.Background(), []interface{}{"a"})).To(Succeed())
+		t.Close()
+		Expect(t.Failed()).To(Equal(uint64(1)))
+	})
+
+	It("drops mirrored batches once the queue is full", func() {
+		release := make(chan struct{})
+		started := make(chan struct{}, 1)
+		blocked := batching.ContextWriterFunc(func(context.Context, []interface{}) error {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-release
+			return nil
+		})
+		t := batching.NewTeeWriter(
+			batching.ContextWriterFunc(func(context.Context, []interface{}) error { return nil }),
+			blocked,
+			1,
+		)
+
+		Expect(t.Write(context.Background(), []interface{}{"a"})).To(Succeed())
+		Eventually(started).Should(Receive())
+		Expect(t.Write(context.Background(), []interface{}{"b"})).To(Succeed())
+		Expect(t.Write(context.Background(), []interface{}{"c"})).To(Succeed())
+
+		Expect(t.Dropped()).To(Equal(uint64(1)))
+		close(release)
+		t.Close()
+	})
+})
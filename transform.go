@@ -0,0 +1,11 @@
+package batching
+
+// WithTransform replaces every element written with the element returned by
+// transform before storing it to the batch, for example to redact, tag or
+// truncate data. It is applied after the filter, see WithFilter, and before
+// the element is measured by the size and weight functions.
+func WithTransform(transform func(data interface{}) interface{}) Option {
+	return func(b *Batcher) {
+		b.transform = transform
+	}
+}
@@ -0,0 +1,65 @@
+package batching_test
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Transform", func() {
+	redact := func(data interface{}) interface{} {
+		return strings.ReplaceAll(data.(string), "secret", "***")
+	}
+
+	It("stores the transformed elements", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(2, time.Minute, writer, batching.WithTransform(redact))
+		b.Write("password=secret")
+		b.Write("user=admin")
+
+		Expect(writer.batch).To(Equal([]interface{}{"password=***", "user=admin"}))
+	})
+
+	It("transforms the data of WriteAll without modifying it", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(2, time.Minute, writer, batching.WithTransform(redact))
+		data := []interface{}{"a=secret", "b=secret"}
+		b.WriteAll(data...)
+
+		Expect(writer.batch).To(Equal([]interface{}{"a=***", "b=***"}))
+		Expect(data).To(Equal([]interface{}{"a=secret", "b=secret"}))
+	})
+
+	It("measures the transformed elements", func() {
+		writer := &recordingWriter{}
+		b := batching.NewBatcher(10, time.Minute, writer,
+			batching.WithTransform(func(data interface{}) interface{} {
+				return data.(string)[:1]
+			}),
+			batching.WithMaxBytes(2),
+			batching.WithSizeFunc(strLen),
+		)
+		b.WriteAll("aaaa", "bbbb")
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"a", "b"}}))
+	})
+
+	It("is applied after the filter", func() {
+		var filtered []interface{}
+		b := batching.NewBatcher(10, time.Minute, &spyWriter{},
+			batching.WithFilter(func(data interface{}) bool {
+				filtered = append(filtered, data)
+				return true
+			}),
+			batching.WithTransform(redact),
+		)
+		b.Write("secret")
+
+		Expect(filtered).To(Equal([]interface{}{"secret"}))
+		Expect(b.Peek()).To(Equal([]interface{}{"***"}))
+	})
+})
@@ -0,0 +1,42 @@
+package batching
+
+import "context"
+
+// WriteUrgent stores data to the batch and immediately writes the batch
+// including data, so that urgent data such as alerts or audit events does
+// not wait for the batch to fill up or the interval to lapse.
+func (b *Batcher) WriteUrgent(data interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	_ = b.writeUrgent(context.Background(), data)
+}
+
+// WriteUrgentContext is like WriteUrgent but passes ctx to the writer. It
+// returns the error from the write, or the error of ctx if it is done before
+// the batch is submitted, in which case the batch is kept.
+func (b *Batcher) WriteUrgentContext(ctx context.Context, data interface{}) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.writeUrgent(ctx, data)
+}
+
+func (b *Batcher) writeUrgent(ctx context.Context, data interface{}) error {
+	if b.closed {
+		return ErrClosed
+	}
+	data, ok := b.accept(data)
+	if !ok {
+		return nil
+	}
+	if err := b.makeRoom(data); err != nil {
+		return err
+	}
+
+	walErr := b.store(data, b.sizeOf(data))
+	if _, err := b.writeBatch(ctx, FlushUrgent); err != nil {
+		return err
+	}
+	return walErr
+}
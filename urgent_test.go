@@ -0,0 +1,43 @@
+package batching_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("WriteUrgent", func() {
+	It("writes the batch including the urgent element", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(10, time.Minute, writer)
+		b.Write("bulk")
+
+		b.WriteUrgent("alert")
+
+		Expect(writer.batch).To(Equal([]interface{}{"bulk", "alert"}))
+		Expect(b.Len()).To(Equal(0))
+		Expect(b.Stats().Flushes[batching.FlushUrgent]).To(Equal(uint64(1)))
+	})
+
+	It("keeps the batch if ctx is done", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(10, time.Minute, writer)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		Expect(b.WriteUrgentContext(ctx, "alert")).To(MatchError(context.Canceled))
+		Expect(writer.called).To(BeZero())
+		Expect(b.Len()).To(Equal(1))
+	})
+
+	It("returns ErrClosed once closed", func() {
+		b := batching.NewBatcher(10, time.Minute, &spyWriter{})
+		Expect(b.Close()).To(Succeed())
+
+		Expect(b.WriteUrgentContext(context.Background(), "alert")).To(MatchError(batching.ErrClosed))
+	})
+})
@@ -0,0 +1,140 @@
+package batching
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+// WAL is a write-ahead log that keeps the pending data of a Batcher in a
+// file, so that data which had not been written when the process crashed is
+// written after a restart. Every element is appended to the file before it is
+// stored to the batch and the file is truncated once the pending batch has
+// been written successfully. Elements of partially written or dropped
+// batches may therefore be written again after a restart. A WAL must only be
+// used by a single Batcher. WAL should be created with OpenWAL().
+type WAL struct {
+	f         *os.File
+	codec     Codec
+	recovered []interface{}
+}
+
+// OpenWAL opens the write-ahead log at path, creating it if it does not
+// exist. Elements are encoded with codec as batches of a single element. The
+// elements left in the log are stored to the batch of the Batcher created
+// WithWAL. A partial record at the end of the log, as left behind by a crash
+// while appending, is discarded.
+func OpenWAL(path string, codec Codec) (*WAL, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &WAL{f: f, codec: codec}
+	offset, err := w.recover()
+	if err == nil {
+		err = f.Truncate(offset)
+	}
+	if err == nil {
+		_, err = f.Seek(offset, io.SeekStart)
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// WithWAL makes the Batcher append every element to w before storing it and
+// stores the elements recovered by w to the batch. Write methods that return
+// an error return the error from appending to the log, in which case the
+// element is still stored.
+func WithWAL(w *WAL) Option {
+	return func(b *Batcher) {
+		b.wal = w
+	}
+}
+
+// Close closes the log file.
+func (w *WAL) Close() error {
+	return w.f.Close()
+}
+
+// recover reads the elements in the log and returns the offset of the end of
+// the last complete record.
+func (w *WAL) recover() (int64, error) {
+	r := bufio.NewReader(w.f)
+	var offset int64
+	for {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return offset, nil
+		}
+		record := make([]byte, n)
+		if _, err := io.ReadFull(r, record); err != nil {
+			return offset, nil
+		}
+
+		batch, err := w.codec.Unmarshal(record)
+		if err != nil {
+			return 0, err
+		}
+		w.recovered = append(w.recovered, batch...)
+		offset += int64(uvarintLen(n)) + int64(n)
+	}
+}
+
+func (w *WAL) append(data interface{}) error {
+	record, err := w.codec.Marshal([]interface{}{data})
+	if err != nil {
+		return err
+	}
+
+	buf := binary.AppendUvarint(make([]byte, 0, binary.MaxVarintLen64+len(record)), uint64(len(record)))
+	_, err = w.f.Write(append(buf, record...))
+	return err
+}
+
+func (w *WAL) truncate() error {
+	if err := w.f.Truncate(0); err != nil {
+		return err
+	}
+	_, err := w.f.Seek(0, io.SeekStart)
+	return err
+}
+
+func uvarintLen(n uint64) int {
+	return len(binary.AppendUvarint(nil, n))
+}
+
+func (b *Batcher) recoverWAL() {
+	if b.wal == nil {
+		return
+	}
+
+	for _, data := range b.wal.recovered {
+		b.add(data, b.sizeOf(data))
+	}
+	b.wal.recovered = nil
+}
+
+// store appends data to the write-ahead log, if any, and adds it to the
+// batch.
+func (b *Batcher) store(data interface{}, size int) error {
+	var err error
+	if b.wal != nil {
+		err = b.wal.append(data)
+	}
+	b.add(data, size)
+	return err
+}
+
+// truncateWAL empties the write-ahead log, if any, once the pending batch is
+// empty.
+func (b *Batcher) truncateWAL() {
+	if b.wal != nil {
+		_ = b.wal.truncate()
+	}
+}
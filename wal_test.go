@@ -0,0 +1,77 @@
+package batching_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("WAL", func() {
+	var path string
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "batcher.wal")
+	})
+
+	open := func() *batching.WAL {
+		wal, err := batching.OpenWAL(path, stringCodec{})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(wal.Close)
+		return wal
+	}
+
+	It("recovers the data that was not written", func() {
+		b := batching.NewBatcher(10, time.Minute, &spyWriter{}, batching.WithWAL(open()))
+		b.Write("a")
+		b.WriteAll("b", "c")
+
+		b = batching.NewBatcher(10, time.Minute, &spyWriter{}, batching.WithWAL(open()))
+
+		Expect(b.Peek()).To(Equal([]interface{}{"a", "b", "c"}))
+	})
+
+	It("truncates the log once the batch is written", func() {
+		writer := &spyWriter{}
+		b := batching.NewBatcher(2, time.Minute, writer, batching.WithWAL(open()))
+		b.WriteAll("a", "b")
+		b.Write("c")
+		Expect(writer.batch).To(Equal([]interface{}{"a", "b"}))
+
+		b = batching.NewBatcher(10, time.Minute, &spyWriter{}, batching.WithWAL(open()))
+
+		Expect(b.Peek()).To(Equal([]interface{}{"c"}))
+	})
+
+	It("keeps the data that failed to write on close", func() {
+		writer := &spyFallibleWriter{err: errors.New("failed")}
+		b := batching.NewFallibleBatcher(10, time.Minute, writer, batching.WithWAL(open()))
+		b.Write("a")
+		Expect(b.Close()).NotTo(Succeed())
+
+		b = batching.NewBatcher(10, time.Minute, &spyWriter{}, batching.WithWAL(open()))
+
+		Expect(b.Peek()).To(Equal([]interface{}{"a"}))
+	})
+
+	It("discards a partial record at the end of the log", func() {
+		b := batching.NewBatcher(10, time.Minute, &spyWriter{}, batching.WithWAL(open()))
+		b.Write("a")
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = f.Write([]byte{100, 'b'})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Close()).To(Succeed())
+
+		b = batching.NewBatcher(10, time.Minute, &spyWriter{}, batching.WithWAL(open()))
+		b.Write("c")
+
+		b = batching.NewBatcher(10, time.Minute, &spyWriter{}, batching.WithWAL(open()))
+		Expect(b.Peek()).To(Equal([]interface{}{"a", "c"}))
+	})
+})
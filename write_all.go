@@ -0,0 +1,68 @@
+package batching
+
+import "context"
+
+// WriteAll stores data to the batch like Write but appends many elements at
+// once, submitting as many full batches as the data fills. Batches are
+// checked against the size once per batch rather than once per element,
+// unless a byte limit, weight function or pending limit is configured.
+func (b *Batcher) WriteAll(data ...interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	_ = b.writeAll(context.Background(), data)
+}
+
+// WriteAllContext is like WriteAll but passes ctx to the writer. It returns
+// the error from the first write that fails, after storing the remaining data
+// to the batch.
+func (b *Batcher) WriteAllContext(ctx context.Context, data ...interface{}) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.writeAll(ctx, data)
+}
+
+func (b *Batcher) writeAll(ctx context.Context, data []interface{}) error {
+	if b.closed {
+		return ErrClosed
+	}
+
+	if b.maxBytes > 0 || b.weightFn != nil || b.pendingLimit > 0 {
+		return b.writeEach(ctx, data)
+	}
+	data = b.acceptAll(data)
+
+	var firstErr error
+	for len(data) > 0 {
+		n := b.size - len(b.batch)
+		if n <= 0 || n > len(data) {
+			n = len(data)
+		}
+		if err := b.storeAll(data[:n]); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		data = data[n:]
+
+		reason, ok := b.trigger()
+		if !ok {
+			continue
+		}
+		if _, err := b.writeBatch(ctx, reason); err != nil {
+			_ = b.storeAll(data)
+			return err
+		}
+	}
+
+	return firstErr
+}
+
+func (b *Batcher) storeAll(data []interface{}) error {
+	var firstErr error
+	for _, d := range data {
+		if err := b.store(d, b.sizeOf(d)); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,88 @@
+package batching_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("WriteAll", func() {
+	It("writes as many full batches as the data fills", func() {
+		writer := &recordingWriter{}
+		b := batching.NewBatcher(2, time.Minute, writer)
+
+		b.Write("a")
+		b.WriteAll("b", "c", "d", "e", "f")
+
+		Expect(writer.batches).To(Equal([][]interface{}{
+			{"a", "b"},
+			{"c", "d"},
+			{"e", "f"},
+		}))
+		Expect(b.Len()).To(Equal(0))
+	})
+
+	It("keeps a partial batch", func() {
+		writer := &recordingWriter{}
+		b := batching.NewBatcher(2, time.Minute, writer)
+
+		b.WriteAll("a", "b", "c")
+
+		Expect(writer.batches).To(HaveLen(1))
+		Expect(b.Len()).To(Equal(1))
+	})
+
+	It("writes a partial batch when the interval has lapsed", func() {
+		writer := &recordingWriter{}
+		b := batching.NewBatcher(10, time.Nanosecond, writer)
+		time.Sleep(time.Millisecond)
+
+		b.WriteAll("a", "b", "c")
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"a", "b", "c"}}))
+	})
+
+	It("honors a byte limit", func() {
+		writer := &recordingWriter{}
+		b := batching.NewBatcherWithByteLimit(4, time.Minute, strLen, writer)
+
+		b.WriteAll("ab", "cd", "efg")
+
+		Expect(writer.batches).To(Equal([][]interface{}{{"ab", "cd"}}))
+		Expect(b.PendingBytes()).To(Equal(3))
+	})
+
+	It("keeps the remaining data if a write fails", func() {
+		writeErr := errors.New("failed")
+		writer := &spyFallibleWriter{err: writeErr}
+		b := batching.NewFallibleBatcher(2, time.Minute, writer)
+
+		err := b.WriteAllContext(context.Background(), "a", "b", "c", "d", "e")
+
+		Expect(err).To(MatchError(writeErr))
+		Expect(writer.called).To(Equal(1))
+		Expect(b.Len()).To(Equal(3))
+	})
+
+	It("writes slices to a ByteBatcher", func() {
+		writer := &spyByteWriter{}
+		b := batching.NewByteBatcher(2, time.Minute, writer)
+
+		b.WriteAll([]byte("a"), []byte("b"))
+
+		Expect(writer.batch).To(Equal([][]byte{[]byte("a"), []byte("b")}))
+	})
+})
+
+type recordingWriter struct {
+	batches [][]interface{}
+}
+
+func (w *recordingWriter) Write(batch []interface{}) {
+	w.batches = append(w.batches, batch)
+}
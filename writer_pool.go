@@ -0,0 +1,257 @@
+package batching
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrBackpressure is returned when a batch cannot be handed off because the
+// maximum number of batches in flight has been reached and the
+// BackpressurePolicy is Fail.
+var ErrBackpressure = errors.New("batching: too many batches in flight")
+
+// BackpressurePolicy decides what happens when a batch is due to be written
+// while the maximum number of batches in flight has been reached.
+type BackpressurePolicy int
+
+const (
+	// Block waits until a batch in flight has been written.
+	Block BackpressurePolicy = iota
+
+	// Fail keeps the batch pending and returns ErrBackpressure.
+	Fail
+)
+
+// WithWriterConcurrency makes the Batcher hand batches to a pool of n
+// goroutines that submit them to the writer, so that up to n batches are
+// written in parallel while the Batcher keeps accumulating data. Handing a
+// batch off blocks while all n goroutines are busy, unless the context of the
+// write is done, in which case the batch is kept pending. Close waits for all
+// batches to be written.
+//
+// Since the Batcher does not wait for the write, a RetryPolicy and the flush
+// hooks only see batches being handed off and never an error from the
+// writer. Batches the writer fails to write are spooled, handed to the dead
+// letter writer or reported as lost like any other batch that is given up,
+// from a goroutine that locks the Batcher, so the Batcher is made safe for
+// concurrent use as if created WithLocking. The writer must be safe for
+// concurrent use and the order in which batches are written is not
+// guaranteed.
+func WithWriterConcurrency(n int) Option {
+	return func(b *Batcher) {
+		b.writerConcurrency = n
+	}
+}
+
+// WithMaxInFlight bounds the number of batches handed off by a Batcher
+// created WithWriterConcurrency that have not been written yet, including
+// the ones being written. Once the limit is reached the policy decides
+// whether the write that triggered the batch blocks or fails, throttling
+// producers instead of letting batches in flight pile up. With Fail the
+// batch is kept pending, so combine it with WithPendingLimit to bound the
+// number of pending elements as well. Without a limit there are never more
+// batches in flight than goroutines in the pool.
+func WithMaxInFlight(n int, policy BackpressurePolicy) Option {
+	return func(b *Batcher) {
+		b.maxInFlight = n
+		b.backpressure = policy
+	}
+}
+
+// writerPool is a ContextWriter that hands batches to a pool of goroutines
+// writing them to the underlying ContextWriter.
+type writerPool struct {
+	w    ContextWriter
+	jobs chan writeJob
+	wg   sync.WaitGroup
+	done chan struct{}
+
+	// failed holds the batches the writer failed to write until the Batcher
+	// gives them up. failures is signalled whenever one is added.
+	mu       sync.Mutex
+	failed   [][]interface{}
+	failures chan struct{}
+
+	slots  chan struct{}
+	policy BackpressurePolicy
+}
+
+type writeJob struct {
+	ctx   context.Context
+	batch []interface{}
+}
+
+func (b *Batcher) startWriterPool() {
+	if b.writerConcurrency < 1 {
+		return
+	}
+
+	b.ensureLocking()
+
+	p := &writerPool{
+		w:        b.w,
+		jobs:     make(chan writeJob),
+		done:     make(chan struct{}),
+		failures: make(chan struct{}, 1),
+		policy:   b.backpressure,
+	}
+	if b.maxInFlight > 0 {
+		p.jobs = make(chan writeJob, b.maxInFlight)
+		p.slots = make(chan struct{}, b.maxInFlight)
+	}
+	p.wg.Add(b.writerConcurrency)
+	for i := 0; i < b.writerConcurrency; i++ {
+		go p.run()
+	}
+	go func() {
+		p.wg.Wait()
+		close(p.done)
+	}()
+	go b.giveUpFailedWrites(p)
+
+	b.pool = p
+	b.w = p
+}
+
+// giveUpFailedWrites gives up the batches the pool failed to write while
+// the Batcher is locked, until the workers of the pool have stopped. The
+// workers never wait for the lock themselves, since the Batcher holds it
+// while handing batches off.
+func (b *Batcher) giveUpFailedWrites(p *writerPool) {
+	for {
+		select {
+		case <-p.failures:
+		case <-p.done:
+		}
+
+		b.mu.Lock()
+		for _, batch := range p.takeFailed() {
+			b.giveUp(batch)
+		}
+		b.mu.Unlock()
+
+		select {
+		case <-p.done:
+			return
+		default:
+		}
+	}
+}
+
+// Write hands the batch to the pool. The batch is written with a context
+// that is not cancelled with ctx since the write outlives the call. If the
+// batch cannot be handed off, because ctx is done or due to the
+// BackpressurePolicy, a notSubmittedError is returned.
+func (p *writerPool) Write(ctx context.Context, batch []interface{}) error {
+	if err := p.acquire(ctx); err != nil {
+		return notSubmittedError{err: err}
+	}
+
+	job := writeJob{ctx: context.WithoutCancel(ctx), batch: batch}
+	select {
+	case p.jobs <- job:
+		return nil
+	case <-ctx.Done():
+		p.release()
+		return notSubmittedError{err: ctx.Err()}
+	}
+}
+
+func (p *writerPool) acquire(ctx context.Context) error {
+	if p.slots == nil {
+		return nil
+	}
+
+	if p.policy == Fail {
+		select {
+		case p.slots <- struct{}{}:
+			return nil
+		default:
+			return ErrBackpressure
+		}
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *writerPool) release() {
+	if p.slots != nil {
+		<-p.slots
+	}
+}
+
+func (p *writerPool) run() {
+	defer p.wg.Done()
+
+	for job := range p.jobs {
+		if err := p.w.Write(job.ctx, job.batch); err != nil {
+			p.fail(job.batch)
+		}
+		p.release()
+	}
+}
+
+func (p *writerPool) fail(batch []interface{}) {
+	p.mu.Lock()
+	p.failed = append(p.failed, batch)
+	p.mu.Unlock()
+
+	select {
+	case p.failures <- struct{}{}:
+	default:
+	}
+}
+
+func (p *writerPool) takeFailed() [][]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	failed := p.failed
+	p.failed = nil
+	return failed
+}
+
+// close stops the workers once they finished the queued batches. It reports
+// whether they finished before ctx was done.
+func (p *writerPool) close(ctx context.Context) bool {
+	close(p.jobs)
+
+	select {
+	case <-p.done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// notSubmittedError is returned by a ContextWriter that did not take the
+// batch at all, in which case the Batcher keeps it pending without applying
+// the RetryPolicy.
+type notSubmittedError struct {
+	err error
+}
+
+func (e notSubmittedError) Error() string {
+	return e.err.Error()
+}
+
+func (e notSubmittedError) Unwrap() error {
+	return e.err
+}
+
+// asNotSubmitted is like errors.As for a notSubmittedError, but does not
+// allocate if err is nil.
+func asNotSubmitted(err error) (notSubmittedError, bool) {
+	if err == nil {
+		return notSubmittedError{}, false
+	}
+	var notSubmitted notSubmittedError
+	ok := errors.As(err, &notSubmitted)
+	return notSubmitted, ok
+}
@@ -0,0 +1,215 @@
+package batching_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/go-batching"
+)
+
+var _ = Describe("Writer concurrency", func() {
+	It("writes batches in parallel", func() {
+		var inFlight, maxInFlight int
+		var mu sync.Mutex
+		release := make(chan struct{})
+		writer := batching.WriterFunc(func([]interface{}) {
+			mu.Lock()
+			inFlight++
+			maxInFlight = max(maxInFlight, inFlight)
+			mu.Unlock()
+
+			<-release
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		})
+		b := batching.NewBatcher(1, time.Hour, writer, batching.WithWriterConcurrency(3))
+
+		b.WriteAll("a", "b", "c")
+		Eventually(func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return inFlight
+		}).Should(Equal(3))
+
+		close(release)
+		Expect(b.Close()).To(Succeed())
+		Expect(maxInFlight).To(Equal(3))
+	})
+
+	It("waits for all batches to be written on close", func() {
+		writer := &countingWriter{}
+		b := batching.NewBatcher(2, time.Hour, writer, batching.WithWriterConcurrency(4))
+
+		for i := 0; i < 101; i++ {
+			b.Write(i)
+		}
+		Expect(b.Close()).To(Succeed())
+
+		Expect(writer.items()).To(Equal(101))
+	})
+
+	It("reports batches that failed to write as lost", func() {
+		var dropped []interface{}
+		var reasons []batching.DropReason
+		writer := batching.FallibleWriterFunc(func([]interface{}) error {
+			return errors.New("failed")
+		})
+		b := batching.NewFallibleBatcher(1, time.Hour, writer,
+			batching.WithOnDropReason(func(d []interface{}, reason batching.DropReason) {
+				dropped = append(dropped, d...)
+				reasons = append(reasons, reason)
+			}),
+			batching.WithWriterConcurrency(2),
+		)
+
+		b.Write("a")
+		Expect(b.Close()).To(Succeed())
+
+		Expect(dropped).To(Equal([]interface{}{"a"}))
+		Expect(reasons).To(Equal([]batching.DropReason{batching.DropWriteFailed}))
+	})
+
+	DescribeTable("gives up batches that failed to write like a Batcher without a pool",
+		func(opts ...batching.Option) {
+			spool := &rejectingSpool{}
+			deadLetter := &recordingWriter{}
+			var dropped, lost int
+			writer := batching.FallibleWriterFunc(func([]interface{}) error {
+				return errors.New("failed")
+			})
+			b := batching.NewFallibleBatcher(1, time.Hour, writer, append(opts,
+				batching.WithSpool(spool),
+				batching.WithDeadLetterWriter(deadLetter),
+				batching.WithOnDrop(func([]interface{}) { dropped++ }),
+				batching.WithOnDropReason(func([]interface{}, batching.DropReason) { lost++ }),
+			)...)
+
+			b.Write("a")
+			Expect(b.Close()).To(Succeed())
+
+			Expect(spool.pushed).To(Equal([][]interface{}{{"a"}}))
+			Expect(deadLetter.batches).To(Equal([][]interface{}{{"a"}}))
+			Expect(dropped).To(Equal(0))
+			Expect(lost).To(Equal(0))
+		},
+		Entry("without a pool"),
+		Entry("with a pool", batching.WithWriterConcurrency(2)),
+	)
+
+	It("hands batches off to a single goroutine", func() {
+		release := make(chan struct{})
+		writer := batching.WriterFunc(func([]interface{}) {
+			<-release
+		})
+		b := batching.NewBatcher(1, time.Hour, writer, batching.WithWriterConcurrency(1))
+
+		b.Write("a")
+		Expect(b.Len()).To(Equal(0))
+
+		close(release)
+		Expect(b.Close()).To(Succeed())
+	})
+
+	It("keeps the batch pending if the context is done before it is handed off", func() {
+		started := make(chan struct{}, 1)
+		release := make(chan struct{})
+		writer := batching.WriterFunc(func([]interface{}) {
+			started <- struct{}{}
+			<-release
+		})
+		b := batching.NewBatcher(1, time.Hour, writer, batching.WithWriterConcurrency(1))
+
+		b.Write("a")
+		Eventually(started).Should(Receive())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		Expect(b.WriteContext(ctx, "b")).To(MatchError(context.DeadlineExceeded))
+		Expect(b.Peek()).To(Equal([]interface{}{"b"}))
+
+		close(release)
+		Expect(b.Close()).To(Succeed())
+	})
+})
+
+var _ = Describe("Max in flight", func() {
+	var (
+		started chan struct{}
+		release chan struct{}
+		writer  batching.Writer
+	)
+
+	BeforeEach(func() {
+		started = make(chan struct{}, 10)
+		release = make(chan struct{})
+		writer = batching.WriterFunc(func([]interface{}) {
+			started <- struct{}{}
+			<-release
+		})
+	})
+
+	It("fails writes once too many batches are in flight", func() {
+		b := batching.NewBatcher(1, time.Hour, writer,
+			batching.WithWriterConcurrency(1),
+			batching.WithMaxInFlight(2, batching.Fail),
+		)
+		ctx := context.Background()
+
+		Expect(b.WriteContext(ctx, "a")).To(Succeed())
+		Expect(b.WriteContext(ctx, "b")).To(Succeed())
+		Expect(b.WriteContext(ctx, "c")).To(MatchError(batching.ErrBackpressure))
+		Expect(b.Peek()).To(Equal([]interface{}{"c"}))
+
+		Eventually(started).Should(Receive())
+		release <- struct{}{}
+		Eventually(started).Should(Receive())
+
+		Expect(b.ForcedFlushContext(ctx)).To(HaveField("Items", 1))
+		close(release)
+		Expect(b.Close()).To(Succeed())
+	})
+
+	It("blocks writes once too many batches are in flight", func() {
+		b := batching.NewBatcher(1, time.Hour, writer,
+			batching.WithWriterConcurrency(1),
+			batching.WithMaxInFlight(1, batching.Block),
+		)
+
+		b.Write("a")
+		Eventually(started).Should(Receive())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		Expect(b.WriteContext(ctx, "b")).To(MatchError(context.DeadlineExceeded))
+		Expect(b.Len()).To(Equal(1))
+
+		close(release)
+		Expect(b.Close()).To(Succeed())
+	})
+})
+
+// rejectingSpool is a Spool that records the batches pushed to it and
+// rejects them.
+type rejectingSpool struct {
+	pushed [][]interface{}
+}
+
+func (s *rejectingSpool) Push(batch []interface{}) error {
+	s.pushed = append(s.pushed, batch)
+	return batching.ErrSpoolFull
+}
+
+func (s *rejectingSpool) Peek() ([]interface{}, bool, error) {
+	return nil, false, nil
+}
+
+func (s *rejectingSpool) Pop() error {
+	return nil
+}